@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"connectrpc.com/connect"
+	counter "github.com/wargasipil/stream_engine/proto_core/counter/v1"
+	"github.com/wargasipil/stream_engine/proto_core/counter/v1/counterconnect"
+	"github.com/wargasipil/stream_engine/proto_core/snapshot_message/v1"
+	"github.com/wargasipil/stream_engine/server"
+	"github.com/wargasipil/stream_engine/snapshot"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+// Client counter service client, method mirror HashMapCounter.
+// kind mismatch returned as *stream_core.KindMismatchError
+type Client struct {
+	rpc counterconnect.CounterServiceClient
+}
+
+// New create client of counter service at baseURL, default using connect protocol,
+// pass connect.WithGRPC() for grpc
+func New(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) *Client {
+	return &Client{counterconnect.NewCounterServiceClient(httpClient, baseURL, opts...)}
+}
+
+// Inc add delta of int64, uint64 or float64 to counter, return counter value after increment
+func (c *Client) Inc(ctx context.Context, key string, delta any) (any, error) {
+	res, err := c.rpc.Increment(ctx, connect.NewRequest(&counter.IncrementRequest{
+		Key:   key,
+		Delta: server.ValueMessage(delta),
+	}))
+	if err != nil {
+		return nil, clientError(err)
+	}
+	return recordValue(res.Msg.Record)
+}
+
+// IncWithID add delta once per event id, return false when event id already applied
+func (c *Client) IncWithID(ctx context.Context, eventID string, key string, delta any) (bool, error) {
+	res, err := c.rpc.Increment(ctx, connect.NewRequest(&counter.IncrementRequest{
+		Key:     key,
+		Delta:   server.ValueMessage(delta),
+		EventId: eventID,
+	}))
+	if err != nil {
+		return false, clientError(err)
+	}
+	return res.Msg.Applied, nil
+}
+
+// Put replace counter with value of int64, uint64 or float64
+func (c *Client) Put(ctx context.Context, key string, value any) (any, error) {
+	res, err := c.rpc.Put(ctx, connect.NewRequest(&counter.PutRequest{
+		Key:   key,
+		Value: server.ValueMessage(value),
+	}))
+	if err != nil {
+		return nil, clientError(err)
+	}
+	return recordValue(res.Msg.Record)
+}
+
+// Get get record of key, false when key never written
+func (c *Client) Get(ctx context.Context, key string) (*stream_core.KeyRecord, bool, error) {
+	res, err := c.rpc.Get(ctx, connect.NewRequest(&counter.GetRequest{Key: key}))
+	if connect.CodeOf(err) == connect.CodeNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, clientError(err)
+	}
+
+	rec, err := snapshot.RecordFromMessage(res.Msg.Record)
+	if err != nil {
+		return nil, false, err
+	}
+	return rec, true, nil
+}
+
+// BatchGet get record of keys, key never written not in result
+func (c *Client) BatchGet(ctx context.Context, keys ...string) (map[string]*stream_core.KeyRecord, error) {
+	res, err := c.rpc.BatchGet(ctx, connect.NewRequest(&counter.BatchGetRequest{Keys: keys}))
+	if err != nil {
+		return nil, clientError(err)
+	}
+
+	records := make(map[string]*stream_core.KeyRecord, len(res.Msg.Records))
+	for _, msg := range res.Msg.Records {
+		rec, err := snapshot.RecordFromMessage(msg)
+		if err != nil {
+			return nil, err
+		}
+		records[rec.Key] = rec
+	}
+	return records, nil
+}
+
+// Merge define computed key from source keys, return computed value
+func (c *Client) Merge(ctx context.Context, op stream_core.MergeOps, kind reflect.Kind, computedKey string, keys ...string) (any, error) {
+	res, err := c.rpc.Merge(ctx, connect.NewRequest(&counter.MergeRequest{
+		Key:     computedKey,
+		Kind:    snapshot.ProtoKind(kind),
+		Op:      snapshot_message.MergeOp(op + 1),
+		Sources: keys,
+	}))
+	if err != nil {
+		return nil, clientError(err)
+	}
+	return recordValue(res.Msg.Record)
+}
+
+// Snapshot iterate key with prefix updated at or after since, value consistent at time snapshot opened
+func (c *Client) Snapshot(ctx context.Context, prefix string, since time.Time, handler func(rec *stream_core.KeyRecord) error) error {
+	req := &counter.SnapshotRequest{Prefix: prefix}
+	if !since.IsZero() {
+		req.Since = since.UnixMilli()
+	}
+
+	stream, err := c.rpc.Snapshot(ctx, connect.NewRequest(req))
+	if err != nil {
+		return clientError(err)
+	}
+	defer stream.Close()
+
+	for stream.Receive() {
+		for _, msg := range stream.Msg().Records {
+			rec, err := snapshot.RecordFromMessage(msg)
+			if err != nil {
+				return err
+			}
+			err = handler(rec)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return clientError(stream.Err())
+}
+
+// Watch call handler for each change of key with prefix until ctx canceled.
+// server close watch when client fall behind buffer, returned as connect.CodeAborted
+func (c *Client) Watch(ctx context.Context, prefix string, buffer int, handler func(change *stream_core.Change) error) error {
+	stream, err := c.rpc.Watch(ctx, connect.NewRequest(&counter.WatchRequest{
+		Prefix: prefix,
+		Buffer: int32(buffer),
+	}))
+	if err != nil {
+		return clientError(err)
+	}
+	defer stream.Close()
+
+	for stream.Receive() {
+		msg := stream.Msg()
+		change := &stream_core.Change{
+			Seq:       msg.Seq,
+			Key:       msg.Key,
+			Kind:      snapshot.KindFromProto(msg.Kind),
+			Timestamp: time.UnixMilli(msg.Timestamp),
+		}
+		change.New, err = server.ValueFromMessage(msg.New)
+		if err != nil {
+			return err
+		}
+		if msg.Old != nil {
+			change.Old, err = server.ValueFromMessage(msg.Old)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = handler(change)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = stream.Err()
+	if ctx.Err() != nil && connect.CodeOf(err) == connect.CodeCanceled {
+		return nil
+	}
+	return clientError(err)
+}
+
+func recordValue(msg *snapshot_message.KeyRecord) (any, error) {
+	rec, err := snapshot.RecordFromMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	return rec.Value, nil
+}
+
+func clientError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	mismatch := server.KindMismatchFromError(err)
+	if mismatch != nil {
+		return mismatch
+	}
+	return err
+}
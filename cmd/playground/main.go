@@ -1,11 +1,11 @@
 package main
 
 import (
-	"fmt"
 	"log"
 	"reflect"
 	"time"
 
+	"github.com/wargasipil/stream_engine/pipeline"
 	"github.com/wargasipil/stream_engine/stream_core"
 	// _ "net/http/pprof"
 )
@@ -43,83 +43,18 @@ func main() {
 	}
 	defer kv.Close()
 
-	// resetting counter
-	kv.ResetCounter()
-
 	start := time.Now()
 
-	err = iterateExample("example-tiny.json", func(e *Transaction) error {
-		// var teamID string
-
-		accountkey := fmt.Sprintf("%s", e.AccountKey)
-
-		kv.IncFloat64("debit", float64(e.Debit))
-		kv.IncFloat64("credit", float64(e.Credit))
-		switch e.BalanceType {
-		case "d":
-			kv.Merge(stream_core.MergeOpMin,
-				reflect.Float64,
-				"balance",
-				"debit",
-				"credit",
-			)
-		case "c":
-			kv.Merge(stream_core.MergeOpMin,
-				reflect.Float64,
-				"balance",
-				"credit",
-				"debit",
-			)
-
-		}
-
-		kv.IncFloat64(accountkey+"/debit", float64(e.Debit))
-		kv.IncFloat64(accountkey+"/credit", float64(e.Credit))
-
-		switch e.BalanceType {
-		case "d":
-			kv.Merge(stream_core.MergeOpMin,
-				reflect.Float64,
-				accountkey+"/balance",
-				accountkey+"/debit",
-				accountkey+"/credit",
-			)
-		case "c":
-			kv.Merge(stream_core.MergeOpMin,
-				reflect.Float64,
-				accountkey+"/balance",
-				accountkey+"/credit",
-				accountkey+"/debit",
-			)
-
-		}
-
-		// if e.TeamID == e.AccountTeamID {
-		// 	teamID = "default"
-		// } else {
-		// 	teamID = fmt.Sprintf("%d", e.AccountID)
-		// }
-		// key := fmt.Sprintf(
-		// 	"teams/%d/daily/%s/%s/%s",
-		// 	e.TeamID,
-		// 	(time.Time)(e.EntryTime).Format("2006-01-02"),
-		// 	e.AccountKey,
-		// 	teamID,
-		// )
-
-		// keyDebit := key + "/debit"
-		// keyCredit := key + "/credit"
-
-		// kv.IncInt64(keyDebit, int64(e.Debit))
-		// kv.IncInt64(keyCredit, int64(e.Credit))
-
-		// log.Println(key)
-		return nil
-	})
+	rules, err := pipeline.Load("rules.yaml")
+	if err != nil {
+		log.Fatalf("failed to load rules: %v", err)
+	}
 
+	result, err := ingestExample(kv, rules, "example-tiny.json")
 	if err != nil {
-		panic(err)
+		log.Fatalf("failed to process example: %v", err)
 	}
+	log.Printf("ingested %d event resumed at offset %d", result.Events, result.Resumed)
 
 	duration := time.Since(start)
 
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+// coreConfigFlags register counter file flags, default to stream_core default config
+func coreConfigFlags(fs *flag.FlagSet) *stream_core.CoreConfig {
+	cfg := stream_core.NewDefaultCoreConfig()
+
+	fs.StringVar(&cfg.HashMapCounterPath, "counter", cfg.HashMapCounterPath, "hashmap counter file")
+	fs.Uint64Var(&cfg.HashMapCounterSlots, "slots", cfg.HashMapCounterSlots, "hashmap counter slots, must power of two")
+	fs.StringVar(&cfg.DynamicValuePath, "dynamic", cfg.DynamicValuePath, "dynamic value file")
+	fs.StringVar(&cfg.IndexPath, "index", cfg.IndexPath, "ordered key index file")
+	fs.StringVar(&cfg.WalDir, "wal", cfg.WalDir, "batch wal dir keeping committed source offset")
+	fs.StringVar(&cfg.DedupePath, "dedupe", cfg.DedupePath, "event id dedupe file")
+	fs.DurationVar(&cfg.DedupeHorizon, "dedupe-horizon", cfg.DedupeHorizon, "event id seen within horizon dropped")
+
+	return cfg
+}
+
+// parseSince parse RFC3339 time or duration before now, empty is zero time
+func parseSince(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err == nil {
+		return time.Now().Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("since %s is not RFC3339 time or duration", value)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/wargasipil/stream_engine/pipeline"
+	"github.com/wargasipil/stream_engine/source"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func runConsume(args []string) error {
+	fs := flag.NewFlagSet("consume", flag.ExitOnError)
+	cfg := coreConfigFlags(fs)
+	brokers := fs.String("brokers", "localhost:9092", "comma separated seed broker")
+	topic := fs.String("topic", "", "consumed topic")
+	group := fs.String("group", "stream-engine", "consumer group")
+	rulesPath := fs.String("rules", "rules.yaml", "rule file")
+	maxPoll := fs.Int("max-poll", source.DefaultKafkaMaxPollRecords, "record applied in one batch")
+	fs.Parse(args)
+
+	if *topic == "" {
+		return errors.New("topic empty")
+	}
+
+	rules, err := pipeline.Load(*rulesPath)
+	if err != nil {
+		return err
+	}
+
+	kv, err := stream_core.NewHashMapCounter(cfg)
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+
+	src, err := source.NewKafkaSource(kv, rules, &source.KafkaConfig{
+		Brokers:        strings.Split(*brokers, ","),
+		Topic:          *topic,
+		Group:          *group,
+		MaxPollRecords: *maxPoll,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = src.Run(ctx)
+	closeErr := src.Close()
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return closeErr
+}
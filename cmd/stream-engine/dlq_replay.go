@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/wargasipil/stream_engine/storage"
+)
+
+func runDeadLetterReplay(args []string) error {
+	fs := flag.NewFlagSet("dlq-replay", flag.ExitOnError)
+	sink := sinkFlags(fs)
+	dir := fs.String("dir", "", "dead letter dir, writer using it must be stopped")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: stream-engine dlq-replay -dir <dead letter dir> [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "mutation delivered one by one and progress saved after each, interrupted replay continue where it stopped.\n")
+		fmt.Fprintf(fs.Output(), "delivery is at least once: increment delivered right before crash is delivered again.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *dir == "" {
+		return errors.New("dead letter dir empty")
+	}
+
+	store, err := sink.open(context.Background())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	result, err := storage.RedriveDeadLetter(*dir, store)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("redrive delivered %d failed %d", result.Delivered, result.Failed)
+	return nil
+}
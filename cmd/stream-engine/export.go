@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/wargasipil/stream_engine/snapshot"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	cfg := coreConfigFlags(fs)
+	format := fs.String("format", "jsonl", "output format jsonl, csv, parquet or proto")
+	prefix := fs.String("prefix", "", "only export key with prefix")
+	since := fs.String("since", "", "only export key updated since RFC3339 time or duration ago, ex: 24h")
+	out := fs.String("out", "-", "output file, - for stdout")
+	fs.Parse(args)
+
+	// export may run beside writer process of same file
+	cfg.ReadOnly = true
+
+	opt := &snapshot.ExportOption{
+		Prefix: *prefix,
+	}
+
+	var err error
+	opt.Format, err = snapshot.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	opt.Since, err = parseSince(*since)
+	if err != nil {
+		return err
+	}
+
+	kv, err := stream_core.NewHashMapCounter(cfg)
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+
+	var count int
+	if *out == "-" {
+		count, err = snapshot.Export(kv, os.Stdout, opt)
+	} else {
+		count, err = snapshot.ExportFile(kv, *out, opt)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("exported %d key", count)
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/wargasipil/stream_engine/snapshot"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	cfg := coreConfigFlags(fs)
+	format := fs.String("format", "jsonl", "input format jsonl, csv or proto")
+	in := fs.String("in", "-", "exported snapshot file, - for stdin")
+	fs.Parse(args)
+
+	f, err := snapshot.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	kv, err := stream_core.NewHashMapCounter(cfg)
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+
+	var count int
+	if *in == "-" {
+		count, err = snapshot.Import(kv, os.Stdin, f)
+	} else {
+		count, err = snapshot.ImportFile(kv, *in, f)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("imported %d key", count)
+	return nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/wargasipil/stream_engine/snapshot"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+// openInspector parse counter file flag and open file read only, fs must not parsed yet
+func openInspector(fs *flag.FlagSet, args []string) (*stream_core.Inspector, error) {
+	cfg := coreConfigFlags(fs)
+	fs.Parse(args)
+	return stream_core.OpenInspector(cfg)
+}
+
+func runStat(args []string) error {
+	in, err := openInspector(flag.NewFlagSet("stat", flag.ExitOnError), args)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	stat, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "counter_file_size\t%d\n", stat.CounterFileSize)
+	fmt.Fprintf(w, "slots\t%d\n", stat.Slots)
+	fmt.Fprintf(w, "key_count\t%d\n", stat.KeyCount)
+	fmt.Fprintf(w, "slots_used\t%d\n", stat.SlotsUsed)
+	fmt.Fprintf(w, "load_factor\t%.4f\n", stat.LoadFactor)
+	fmt.Fprintf(w, "dynamic_file_size\t%d\n", stat.DynamicFileSize)
+	fmt.Fprintf(w, "dynamic_offset\t%d\n", stat.DynamicOffset)
+	fmt.Fprintf(w, "entries\t%d\n", stat.Entries)
+	return w.Flush()
+}
+
+func runGet(args []string) error {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	in, err := openInspector(fs, args)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: stream-engine get [flags] <key>")
+	}
+
+	rec, ok, err := in.Get(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("key %s not found", fs.Arg(0))
+	}
+
+	rw, err := snapshot.NewRecordWriter(snapshot.FormatJSONL, os.Stdout)
+	if err != nil {
+		return err
+	}
+	err = rw.Write(rec)
+	if err != nil {
+		return err
+	}
+	return rw.Close()
+}
+
+func runScan(args []string) error {
+	fs := flag.NewFlagSet("scan", flag.ExitOnError)
+	in, err := openInspector(fs, args)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if fs.NArg() > 1 {
+		return errors.New("usage: stream-engine scan [flags] [prefix]")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	err = in.Scan(fs.Arg(0), func(rec *stream_core.KeyRecord) error {
+		_, err := fmt.Fprintf(w, "%s\t%s\t%v\t%s\n", rec.Key, rec.Kind, rec.Value, rec.UpdatedAt.UTC().Format(time.RFC3339Nano))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func runDump(args []string) error {
+	fs := flag.NewFlagSet("dump", flag.ExitOnError)
+	format := fs.String("format", "jsonl", "output format jsonl, csv, parquet or proto")
+	prefix := fs.String("prefix", "", "only dump key with prefix")
+	out := fs.String("out", "-", "output file, - for stdout")
+	in, err := openInspector(fs, args)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	f, err := snapshot.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	w := os.Stdout
+	if *out != "-" {
+		w, err = os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer w.Close()
+	}
+
+	rw, err := snapshot.NewRecordWriter(f, w)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	err = in.Scan(*prefix, func(rec *stream_core.KeyRecord) error {
+		count++
+		return rw.Write(rec)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = rw.Close()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("dumped %d key", count)
+	return nil
+}
+
+func runVerify(args []string) error {
+	in, err := openInspector(flag.NewFlagSet("verify", flag.ExitOnError), args)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	report, err := in.Verify()
+	if err != nil {
+		return err
+	}
+
+	for _, problem := range report.Problems {
+		fmt.Println(problem)
+	}
+	log.Printf("verified %d entries, %d slot used", report.Entries, report.SlotsUsed)
+
+	if !report.OK() {
+		return fmt.Errorf("%d problem found", len(report.Problems)+report.Dropped)
+	}
+	return nil
+}
+
+func runRepair(args []string) error {
+	fs := flag.NewFlagSet("repair", flag.ExitOnError)
+	cfg := coreConfigFlags(fs)
+	fs.Parse(args)
+
+	report, err := stream_core.RepairCounter(cfg)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("repaired %d entries: %d slot rebuilt, %d slot cleared, %d byte truncated, key count %d",
+		report.Entries, report.Rebuilt, report.Cleared, report.Truncated, report.KeyCount)
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []*command{
+	{"export", "export counter snapshot to jsonl, csv, parquet or proto", runExport},
+	{"import", "bulk load exported jsonl, csv or proto snapshot", runImport},
+	{"dlq-replay", "redrive dead lettered mutation to key storage, at least once", runDeadLetterReplay},
+	{"consume", "apply rule to kafka topic event as consumer group member", runConsume},
+	{"serve", "serve http json api pushing counter update", runServe},
+	{"stat", "print counter file header, key count and load", runStat},
+	{"get", "print record of key read from counter file", runGet},
+	{"scan", "list record of key with prefix in key order", runScan},
+	{"dump", "dump counter file record to jsonl, csv, parquet or proto", runDump},
+	{"verify", "check key pointer, hash, merge source and offset", runVerify},
+	{"repair", "rebuild counter table from dynamic value log, writer must stopped", runRepair},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: stream-engine <command> [flags]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.usage)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		err := cmd.run(os.Args[2:])
+		if err != nil {
+			log.Fatalf("%s: %v", name, err)
+		}
+		return
+	}
+
+	usage()
+	os.Exit(2)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/wargasipil/stream_engine/metrics"
+	"github.com/wargasipil/stream_engine/server"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	cfg := coreConfigFlags(fs)
+	addr := fs.String("addr", ":8080", "listen address")
+	metricsMapping := fs.String("metrics", "", "metric mapping file, counter exported on /metrics when set")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 30*time.Second, "wait in flight request before closing counter")
+	fs.Parse(args)
+
+	kv, err := stream_core.NewHashMapCounter(cfg)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/v1/", server.NewHTTPHandler(kv))
+	mux.Handle(server.NewConnectHandler(kv))
+	metrics.PublishExpvar("stream_engine", kv)
+	mux.Handle("GET /debug/vars", expvar.Handler())
+	if *metricsMapping != "" {
+		exporter, err := metrics.Load(kv, *metricsMapping)
+		if err != nil {
+			return errors.Join(err, kv.Close())
+		}
+		mux.Handle("GET /metrics", exporter)
+	}
+
+	// grpc client need http2 without tls
+	protocols := &http.Protocols{}
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	// watch stream never finished by itself, canceled when shutdown started
+	streamCtx, cancelStream := context.WithCancel(context.Background())
+	defer cancelStream()
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		Protocols:         protocols,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return streamCtx },
+	}
+	srv.RegisterOnShutdown(cancelStream)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("serving on %s", *addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err = <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return errors.Join(err, kv.Close())
+		}
+	case <-ctx.Done():
+		log.Printf("shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+		err = srv.Shutdown(shutdownCtx)
+		cancel()
+		if err != nil {
+			// request still writing to counter, unmapping now would crash it.
+			// counter page already shared with os, only index checkpoint skipped
+			return err
+		}
+	}
+
+	return kv.Close()
+}
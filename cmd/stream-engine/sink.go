@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/wargasipil/stream_engine/storage"
+)
+
+type sinkConfig struct {
+	kind              string
+	firestoreProject  string
+	firestoreDatabase string
+	redisAddr         string
+	redisPrefix       string
+	postgresDSN       string
+	postgresSchema    string
+	postgresTable     string
+	boltPath          string
+}
+
+// sinkFlags register key storage flags
+func sinkFlags(fs *flag.FlagSet) *sinkConfig {
+	cfg := &sinkConfig{}
+
+	fs.StringVar(&cfg.kind, "sink", "firestore", "key storage, firestore, redis, postgres or bolt")
+	fs.StringVar(&cfg.firestoreProject, "firestore-project", "", "firestore project id")
+	fs.StringVar(&cfg.firestoreDatabase, "firestore-database", "(default)", "firestore database")
+	fs.StringVar(&cfg.redisAddr, "redis-addr", "localhost:6379", "redis address")
+	fs.StringVar(&cfg.redisPrefix, "redis-prefix", "", "redis hash key prefix")
+	fs.StringVar(&cfg.postgresDSN, "postgres-dsn", "", "postgres connection string")
+	fs.StringVar(&cfg.postgresSchema, "postgres-schema", "", "postgres schema, default public")
+	fs.StringVar(&cfg.postgresTable, "postgres-table", "", "postgres table, default key_storage")
+	fs.StringVar(&cfg.boltPath, "bolt-path", "", "bolt key storage file")
+
+	return cfg
+}
+
+func (cfg *sinkConfig) open(ctx context.Context) (storage.KeyStorage, error) {
+	switch cfg.kind {
+	case "firestore":
+		return storage.NewFirestoreKeyStorage(ctx, cfg.firestoreProject, cfg.firestoreDatabase)
+	case "redis":
+		return storage.NewRedisKeyStorage(ctx, &redis.Options{Addr: cfg.redisAddr}, &storage.RedisConfig{
+			Prefix: cfg.redisPrefix,
+		})
+	case "postgres":
+		return storage.NewPostgresKeyStorage(ctx, cfg.postgresDSN, &storage.PostgresConfig{
+			Schema: cfg.postgresSchema,
+			Table:  cfg.postgresTable,
+		})
+	case "bolt":
+		return storage.NewBoltKeyStorage(cfg.boltPath)
+	default:
+		return nil, fmt.Errorf("sink %s not supported", cfg.kind)
+	}
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultMaxSeries series limit of all metric when config not set
+const DefaultMaxSeries = 10_000
+
+// Config metric mapping file content, json file is valid yaml so both read by same parser
+type Config struct {
+	// Include only key with one of prefix exported, empty not filtering
+	Include []string `yaml:"include"`
+	// Exclude key with one of prefix not exported
+	Exclude []string `yaml:"exclude"`
+	// MaxSeries series limit of all metric, default DefaultMaxSeries
+	MaxSeries int       `yaml:"max_series"`
+	Metrics   []*Metric `yaml:"metrics"`
+}
+
+type Metric struct {
+	Name string `yaml:"name"`
+	Help string `yaml:"help"`
+	// Type gauge or counter, default gauge
+	Type string `yaml:"type"`
+	// Key pattern of counter key, {label} segment captured as label and * segment match any
+	Key string `yaml:"key"`
+	// Labels captured segment exported as label, default all captured segment
+	Labels []string `yaml:"labels"`
+	// Aggregate sum, last, min or max of key mapped to same series, default sum
+	Aggregate string `yaml:"aggregate"`
+	// MaxSeries series limit of metric, 0 only limited by config max series
+	MaxSeries int `yaml:"max_series"`
+}
+
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig(data)
+}
+
+func ParseConfig(data []byte) (*Config, error) {
+	cfg := &Config{}
+	err := yaml.Unmarshal(data, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,405 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+const (
+	ContentTypeOpenMetrics = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	ContentTypeText        = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// ScanPageSize key read while holding counter lock, writer not blocked during whole scrape
+const ScanPageSize = 1024
+
+// DroppedSeriesMetric gauge of series dropped by cardinality limit on last scrape
+const DroppedSeriesMetric = "stream_engine_metrics_dropped_series"
+
+type compiledMetric struct {
+	name      string
+	help      string
+	counter   bool
+	pattern   *pattern
+	labels    []string
+	aggregate string
+	maxSeries int
+}
+
+// Exporter render counter key matched by metric mapping in openmetrics or prometheus text format
+type Exporter struct {
+	hm        *stream_core.HashMapCounter
+	include   []string
+	exclude   []string
+	maxSeries int
+	metrics   []*compiledMetric
+}
+
+func New(hm *stream_core.HashMapCounter, cfg *Config) (*Exporter, error) {
+	e := &Exporter{
+		hm:        hm,
+		include:   cfg.Include,
+		exclude:   cfg.Exclude,
+		maxSeries: cfg.MaxSeries,
+	}
+	if e.maxSeries <= 0 {
+		e.maxSeries = DefaultMaxSeries
+	}
+
+	names := map[string]bool{}
+	for i, metric := range cfg.Metrics {
+		compiled, err := compileMetric(metric)
+		if err != nil {
+			name := metric.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			return nil, fmt.Errorf("metric %s: %w", name, err)
+		}
+		if names[compiled.name] {
+			return nil, fmt.Errorf("metric %s: name already used", compiled.name)
+		}
+		names[compiled.name] = true
+		e.metrics = append(e.metrics, compiled)
+	}
+
+	return e, nil
+}
+
+// Load create exporter from yaml or json mapping file
+func Load(hm *stream_core.HashMapCounter, path string) (*Exporter, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(hm, cfg)
+}
+
+func compileMetric(metric *Metric) (*compiledMetric, error) {
+	c := &compiledMetric{
+		name:      metric.Name,
+		help:      metric.Help,
+		aggregate: metric.Aggregate,
+		maxSeries: metric.MaxSeries,
+	}
+
+	switch metric.Type {
+	case "", "gauge":
+	case "counter":
+		c.counter = true
+		c.name = strings.TrimSuffix(c.name, "_total")
+	default:
+		return nil, fmt.Errorf("type %q not supported", metric.Type)
+	}
+
+	if !validMetricName(c.name) {
+		return nil, fmt.Errorf("invalid name %q", metric.Name)
+	}
+
+	switch c.aggregate {
+	case "":
+		c.aggregate = "sum"
+	case "sum", "last", "min", "max":
+	default:
+		return nil, fmt.Errorf("aggregate %q not supported", metric.Aggregate)
+	}
+
+	if c.maxSeries < 0 {
+		return nil, fmt.Errorf("negative max series")
+	}
+
+	var err error
+	c.pattern, err = compilePattern(metric.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	captured := c.pattern.labels()
+	c.labels = metric.Labels
+	if len(c.labels) == 0 {
+		c.labels = captured
+	}
+	for i, label := range c.labels {
+		if !slices.Contains(captured, label) {
+			return nil, fmt.Errorf("label %s not captured by key pattern %q", label, metric.Key)
+		}
+		if slices.Contains(c.labels[:i], label) {
+			return nil, fmt.Errorf("label %s duplicated", label)
+		}
+	}
+
+	return c, nil
+}
+
+// ServeHTTP render openmetrics when scraper accept it, prometheus text format otherwise
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	openMetrics := strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")
+
+	buf := &bytes.Buffer{}
+	err := e.Write(buf, openMetrics)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if openMetrics {
+		w.Header().Set("Content-Type", ContentTypeOpenMetrics)
+	} else {
+		w.Header().Set("Content-Type", ContentTypeText)
+	}
+	w.Write(buf.Bytes())
+}
+
+type series struct {
+	labels    []string
+	value     any
+	updatedAt time.Time
+}
+
+type family struct {
+	metric  *compiledMetric
+	series  []*series
+	index   map[string]*series
+	dropped int
+}
+
+// Write render all metric, openMetrics false write prometheus text format
+func (e *Exporter) Write(w io.Writer, openMetrics bool) error {
+	total := 0
+	families := make([]*family, len(e.metrics))
+	for i, metric := range e.metrics {
+		f, err := e.collect(metric, &total)
+		if err != nil {
+			return fmt.Errorf("metric %s: %w", metric.name, err)
+		}
+		families[i] = f
+	}
+
+	b := &strings.Builder{}
+	for _, f := range families {
+		writeFamily(b, f, openMetrics)
+	}
+
+	b.WriteString("# HELP " + DroppedSeriesMetric + " series dropped by cardinality limit on last scrape\n")
+	b.WriteString("# TYPE " + DroppedSeriesMetric + " gauge\n")
+	for _, f := range families {
+		fmt.Fprintf(b, "%s{metric=\"%s\"} %d\n", DroppedSeriesMetric, f.metric.name, f.dropped)
+	}
+
+	if openMetrics {
+		b.WriteString("# EOF\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// collect scan key matched by metric page by page, total is series count of all metric collected before
+func (e *Exporter) collect(metric *compiledMetric, total *int) (*family, error) {
+	f := &family{
+		metric: metric,
+		index:  map[string]*series{},
+	}
+
+	opt := &stream_core.ScanOption{Limit: ScanPageSize}
+	for {
+		cursor, err := e.hm.ScanRecord(metric.pattern.prefix, time.Time{}, opt, func(rec *stream_core.KeyRecord) error {
+			if !e.allowed(rec.Key) {
+				return nil
+			}
+			captured, ok := metric.pattern.match(rec.Key)
+			if !ok {
+				return nil
+			}
+
+			labels := make([]string, len(metric.labels))
+			for i, label := range metric.labels {
+				labels[i] = captured[label]
+			}
+			id := strings.Join(labels, "\xff")
+
+			s, ok := f.index[id]
+			if ok {
+				s.value, s.updatedAt = aggregate(metric.aggregate, s, rec)
+				return nil
+			}
+
+			if *total >= e.maxSeries || (metric.maxSeries > 0 && len(f.series) >= metric.maxSeries) {
+				f.dropped++
+				return nil
+			}
+
+			s = &series{labels: labels, value: rec.Value, updatedAt: rec.UpdatedAt}
+			f.index[id] = s
+			f.series = append(f.series, s)
+			*total++
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if cursor == "" {
+			break
+		}
+		opt.Cursor = cursor
+	}
+
+	slices.SortFunc(f.series, func(a, b *series) int {
+		return slices.Compare(a.labels, b.labels)
+	})
+	return f, nil
+}
+
+func (e *Exporter) allowed(key string) bool {
+	for _, prefix := range e.exclude {
+		if strings.HasPrefix(key, prefix) {
+			return false
+		}
+	}
+	if len(e.include) == 0 {
+		return true
+	}
+	for _, prefix := range e.include {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func aggregate(op string, s *series, rec *stream_core.KeyRecord) (any, time.Time) {
+	updatedAt := s.updatedAt
+	if rec.UpdatedAt.After(updatedAt) {
+		updatedAt = rec.UpdatedAt
+	}
+
+	switch op {
+	case "last":
+		if rec.UpdatedAt.Before(s.updatedAt) {
+			return s.value, updatedAt
+		}
+		return rec.Value, updatedAt
+	case "min":
+		if toFloat(rec.Value) < toFloat(s.value) {
+			return rec.Value, updatedAt
+		}
+		return s.value, updatedAt
+	case "max":
+		if toFloat(rec.Value) > toFloat(s.value) {
+			return rec.Value, updatedAt
+		}
+		return s.value, updatedAt
+	default:
+		return addValue(s.value, rec.Value), updatedAt
+	}
+}
+
+// addValue keep integer kind when both value have same kind, float64 otherwise
+func addValue(a, b any) any {
+	switch x := a.(type) {
+	case int64:
+		if y, ok := b.(int64); ok {
+			return x + y
+		}
+	case uint64:
+		if y, ok := b.(uint64); ok {
+			return x + y
+		}
+	}
+	return toFloat(a) + toFloat(b)
+}
+
+func toFloat(value any) float64 {
+	switch val := value.(type) {
+	case int64:
+		return float64(val)
+	case uint64:
+		return float64(val)
+	case float64:
+		return val
+	default:
+		return math.NaN()
+	}
+}
+
+func writeFamily(b *strings.Builder, f *family, openMetrics bool) {
+	metric := f.metric
+	sample := metric.name
+	typ := "gauge"
+	if metric.counter {
+		sample += "_total"
+		typ = "counter"
+	}
+
+	// prometheus text format name family by sample name
+	name := metric.name
+	if !openMetrics {
+		name = sample
+	}
+
+	if metric.help != "" {
+		b.WriteString("# HELP " + name + " " + escapeHelp(metric.help, openMetrics) + "\n")
+	}
+	b.WriteString("# TYPE " + name + " " + typ + "\n")
+
+	for _, s := range f.series {
+		b.WriteString(sample)
+		if len(metric.labels) > 0 {
+			b.WriteByte('{')
+			for i, label := range metric.labels {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				b.WriteString(label + "=\"" + escapeLabelValue(s.labels[i]) + "\"")
+			}
+			b.WriteByte('}')
+		}
+		b.WriteString(" " + formatValue(s.value) + "\n")
+	}
+}
+
+func formatValue(value any) string {
+	switch val := value.(type) {
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case uint64:
+		return strconv.FormatUint(val, 10)
+	}
+
+	f := toFloat(value)
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// escapeHelp openmetrics also escape double quote in help
+func escapeHelp(help string, openMetrics bool) string {
+	if openMetrics {
+		return labelEscaper.Replace(help)
+	}
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelEscaper.Replace(value)
+}
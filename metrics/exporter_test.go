@@ -0,0 +1,144 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/metrics"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+const testMapping = `
+exclude:
+  - teams/99/
+metrics:
+  - name: debit
+    help: debit of account
+    key: "teams/{team}/daily/{day}/{account}/debit"
+    labels: [team, account]
+  - name: last_debit
+    key: "teams/{team}/daily/{day}/{account}/debit"
+    labels: [team]
+    aggregate: last
+  - name: transaction
+    type: counter
+    key: "teams/{team}/transaction_count"
+  - name: shop_order
+    key: "shops/{shop}/order_count"
+    max_series: 2
+`
+
+func TestExporter(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/metrics_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/metrics_value_unittest",
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer kv.Close()
+
+	kv.IncFloat64("teams/12/daily/2025-01-01/cash/debit", 100)
+	kv.IncFloat64("teams/12/daily/2025-01-02/cash/debit", 50.5)
+	kv.IncFloat64("teams/12/daily/2025-01-02/bank/debit", 10)
+	kv.IncFloat64("teams/99/daily/2025-01-02/bank/debit", 10)
+	kv.IncFloat64("teams/12/daily/2025-01-02/cash/credit", 10)
+	kv.IncUint64("teams/12/transaction_count", 3)
+	kv.IncInt64("shops/1/order_count", 1)
+	kv.IncInt64("shops/2/order_count", 2)
+	mappingCfg, err := metrics.ParseConfig([]byte(testMapping))
+	assert.Nil(t, err)
+
+	exporter, err := metrics.New(kv, mappingCfg)
+	assert.Nil(t, err)
+
+	t.Run("testing openmetrics", func(t *testing.T) {
+		kv.IncInt64("shops/3/order_count", 3)
+		kv.IncInt64(`shops/a"b/order_count`, 1)
+
+		b := &strings.Builder{}
+		assert.Nil(t, exporter.Write(b, true))
+		assert.Equal(t, `# HELP debit debit of account
+# TYPE debit gauge
+debit{team="12",account="bank"} 10
+debit{team="12",account="cash"} 150.5
+# TYPE last_debit gauge
+last_debit{team="12"} 50.5
+# TYPE transaction counter
+transaction_total{team="12"} 3
+# TYPE shop_order gauge
+shop_order{shop="1"} 1
+shop_order{shop="2"} 2
+# HELP stream_engine_metrics_dropped_series series dropped by cardinality limit on last scrape
+# TYPE stream_engine_metrics_dropped_series gauge
+stream_engine_metrics_dropped_series{metric="debit"} 0
+stream_engine_metrics_dropped_series{metric="last_debit"} 0
+stream_engine_metrics_dropped_series{metric="transaction"} 0
+stream_engine_metrics_dropped_series{metric="shop_order"} 2
+# EOF
+`, b.String())
+	})
+
+	t.Run("testing label value escaped", func(t *testing.T) {
+		mappingCfg, err := metrics.ParseConfig([]byte(`
+include: [shops/]
+metrics:
+  - name: shop_order
+    key: "shops/{shop}/order_count"
+`))
+		assert.Nil(t, err)
+		exporter, err := metrics.New(kv, mappingCfg)
+		assert.Nil(t, err)
+
+		b := &strings.Builder{}
+		assert.Nil(t, exporter.Write(b, false))
+		assert.Contains(t, b.String(), `shop_order{shop="a\"b"} 1`)
+		assert.NotContains(t, b.String(), "# EOF")
+	})
+
+	t.Run("testing http content negotiation", func(t *testing.T) {
+		ts := httptest.NewServer(exporter)
+		defer ts.Close()
+
+		req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+		assert.Nil(t, err)
+		req.Header.Set("Accept", "application/openmetrics-text;version=1.0.0,text/plain;q=0.5")
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, metrics.ContentTypeOpenMetrics, resp.Header.Get("Content-Type"))
+		assert.True(t, strings.HasSuffix(string(body), "# EOF\n"))
+
+		resp, err = http.Get(ts.URL)
+		assert.Nil(t, err)
+		body, _ = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, metrics.ContentTypeText, resp.Header.Get("Content-Type"))
+		assert.Contains(t, string(body), "# TYPE transaction_total counter\n")
+	})
+
+	t.Run("testing invalid mapping", func(t *testing.T) {
+		for _, mapping := range []string{
+			`metrics: [{name: "1debit", key: "a/{b}"}]`,
+			`metrics: [{name: debit, key: "a/{b}", type: histogram}]`,
+			`metrics: [{name: debit, key: "a/{b}", labels: [c]}]`,
+			`metrics: [{name: debit, key: "a/x{b}"}]`,
+			`metrics: [{name: debit, key: "a/{b}", aggregate: avg}]`,
+			`metrics: [{name: debit, key: "a/{b}"}, {name: debit, key: "c/{b}"}]`,
+		} {
+			mappingCfg, err := metrics.ParseConfig([]byte(mapping))
+			assert.Nil(t, err)
+			_, err = metrics.New(kv, mappingCfg)
+			assert.NotNil(t, err, mapping)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pattern key pattern split by slash, each segment literal, {label} or *
+type pattern struct {
+	src      string
+	segments []patternSegment
+	// prefix literal segment before first placeholder, used to scan index
+	prefix string
+}
+
+type patternSegment struct {
+	literal string
+	label   string
+	any     bool
+}
+
+func compilePattern(src string) (*pattern, error) {
+	if src == "" {
+		return nil, fmt.Errorf("key pattern empty")
+	}
+
+	p := &pattern{src: src}
+	literal := true
+	for _, segment := range strings.Split(src, "/") {
+		switch {
+		case segment == "*":
+			p.segments = append(p.segments, patternSegment{any: true})
+			literal = false
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			label := segment[1 : len(segment)-1]
+			if !validLabelName(label) {
+				return nil, fmt.Errorf("key pattern %q invalid label %q", src, label)
+			}
+			p.segments = append(p.segments, patternSegment{label: label})
+			literal = false
+		case strings.ContainsAny(segment, "{}*"):
+			return nil, fmt.Errorf("key pattern %q placeholder must be whole segment", src)
+		default:
+			p.segments = append(p.segments, patternSegment{literal: segment})
+			if literal {
+				p.prefix += segment + "/"
+			}
+		}
+	}
+
+	// pattern without placeholder match exactly one key
+	if literal {
+		p.prefix = src
+	}
+	return p, nil
+}
+
+func (p *pattern) labels() []string {
+	labels := []string{}
+	for _, segment := range p.segments {
+		if segment.label != "" {
+			labels = append(labels, segment.label)
+		}
+	}
+	return labels
+}
+
+// match key against pattern, return captured segment by label
+func (p *pattern) match(key string) (map[string]string, bool) {
+	parts := strings.Split(key, "/")
+	if len(parts) != len(p.segments) {
+		return nil, false
+	}
+
+	captured := map[string]string{}
+	for i, segment := range p.segments {
+		switch {
+		case segment.any:
+		case segment.label != "":
+			captured[segment.label] = parts[i]
+		case segment.literal != parts[i]:
+			return nil, false
+		}
+	}
+	return captured, true
+}
+
+func validMetricName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_' || c == ':':
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func validLabelName(name string) bool {
+	return validMetricName(name) && !strings.Contains(name, ":") && !strings.HasPrefix(name, "__")
+}
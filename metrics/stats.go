@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"context"
+	"expvar"
+
+	"github.com/wargasipil/stream_engine/stream_core"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// PublishExpvar publish counter stats as expvar name, expvar panic when name already published
+func PublishExpvar(name string, hm *stream_core.HashMapCounter) {
+	expvar.Publish(name, expvar.Func(func() any {
+		stats, err := hm.Stats()
+		if err != nil {
+			return map[string]string{"error": err.Error()}
+		}
+		return stats
+	}))
+}
+
+// RegisterOtel observe counter stats with meter on every collection, unregister before closing counter
+func RegisterOtel(meter metric.Meter, hm *stream_core.HashMapCounter) (metric.Registration, error) {
+	i := &otelInstruments{}
+	var err error
+
+	gauges := []struct {
+		target *metric.Int64ObservableGauge
+		name   string
+		desc   string
+		unit   string
+	}{
+		{&i.slots, "stream_engine.slots", "hashmap slot count", "{slot}"},
+		{&i.slotsUsed, "stream_engine.slots.used", "hashmap slot holding key", "{slot}"},
+		{&i.probeMax, "stream_engine.dedupe.probe.max", "max probe length of dedupe window lookup", "{probe}"},
+		{&i.dynamicSize, "stream_engine.dynamic.size", "allocated byte of dynamic value file", "By"},
+		{&i.dynamicUsed, "stream_engine.dynamic.used", "written byte of dynamic value file", "By"},
+		{&i.walSegments, "stream_engine.wal.segments", "wal segment count", "{segment}"},
+		{&i.walSize, "stream_engine.wal.size", "byte of all wal segment", "By"},
+		{&i.snapshotOpen, "stream_engine.snapshot.open", "snapshot view still open", "{snapshot}"},
+	}
+	for _, g := range gauges {
+		*g.target, err = meter.Int64ObservableGauge(g.name, metric.WithDescription(g.desc), metric.WithUnit(g.unit))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	floatGauges := []struct {
+		target *metric.Float64ObservableGauge
+		name   string
+		desc   string
+		unit   string
+	}{
+		{&i.loadFactor, "stream_engine.load_factor", "used slot share of hashmap slot", "1"},
+		{&i.probeAvg, "stream_engine.dedupe.probe.avg", "average probe length of dedupe window lookup", "{probe}"},
+		{&i.fragmentation, "stream_engine.dynamic.fragmentation", "share of dynamic value file allocated but not written", "1"},
+		{&i.opsRate, "stream_engine.ops.rate", "op per second over last rate window", "{op}/s"},
+		{&i.lockWaitMax, "stream_engine.lock.wait.max", "longest wait for counter lock", "s"},
+		{&i.snapshotLast, "stream_engine.snapshot.duration.last", "time last snapshot view kept open", "s"},
+		{&i.snapshotMax, "stream_engine.snapshot.duration.max", "longest time snapshot view kept open", "s"},
+	}
+	for _, g := range floatGauges {
+		*g.target, err = meter.Float64ObservableGauge(g.name, metric.WithDescription(g.desc), metric.WithUnit(g.unit))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	counters := []struct {
+		target *metric.Int64ObservableCounter
+		name   string
+		desc   string
+		unit   string
+	}{
+		{&i.ops, "stream_engine.ops", "op applied since counter opened", "{op}"},
+		{&i.lockAcquired, "stream_engine.lock.acquired", "counter lock acquired", "{lock}"},
+		{&i.lockContended, "stream_engine.lock.contended", "counter lock call waiting for other holder", "{lock}"},
+		{&i.snapshots, "stream_engine.snapshots", "snapshot view closed", "{snapshot}"},
+		{&i.dedupe, "stream_engine.dedupe.lookups", "event id lookup by result", "{lookup}"},
+	}
+	for _, c := range counters {
+		*c.target, err = meter.Int64ObservableCounter(c.name, metric.WithDescription(c.desc), metric.WithUnit(c.unit))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	i.lockWait, err = meter.Float64ObservableCounter("stream_engine.lock.wait",
+		metric.WithDescription("total wait for counter lock"), metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	i.snapshotTotal, err = meter.Float64ObservableCounter("stream_engine.snapshot.duration",
+		metric.WithDescription("total time snapshot view kept open"), metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	return meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		stats, err := hm.Stats()
+		if err != nil {
+			return err
+		}
+		i.observe(o, &stats)
+		return nil
+	},
+		i.slots, i.slotsUsed, i.probeMax, i.dynamicSize, i.dynamicUsed, i.walSegments, i.walSize, i.snapshotOpen,
+		i.loadFactor, i.probeAvg, i.fragmentation, i.opsRate, i.lockWaitMax, i.snapshotLast, i.snapshotMax,
+		i.ops, i.lockAcquired, i.lockContended, i.snapshots, i.dedupe, i.lockWait, i.snapshotTotal,
+	)
+}
+
+type otelInstruments struct {
+	slots        metric.Int64ObservableGauge
+	slotsUsed    metric.Int64ObservableGauge
+	probeMax     metric.Int64ObservableGauge
+	dynamicSize  metric.Int64ObservableGauge
+	dynamicUsed  metric.Int64ObservableGauge
+	walSegments  metric.Int64ObservableGauge
+	walSize      metric.Int64ObservableGauge
+	snapshotOpen metric.Int64ObservableGauge
+
+	loadFactor    metric.Float64ObservableGauge
+	probeAvg      metric.Float64ObservableGauge
+	fragmentation metric.Float64ObservableGauge
+	opsRate       metric.Float64ObservableGauge
+	lockWaitMax   metric.Float64ObservableGauge
+	snapshotLast  metric.Float64ObservableGauge
+	snapshotMax   metric.Float64ObservableGauge
+
+	ops           metric.Int64ObservableCounter
+	lockAcquired  metric.Int64ObservableCounter
+	lockContended metric.Int64ObservableCounter
+	snapshots     metric.Int64ObservableCounter
+	dedupe        metric.Int64ObservableCounter
+
+	lockWait      metric.Float64ObservableCounter
+	snapshotTotal metric.Float64ObservableCounter
+}
+
+func (i *otelInstruments) observe(o metric.Observer, stats *stream_core.Stats) {
+	o.ObserveInt64(i.slots, int64(stats.Slots))
+	o.ObserveInt64(i.slotsUsed, int64(stats.SlotsUsed))
+	o.ObserveInt64(i.probeMax, int64(stats.Probe.Max))
+	o.ObserveInt64(i.dynamicSize, stats.DynamicFileSize)
+	o.ObserveInt64(i.dynamicUsed, stats.DynamicUsed)
+	o.ObserveInt64(i.walSegments, int64(stats.Wal.Segments))
+	o.ObserveInt64(i.walSize, stats.Wal.Bytes)
+	o.ObserveInt64(i.snapshotOpen, int64(stats.Snapshot.Open))
+
+	o.ObserveFloat64(i.loadFactor, stats.LoadFactor)
+	o.ObserveFloat64(i.probeAvg, stats.Probe.Avg)
+	o.ObserveFloat64(i.fragmentation, stats.DynamicFragmentation)
+	o.ObserveFloat64(i.lockWaitMax, stats.Lock.WaitMax.Seconds())
+	o.ObserveFloat64(i.snapshotLast, stats.Snapshot.Last.Seconds())
+	o.ObserveFloat64(i.snapshotMax, stats.Snapshot.Max.Seconds())
+
+	rates := map[string]float64{
+		"inc":   stats.OpsPerSecond.Inc,
+		"put":   stats.OpsPerSecond.Put,
+		"merge": stats.OpsPerSecond.Merge,
+		"batch": stats.OpsPerSecond.Batch,
+	}
+	for op, rate := range rates {
+		o.ObserveFloat64(i.opsRate, rate, metric.WithAttributes(attribute.String("op", op)))
+	}
+
+	totals := map[string]uint64{
+		"inc":   stats.Ops.Inc,
+		"put":   stats.Ops.Put,
+		"merge": stats.Ops.Merge,
+		"batch": stats.Ops.Batch,
+	}
+	for op, total := range totals {
+		o.ObserveInt64(i.ops, int64(total), metric.WithAttributes(attribute.String("op", op)))
+	}
+
+	o.ObserveInt64(i.lockAcquired, int64(stats.Lock.Acquired))
+	o.ObserveInt64(i.lockContended, int64(stats.Lock.Contended))
+	o.ObserveInt64(i.snapshots, int64(stats.Snapshot.Count))
+
+	dedupe := map[string]uint64{
+		"hit":       stats.Dedupe.Hits,
+		"bloom_hit": stats.Dedupe.BloomHits,
+		"miss":      stats.Dedupe.Misses,
+	}
+	for result, count := range dedupe {
+		o.ObserveInt64(i.dedupe, int64(count), metric.WithAttributes(attribute.String("result", result)))
+	}
+
+	o.ObserveFloat64(i.lockWait, stats.Lock.WaitTotal.Seconds())
+	o.ObserveFloat64(i.snapshotTotal, stats.Snapshot.Total.Seconds())
+}
@@ -0,0 +1,81 @@
+package metrics_test
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/metrics"
+	"github.com/wargasipil/stream_engine/stream_core"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestStatsExport(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/metrics_stats_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/metrics_stats_value_unittest",
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer kv.Close()
+
+	kv.IncInt64("users/1/order_count", 1)
+	kv.PutInt64("users/1/balance", 1)
+
+	t.Run("testing expvar", func(t *testing.T) {
+		metrics.PublishExpvar("stream_engine_unittest", kv)
+
+		stats := stream_core.Stats{}
+		err := json.Unmarshal([]byte(expvar.Get("stream_engine_unittest").String()), &stats)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(2), stats.SlotsUsed)
+		assert.Equal(t, uint64(1), stats.Ops.Put)
+	})
+
+	t.Run("testing otel", func(t *testing.T) {
+		reader := sdkmetric.NewManualReader()
+		provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+		defer provider.Shutdown(context.Background())
+
+		reg, err := metrics.RegisterOtel(provider.Meter("stream_engine"), kv)
+		assert.Nil(t, err)
+		defer reg.Unregister()
+
+		rm := metricdata.ResourceMetrics{}
+		assert.Nil(t, reader.Collect(context.Background(), &rm))
+
+		found := map[string]metricdata.Aggregation{}
+		for _, scope := range rm.ScopeMetrics {
+			for _, m := range scope.Metrics {
+				found[m.Name] = m.Data
+			}
+		}
+
+		slotsUsed := found["stream_engine.slots.used"].(metricdata.Gauge[int64])
+		assert.Equal(t, int64(2), slotsUsed.DataPoints[0].Value)
+
+		ops := found["stream_engine.ops"].(metricdata.Sum[int64])
+		assert.True(t, ops.IsMonotonic)
+		assert.Len(t, ops.DataPoints, 4)
+		for _, point := range ops.DataPoints {
+			op, _ := point.Attributes.Value("op")
+			switch op.AsString() {
+			case "inc", "put":
+				assert.Equal(t, int64(1), point.Value)
+			default:
+				assert.Equal(t, int64(0), point.Value)
+			}
+		}
+
+		assert.Contains(t, found, "stream_engine.lock.wait")
+		assert.Contains(t, found, "stream_engine.dynamic.fragmentation")
+	})
+}
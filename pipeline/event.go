@@ -0,0 +1,40 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Event decoded input record, number kept as json.Number so large integer not lose precision
+type Event map[string]any
+
+// DecodeJSONEvent decode json object to event
+func DecodeJSONEvent(data []byte) (Event, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	event := Event{}
+	err := decoder.Decode(&event)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// Field value of field, dotted name read nested object
+func (e Event) Field(name string) (any, bool) {
+	var current any = map[string]any(e)
+	for part := range strings.SplitSeq(name, ".") {
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = obj[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
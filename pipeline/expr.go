@@ -0,0 +1,553 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr compiled expression, supporting field reference (dotted for nested field), number, string,
+// true, false, null, arithmetic + - * /, comparison, && || ! and function if(cond, a, b), abs(x)
+type Expr struct {
+	src  string
+	root node
+}
+
+type node interface {
+	eval(event Event) (any, error)
+}
+
+func CompileExpr(src string) (*Expr, error) {
+	p := &exprParser{src: src}
+	err := p.tokenize()
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("expression %q unexpected %q", src, p.tokens[p.pos].text)
+	}
+
+	return &Expr{src, root}, nil
+}
+
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval result is int64, float64, string, bool or nil
+func (e *Expr) Eval(event Event) (any, error) {
+	value, err := e.root.eval(event)
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %w", e.src, err)
+	}
+	return value, nil
+}
+
+// ---------------------------- tokenizer ---------------------------------
+
+type tokenKind int
+
+const (
+	tokenNumber tokenKind = iota
+	tokenString
+	tokenIdent
+	tokenOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+type exprParser struct {
+	src    string
+	tokens []token
+	pos    int
+}
+
+var exprOps = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "!", "(", ")", ","}
+
+func (p *exprParser) tokenize() error {
+	src := p.src
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+				i++
+			}
+			p.tokens = append(p.tokens, token{tokenNumber, src[start:i]})
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(src[i+1:], src[i])
+			if end < 0 {
+				return fmt.Errorf("expression %q unterminated string", src)
+			}
+			p.tokens = append(p.tokens, token{tokenString, src[i+1 : i+1+end]})
+			i += end + 2
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || src[i] == '.' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			p.tokens = append(p.tokens, token{tokenIdent, src[start:i]})
+		default:
+			matched := false
+			for _, op := range exprOps {
+				if strings.HasPrefix(src[i:], op) {
+					p.tokens = append(p.tokens, token{tokenOp, op})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return fmt.Errorf("expression %q unexpected character %q", src, c)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *exprParser) peekOp(ops ...string) string {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokenOp {
+		return ""
+	}
+	for _, op := range ops {
+		if p.tokens[p.pos].text == op {
+			return op
+		}
+	}
+	return ""
+}
+
+func (p *exprParser) expectOp(op string) error {
+	if p.peekOp(op) == "" {
+		return fmt.Errorf("expression %q expect %q", p.src, op)
+	}
+	p.pos++
+	return nil
+}
+
+// ---------------------------- parser ---------------------------------
+
+func (p *exprParser) parseBinary(next func() (node, error), ops ...string) (node, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op := p.peekOp(ops...)
+		if op == "" {
+			return left, nil
+		}
+		p.pos++
+
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op, left, right}
+	}
+}
+
+func (p *exprParser) parseOr() (node, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *exprParser) parseAnd() (node, error) {
+	return p.parseBinary(p.parseCompare, "&&")
+}
+
+func (p *exprParser) parseCompare() (node, error) {
+	return p.parseBinary(p.parseAdd, "==", "!=", "<=", ">=", "<", ">")
+}
+
+func (p *exprParser) parseAdd() (node, error) {
+	return p.parseBinary(p.parseMul, "+", "-")
+}
+
+func (p *exprParser) parseMul() (node, error) {
+	return p.parseBinary(p.parseUnary, "*", "/")
+}
+
+func (p *exprParser) parseUnary() (node, error) {
+	op := p.peekOp("-", "!")
+	if op == "" {
+		return p.parsePrimary()
+	}
+	p.pos++
+
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &unaryNode{op, operand}, nil
+}
+
+func (p *exprParser) parsePrimary() (node, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("expression %q unexpected end", p.src)
+	}
+
+	tok := p.tokens[p.pos]
+	p.pos++
+
+	switch tok.kind {
+	case tokenNumber:
+		value, err := parseNumber(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("expression %q invalid number %s", p.src, tok.text)
+		}
+		return &literalNode{value}, nil
+	case tokenString:
+		return &literalNode{tok.text}, nil
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{true}, nil
+		case "false":
+			return &literalNode{false}, nil
+		case "null":
+			return &literalNode{nil}, nil
+		}
+
+		if p.peekOp("(") == "" {
+			return &fieldNode{tok.text}, nil
+		}
+		return p.parseCall(tok.text)
+	default:
+		if tok.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expectOp(")")
+		}
+		return nil, fmt.Errorf("expression %q unexpected %q", p.src, tok.text)
+	}
+}
+
+func (p *exprParser) parseCall(name string) (node, error) {
+	p.pos++ // (
+
+	args := []node{}
+	for p.peekOp(")") == "" {
+		if len(args) > 0 {
+			err := p.expectOp(",")
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	p.pos++ // )
+
+	switch {
+	case name == "if" && len(args) == 3:
+	case name == "abs" && len(args) == 1:
+	default:
+		return nil, fmt.Errorf("expression %q unknown function %s with %d argument", p.src, name, len(args))
+	}
+	return &callNode{name, args}, nil
+}
+
+// ---------------------------- node ---------------------------------
+
+type literalNode struct {
+	value any
+}
+
+func (n *literalNode) eval(event Event) (any, error) {
+	return n.value, nil
+}
+
+type fieldNode struct {
+	name string
+}
+
+func (n *fieldNode) eval(event Event) (any, error) {
+	value, _ := event.Field(n.name)
+	if number, ok := value.(json.Number); ok {
+		return parseNumber(number.String())
+	}
+	return value, nil
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(event Event) (any, error) {
+	value, err := n.operand.eval(event)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "!" {
+		return !truthy(value), nil
+	}
+
+	number, err := toNumber(value)
+	if err != nil {
+		return nil, err
+	}
+	switch val := number.(type) {
+	case int64:
+		return -val, nil
+	default:
+		return -val.(float64), nil
+	}
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(event Event) (any, error) {
+	left, err := n.left.eval(event)
+	if err != nil {
+		return nil, err
+	}
+
+	// short circuit
+	switch n.op {
+	case "&&":
+		if !truthy(left) {
+			return false, nil
+		}
+	case "||":
+		if truthy(left) {
+			return true, nil
+		}
+	}
+
+	right, err := n.right.eval(event)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "&&", "||":
+		return truthy(right), nil
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		return compare(n.op, left, right)
+	default:
+		return arithmetic(n.op, left, right)
+	}
+}
+
+type callNode struct {
+	name string
+	args []node
+}
+
+func (n *callNode) eval(event Event) (any, error) {
+	switch n.name {
+	case "if":
+		cond, err := n.args[0].eval(event)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(cond) {
+			return n.args[1].eval(event)
+		}
+		return n.args[2].eval(event)
+	default:
+		value, err := n.args[0].eval(event)
+		if err != nil {
+			return nil, err
+		}
+		number, err := toNumber(value)
+		if err != nil {
+			return nil, err
+		}
+		switch val := number.(type) {
+		case int64:
+			if val < 0 {
+				return -val, nil
+			}
+			return val, nil
+		default:
+			return math.Abs(val.(float64)), nil
+		}
+	}
+}
+
+// ---------------------------- value ---------------------------------
+
+// parseNumber parse integer as int64 and other as float64
+func parseNumber(text string) (any, error) {
+	i, err := strconv.ParseInt(text, 10, 64)
+	if err == nil {
+		return i, nil
+	}
+	return strconv.ParseFloat(text, 64)
+}
+
+// toNumber convert value to int64 or float64, numeric string is parsed
+func toNumber(value any) (any, error) {
+	switch val := value.(type) {
+	case int64:
+		return val, nil
+	case float64:
+		return val, nil
+	case int:
+		return int64(val), nil
+	case string:
+		number, err := parseNumber(strings.TrimSpace(val))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", val)
+		}
+		return number, nil
+	case json.Number:
+		return parseNumber(val.String())
+	case bool:
+		if val {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case nil:
+		return nil, fmt.Errorf("null is not a number")
+	default:
+		return nil, fmt.Errorf("%T is not a number", value)
+	}
+}
+
+func toFloat(number any) float64 {
+	if i, ok := number.(int64); ok {
+		return float64(i)
+	}
+	return number.(float64)
+}
+
+func truthy(value any) bool {
+	switch val := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case string:
+		return val != ""
+	case int64:
+		return val != 0
+	case float64:
+		return val != 0
+	default:
+		return true
+	}
+}
+
+func equal(left, right any) bool {
+	if left == nil || right == nil {
+		return left == right
+	}
+
+	lnum, lerr := toNumber(left)
+	rnum, rerr := toNumber(right)
+	_, lstr := left.(string)
+	_, rstr := right.(string)
+	if lerr == nil && rerr == nil && !(lstr && rstr) {
+		return toFloat(lnum) == toFloat(rnum)
+	}
+
+	return fmt.Sprint(left) == fmt.Sprint(right)
+}
+
+func compare(op string, left, right any) (bool, error) {
+	var cmp int
+	lstr, lok := left.(string)
+	rstr, rok := right.(string)
+	if lok && rok {
+		cmp = strings.Compare(lstr, rstr)
+	} else {
+		lnum, err := toNumber(left)
+		if err != nil {
+			return false, err
+		}
+		rnum, err := toNumber(right)
+		if err != nil {
+			return false, err
+		}
+
+		l, r := toFloat(lnum), toFloat(rnum)
+		switch {
+		case l < r:
+			cmp = -1
+		case l > r:
+			cmp = 1
+		}
+	}
+
+	switch op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+func arithmetic(op string, left, right any) (any, error) {
+	lnum, err := toNumber(left)
+	if err != nil {
+		return nil, err
+	}
+	rnum, err := toNumber(right)
+	if err != nil {
+		return nil, err
+	}
+
+	li, lint := lnum.(int64)
+	ri, rint := rnum.(int64)
+	if lint && rint && op != "/" {
+		switch op {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		default:
+			return li * ri, nil
+		}
+	}
+
+	l, r := toFloat(lnum), toFloat(rnum)
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	default:
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	}
+}
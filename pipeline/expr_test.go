@@ -0,0 +1,60 @@
+package pipeline_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/pipeline"
+)
+
+func TestExpr(t *testing.T) {
+	event, err := pipeline.DecodeJSONEvent([]byte(`{
+		"debit": "150.50",
+		"credit": 20,
+		"qty": 3,
+		"balance_type": "d",
+		"rollback": false,
+		"account": {"team_id": 12}
+	}`))
+	assert.Nil(t, err)
+
+	cases := []struct {
+		src      string
+		expected any
+	}{
+		{"debit", "150.50"},
+		{"credit", int64(20)},
+		{"debit - credit", 130.5},
+		{"credit * qty + 1", int64(61)},
+		{"credit / 8", 2.5},
+		{"-(credit - 25)", int64(5)},
+		{"account.team_id", int64(12)},
+		{`balance_type == "d" && !rollback`, true},
+		{`balance_type == 'c' || credit >= 20`, true},
+		{"credit > qty * 10", false},
+		{"if(balance_type == \"d\", debit, 0 - credit)", "150.50"},
+		{"abs(qty - credit)", int64(17)},
+		{"missing == null", true},
+	}
+
+	for _, c := range cases {
+		expr, err := pipeline.CompileExpr(c.src)
+		assert.Nil(t, err, c.src)
+
+		value, err := expr.Eval(event)
+		assert.Nil(t, err, c.src)
+		assert.Equal(t, c.expected, value, c.src)
+	}
+
+	t.Run("testing invalid expression", func(t *testing.T) {
+		for _, src := range []string{"debit +", "(credit", "unknown(credit)", `"open`, "credit ^ 2"} {
+			_, err := pipeline.CompileExpr(src)
+			assert.NotNil(t, err, src)
+		}
+
+		expr, err := pipeline.CompileExpr("balance_type * 2")
+		assert.Nil(t, err)
+		_, err = expr.Eval(event)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,334 @@
+package pipeline
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+// Action counter update produced by rule for one event
+type Action struct {
+	Rule  string
+	Op    Op
+	Key   string
+	Kind  reflect.Kind
+	Value any
+	// EventID dedupe id of inc, event id joined with rule name
+	EventID string
+	// Merge and Sources set for merge op
+	Merge   stream_core.MergeOps
+	Sources []string
+}
+
+type compiledRule struct {
+	name    string
+	when    *Expr
+	key     *Template
+	value   *Expr
+	kind    reflect.Kind
+	op      Op
+	merge   stream_core.MergeOps
+	sources []*Template
+}
+
+// Pipeline apply rule to decoded event, rule applied in file order
+type Pipeline struct {
+	rules   []*compiledRule
+	ctx     *templateContext
+	eventID *Template
+}
+
+func New(rs *RuleSet) (*Pipeline, error) {
+	ctx := &templateContext{
+		layouts:  rs.TimeLayouts,
+		location: time.UTC,
+	}
+	if len(ctx.layouts) == 0 {
+		ctx.layouts = DefaultTimeLayouts
+	}
+	if rs.Timezone != "" {
+		loc, err := time.LoadLocation(rs.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		ctx.location = loc
+	}
+
+	p := &Pipeline{ctx: ctx}
+	if rs.EventID != "" {
+		tmpl, err := CompileTemplate(rs.EventID)
+		if err != nil {
+			return nil, fmt.Errorf("event id: %w", err)
+		}
+		p.eventID = tmpl
+	}
+
+	for i, rule := range rs.Rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			name := rule.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			return nil, fmt.Errorf("rule %s: %w", name, err)
+		}
+		p.rules = append(p.rules, compiled)
+	}
+
+	return p, nil
+}
+
+// Load create pipeline from yaml or json rule file
+func Load(path string) (*Pipeline, error) {
+	rs, err := LoadRuleSet(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(rs)
+}
+
+func compileRule(rule *Rule) (*compiledRule, error) {
+	var err error
+	c := &compiledRule{name: rule.Name}
+
+	c.op, err = ParseOp(rule.Op)
+	if err != nil {
+		return nil, err
+	}
+
+	c.kind, err = parseKind(rule.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	c.key, err = CompileTemplate(rule.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.When != "" {
+		c.when, err = CompileExpr(rule.When)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.op == OpMerge {
+		c.merge, err = stream_core.ParseMergeOps(rule.Merge)
+		if err != nil {
+			return nil, err
+		}
+		if len(rule.Sources) == 0 {
+			return nil, fmt.Errorf("merge rule without source")
+		}
+
+		for _, source := range rule.Sources {
+			tmpl, err := CompileTemplate(source)
+			if err != nil {
+				return nil, err
+			}
+			c.sources = append(c.sources, tmpl)
+		}
+		return c, nil
+	}
+
+	if rule.Value == "" {
+		return nil, fmt.Errorf("%s rule without value", c.op)
+	}
+	c.value, err = CompileExpr(rule.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Actions evaluate all rule against event
+func (p *Pipeline) Actions(event Event) ([]*Action, error) {
+	var eventID string
+	if p.eventID != nil {
+		var err error
+		eventID, err = p.eventID.execute(event, p.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("event id: %w", err)
+		}
+	}
+
+	actions := []*Action{}
+	for _, rule := range p.rules {
+		action, err := rule.action(event, p.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule.name, err)
+		}
+		if action == nil {
+			continue
+		}
+		if eventID != "" && action.Op == OpInc {
+			action.EventID = eventID + "/" + rule.name
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+func (r *compiledRule) action(event Event, ctx *templateContext) (*Action, error) {
+	if r.when != nil {
+		cond, err := r.when.Eval(event)
+		if err != nil {
+			return nil, err
+		}
+		if !truthy(cond) {
+			return nil, nil
+		}
+	}
+
+	key, err := r.key.execute(event, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	action := &Action{
+		Rule: r.name,
+		Op:   r.op,
+		Key:  key,
+		Kind: r.kind,
+	}
+
+	if r.op == OpMerge {
+		action.Merge = r.merge
+		for _, source := range r.sources {
+			sourceKey, err := source.execute(event, ctx)
+			if err != nil {
+				return nil, err
+			}
+			action.Sources = append(action.Sources, sourceKey)
+		}
+		return action, nil
+	}
+
+	value, err := r.value.Eval(event)
+	if err != nil {
+		return nil, err
+	}
+	action.Value, err = convertValue(r.kind, value)
+	if err != nil {
+		return nil, fmt.Errorf("value %q: %w", r.value, err)
+	}
+
+	return action, nil
+}
+
+// convertValue convert expression result to counter kind, float truncated for integer kind
+func convertValue(kind reflect.Kind, value any) (any, error) {
+	number, err := toNumber(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case reflect.Int64:
+		if i, ok := number.(int64); ok {
+			return i, nil
+		}
+		return int64(number.(float64)), nil
+	case reflect.Uint64:
+		f := toFloat(number)
+		if f < 0 {
+			return nil, fmt.Errorf("negative value %v for uint64", number)
+		}
+		if i, ok := number.(int64); ok {
+			return uint64(i), nil
+		}
+		return uint64(f), nil
+	default:
+		f := toFloat(number)
+		if math.IsNaN(f) {
+			return nil, fmt.Errorf("value is NaN")
+		}
+		return f, nil
+	}
+}
+
+// Apply update counter with all action of event. counter kind mismatch is returned as error,
+// action before failed action already applied
+func (p *Pipeline) Apply(hm *stream_core.HashMapCounter, event Event) error {
+	actions, err := p.Actions(event)
+	if err != nil {
+		return err
+	}
+
+	for _, action := range actions {
+		err = ApplyAction(hm, action)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyAction update counter with single action
+func ApplyAction(hm *stream_core.HashMapCounter, action *Action) (err error) {
+	// merge counter panic on unsupported kind
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("rule %s key %s: %v", action.Rule, action.Key, r)
+		}
+	}()
+
+	switch {
+	case action.Op == OpMerge:
+		_, err = hm.Merge(action.Merge, action.Kind, action.Key, action.Sources...)
+	case action.Op == OpPut:
+		_, err = hm.Put(action.Key, action.Value)
+	case action.EventID != "":
+		_, err = hm.IncWithID(action.EventID, action.Key, action.Value)
+	default:
+		_, err = hm.Inc(action.Key, action.Value)
+	}
+	if err != nil {
+		return fmt.Errorf("rule %s: %w", action.Rule, err)
+	}
+	return nil
+}
+
+// AddToBatch add all action of event to batch, applied later by ApplyBatch
+func (p *Pipeline) AddToBatch(batch *stream_core.Batch, event Event) error {
+	actions, err := p.Actions(event)
+	if err != nil {
+		return err
+	}
+
+	for _, action := range actions {
+		BatchAction(batch, action)
+	}
+	return nil
+}
+
+// BatchAction add single action to batch, event id not used since batch deduped by committed source offset
+func BatchAction(batch *stream_core.Batch, action *Action) {
+	switch action.Op {
+	case OpMerge:
+		batch.Merge(action.Merge, action.Kind, action.Key, action.Sources...)
+	case OpPut:
+		switch val := action.Value.(type) {
+		case int64:
+			batch.PutInt64(action.Key, val)
+		case uint64:
+			batch.PutUint64(action.Key, val)
+		case float64:
+			batch.PutFloat64(action.Key, val)
+		}
+	default:
+		switch val := action.Value.(type) {
+		case int64:
+			batch.IncInt64(action.Key, val)
+		case uint64:
+			batch.IncUint64(action.Key, val)
+		case float64:
+			batch.IncFloat64(action.Key, val)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package pipeline_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/pipeline"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+const testRules = `
+timezone: Asia/Jakarta
+rules:
+  - name: team_debit
+    key: "teams/{team_id}/daily/{entry_time:date}/{account_key}/debit"
+    value: debit
+    kind: float64
+    op: inc
+  - name: team_credit
+    key: "teams/{team_id}/daily/{entry_time:date}/{account_key}/credit"
+    value: credit
+    kind: float64
+    op: inc
+  - name: debit_total
+    when: balance_type == "d"
+    key: "teams/{team_id}/daily/{entry_time:date}/{account_key}/total"
+    kind: float64
+    op: merge
+    merge: add
+    sources:
+      - "teams/{team_id}/daily/{entry_time:date}/{account_key}/debit"
+      - "teams/{team_id}/daily/{entry_time:date}/{account_key}/credit"
+  - name: transaction_count
+    key: "teams/{team_id}/transaction_count"
+    value: "1"
+    kind: uint64
+    op: inc
+  - name: last_shop
+    key: "teams/{team_id}/last_shop"
+    value: shop_id
+    kind: int64
+    op: put
+`
+
+func TestPipeline(t *testing.T) {
+	p, err := pipeline.New(mustParse(t, testRules))
+	assert.Nil(t, err)
+
+	event, err := pipeline.DecodeJSONEvent([]byte(`{
+		"team_id": "12",
+		"entry_time": "2025-01-01 20:30:00.000000 UTC",
+		"account_key": "cash",
+		"debit": "100.00",
+		"credit": "30.50",
+		"balance_type": "d",
+		"shop_id": 7
+	}`))
+	assert.Nil(t, err)
+
+	t.Run("testing actions", func(t *testing.T) {
+		actions, err := p.Actions(event)
+		assert.Nil(t, err)
+		assert.Len(t, actions, 5)
+
+		// entry time in jakarta already next day
+		assert.Equal(t, &pipeline.Action{
+			Rule:  "team_debit",
+			Op:    pipeline.OpInc,
+			Key:   "teams/12/daily/2025-01-02/cash/debit",
+			Kind:  reflect.Float64,
+			Value: float64(100),
+		}, actions[0])
+
+		assert.Equal(t, &pipeline.Action{
+			Rule:  "debit_total",
+			Op:    pipeline.OpMerge,
+			Key:   "teams/12/daily/2025-01-02/cash/total",
+			Kind:  reflect.Float64,
+			Merge: stream_core.MergeOpAdd,
+			Sources: []string{
+				"teams/12/daily/2025-01-02/cash/debit",
+				"teams/12/daily/2025-01-02/cash/credit",
+			},
+		}, actions[2])
+
+		assert.Equal(t, uint64(1), actions[3].Value)
+		assert.Equal(t, int64(7), actions[4].Value)
+	})
+
+	t.Run("testing when filter", func(t *testing.T) {
+		credit := pipeline.Event{}
+		for k, v := range event {
+			credit[k] = v
+		}
+		credit["balance_type"] = "c"
+
+		actions, err := p.Actions(credit)
+		assert.Nil(t, err)
+		assert.Len(t, actions, 4)
+	})
+
+	t.Run("testing missing field", func(t *testing.T) {
+		_, err := p.Actions(pipeline.Event{"team_id": "12"})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("testing apply to counter", func(t *testing.T) {
+		cfg := stream_core.CoreConfig{
+			HashMapCounterPath:  "/tmp/stream_engine/pipeline_unittest",
+			HashMapCounterSlots: 1024,
+			DynamicValuePath:    "/tmp/stream_engine/pipeline_value_unittest",
+		}
+		os.Remove(cfg.DynamicValuePath)
+		os.Remove(cfg.HashMapCounterPath)
+
+		kv, err := stream_core.NewHashMapCounter(&cfg)
+		assert.Nil(t, err)
+		defer kv.Close()
+
+		assert.Nil(t, p.Apply(kv, event))
+		assert.Nil(t, p.Apply(kv, event))
+
+		assert.Equal(t, float64(200), kv.GetFloat64("teams/12/daily/2025-01-02/cash/debit"))
+		assert.Equal(t, float64(61), kv.GetFloat64("teams/12/daily/2025-01-02/cash/credit"))
+		assert.Equal(t, float64(261), kv.GetFloat64("teams/12/daily/2025-01-02/cash/total"))
+		assert.Equal(t, uint64(2), kv.GetUint64("teams/12/transaction_count"))
+		assert.Equal(t, int64(7), kv.GetInt64("teams/12/last_shop"))
+
+		// kind mismatch returned as error
+		err = pipeline.ApplyAction(kv, &pipeline.Action{
+			Op:    pipeline.OpInc,
+			Key:   "teams/12/transaction_count",
+			Kind:  reflect.Int64,
+			Value: int64(1),
+		})
+		assert.ErrorIs(t, err, stream_core.ErrKindMismatch)
+	})
+
+	t.Run("testing event id dedupe", func(t *testing.T) {
+		cfg := stream_core.CoreConfig{
+			HashMapCounterPath:  "/tmp/stream_engine/pipeline_dedupe_unittest",
+			HashMapCounterSlots: 1024,
+			DynamicValuePath:    "/tmp/stream_engine/pipeline_dedupe_value_unittest",
+			DedupePath:          "/tmp/stream_engine/pipeline_dedupe_set_unittest",
+			DedupeExpectedIDs:   1000,
+			DedupeWindow:        1 << 10,
+		}
+		os.Remove(cfg.DynamicValuePath)
+		os.Remove(cfg.HashMapCounterPath)
+		os.Remove(cfg.DedupePath)
+
+		kv, err := stream_core.NewHashMapCounter(&cfg)
+		assert.Nil(t, err)
+		defer kv.Close()
+
+		rs := mustParse(t, testRules)
+		rs.EventID = "{id}"
+		p, err := pipeline.New(rs)
+		assert.Nil(t, err)
+
+		event["id"] = "trx-1"
+		defer delete(event, "id")
+
+		actions, err := p.Actions(event)
+		assert.Nil(t, err)
+		assert.Equal(t, "trx-1/team_debit", actions[0].EventID)
+		assert.Equal(t, "", actions[2].EventID)
+
+		// retried event not counted twice
+		assert.Nil(t, p.Apply(kv, event))
+		assert.Nil(t, p.Apply(kv, event))
+
+		assert.Equal(t, float64(100), kv.GetFloat64("teams/12/daily/2025-01-02/cash/debit"))
+		assert.Equal(t, float64(130.5), kv.GetFloat64("teams/12/daily/2025-01-02/cash/total"))
+		assert.Equal(t, uint64(1), kv.GetUint64("teams/12/transaction_count"))
+		assert.Equal(t, uint64(3), kv.DedupeStats().Hits)
+	})
+
+	t.Run("testing invalid rule", func(t *testing.T) {
+		for _, rules := range []string{
+			`rules: [{name: a, key: "x", value: "1", kind: int32, op: inc}]`,
+			`rules: [{name: a, key: "x", value: "1", kind: int64, op: dec}]`,
+			`rules: [{name: a, key: "{x:week}", value: "1", kind: int64, op: inc}]`,
+			`rules: [{name: a, key: "x", kind: int64, op: inc}]`,
+			`rules: [{name: a, key: "x", kind: int64, op: merge, merge: min}]`,
+		} {
+			_, err := pipeline.New(mustParse(t, rules))
+			assert.NotNil(t, err, rules)
+		}
+	})
+
+	t.Run("testing json rule file", func(t *testing.T) {
+		rs := mustParse(t, `{"rules": [{"name": "a", "key": "x/{id}", "value": "n * 2", "kind": "int64", "op": "inc"}]}`)
+		p, err := pipeline.New(rs)
+		assert.Nil(t, err)
+
+		actions, err := p.Actions(pipeline.Event{"id": "1", "n": int64(3)})
+		assert.Nil(t, err)
+		assert.Equal(t, "x/1", actions[0].Key)
+		assert.Equal(t, int64(6), actions[0].Value)
+	})
+}
+
+func mustParse(t *testing.T, data string) *pipeline.RuleSet {
+	rs, err := pipeline.ParseRuleSet([]byte(data))
+	assert.Nil(t, err)
+	return rs
+}
@@ -0,0 +1,95 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RuleSet rule file content, json file is valid yaml so both read by same parser
+type RuleSet struct {
+	// Timezone used by time format in key template, default UTC
+	Timezone string `yaml:"timezone"`
+	// TimeLayouts parsing time field, default DefaultTimeLayouts
+	TimeLayouts []string `yaml:"time_layouts"`
+	// EventID template of event id, inc of event already applied within dedupe horizon dropped
+	EventID string  `yaml:"event_id"`
+	Rules   []*Rule `yaml:"rules"`
+}
+
+type Rule struct {
+	Name string `yaml:"name"`
+	// When expression filtering event, empty apply rule to every event
+	When string `yaml:"when"`
+	// Key template of counter key
+	Key string `yaml:"key"`
+	// Value expression of delta or replaced value, not used by merge
+	Value string `yaml:"value"`
+	// Kind counter kind int64, uint64 or float64
+	Kind string `yaml:"kind"`
+	// Op inc, put or merge
+	Op string `yaml:"op"`
+	// Merge op add, min, multiply or divide of merge rule
+	Merge string `yaml:"merge"`
+	// Sources key template of merge source
+	Sources []string `yaml:"sources"`
+}
+
+func LoadRuleSet(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRuleSet(data)
+}
+
+func ParseRuleSet(data []byte) (*RuleSet, error) {
+	rs := &RuleSet{}
+	err := yaml.Unmarshal(data, rs)
+	if err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+type Op int
+
+const (
+	OpInc Op = iota
+	OpPut
+	OpMerge
+)
+
+var opNames = map[Op]string{
+	OpInc:   "inc",
+	OpPut:   "put",
+	OpMerge: "merge",
+}
+
+func (op Op) String() string {
+	return opNames[op]
+}
+
+func ParseOp(name string) (Op, error) {
+	for op, opName := range opNames {
+		if opName == name {
+			return op, nil
+		}
+	}
+	return 0, fmt.Errorf("op %s not supported", name)
+}
+
+func parseKind(name string) (reflect.Kind, error) {
+	switch name {
+	case "int64":
+		return reflect.Int64, nil
+	case "uint64":
+		return reflect.Uint64, nil
+	case "float64":
+		return reflect.Float64, nil
+	default:
+		return reflect.Invalid, fmt.Errorf("counter kind %q not supported", name)
+	}
+}
@@ -0,0 +1,168 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeLayouts layout tried in order when parsing time field
+var DefaultTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999 MST",
+	"2006-01-02 15:04:05.999999",
+	"2006-01-02",
+}
+
+var timeFormats = map[string]string{
+	"date":  "2006-01-02",
+	"month": "2006-01",
+	"year":  "2006",
+	"hour":  "2006-01-02T15",
+}
+
+// Template key template, {field} replaced by field value and {field:format} by formatted value.
+// format is date, month, year, hour for time field, and lower, upper, int
+type Template struct {
+	src   string
+	parts []templatePart
+}
+
+type templatePart struct {
+	literal string
+	field   string
+	format  string
+}
+
+type templateContext struct {
+	layouts  []string
+	location *time.Location
+}
+
+func CompileTemplate(src string) (*Template, error) {
+	t := &Template{src: src}
+
+	rest := src
+	for rest != "" {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			t.parts = append(t.parts, templatePart{literal: rest})
+			break
+		}
+		if start > 0 {
+			t.parts = append(t.parts, templatePart{literal: rest[:start]})
+		}
+
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("template %q unclosed placeholder", src)
+		}
+
+		placeholder := rest[start+1 : start+end]
+		field, format, _ := strings.Cut(placeholder, ":")
+		if field == "" {
+			return nil, fmt.Errorf("template %q empty placeholder", src)
+		}
+
+		switch format {
+		case "", "lower", "upper", "int":
+		default:
+			if _, ok := timeFormats[format]; !ok {
+				return nil, fmt.Errorf("template %q unknown format %s", src, format)
+			}
+		}
+
+		t.parts = append(t.parts, templatePart{field: field, format: format})
+		rest = rest[start+end+1:]
+	}
+
+	return t, nil
+}
+
+func (t *Template) String() string {
+	return t.src
+}
+
+func (t *Template) execute(event Event, ctx *templateContext) (string, error) {
+	var b strings.Builder
+	for _, part := range t.parts {
+		if part.field == "" {
+			b.WriteString(part.literal)
+			continue
+		}
+
+		value, ok := event.Field(part.field)
+		if !ok || value == nil {
+			return "", fmt.Errorf("template %q field %s missing", t.src, part.field)
+		}
+
+		text, err := formatField(value, part.format, ctx)
+		if err != nil {
+			return "", fmt.Errorf("template %q field %s: %w", t.src, part.field, err)
+		}
+		b.WriteString(text)
+	}
+	return b.String(), nil
+}
+
+func formatField(value any, format string, ctx *templateContext) (string, error) {
+	switch format {
+	case "":
+		return stringify(value), nil
+	case "lower":
+		return strings.ToLower(stringify(value)), nil
+	case "upper":
+		return strings.ToUpper(stringify(value)), nil
+	case "int":
+		number, err := toNumber(value)
+		if err != nil {
+			return "", err
+		}
+		if i, ok := number.(int64); ok {
+			return strconv.FormatInt(i, 10), nil
+		}
+		return strconv.FormatInt(int64(number.(float64)), 10), nil
+	default:
+		t, err := parseTime(value, ctx)
+		if err != nil {
+			return "", err
+		}
+		return t.Format(timeFormats[format]), nil
+	}
+}
+
+func stringify(value any) string {
+	switch val := value.(type) {
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// parseTime parse string with configured layout, number is unix second
+func parseTime(value any, ctx *templateContext) (time.Time, error) {
+	text, ok := value.(string)
+	if !ok {
+		number, err := toNumber(value)
+		if err != nil {
+			return time.Time{}, err
+		}
+		sec := toFloat(number)
+		return time.Unix(int64(sec), 0).In(ctx.location), nil
+	}
+
+	for _, layout := range ctx.layouts {
+		t, err := time.Parse(layout, text)
+		if err == nil {
+			return t.In(ctx.location), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("time %q not match any layout", text)
+}
@@ -0,0 +1,1102 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: counter/v1/counter.proto
+
+package counter
+
+import (
+	v1 "github.com/wargasipil/stream_engine/proto_core/snapshot_message/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Value counter value, kind of counter follow value set
+type Value struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Value:
+	//
+	//	*Value_Int64Value
+	//	*Value_Uint64Value
+	//	*Value_Float64Value
+	Value         isValue_Value `protobuf_oneof:"value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Value) Reset() {
+	*x = Value{}
+	mi := &file_counter_v1_counter_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Value) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Value) ProtoMessage() {}
+
+func (x *Value) ProtoReflect() protoreflect.Message {
+	mi := &file_counter_v1_counter_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Value.ProtoReflect.Descriptor instead.
+func (*Value) Descriptor() ([]byte, []int) {
+	return file_counter_v1_counter_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Value) GetValue() isValue_Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *Value) GetInt64Value() int64 {
+	if x != nil {
+		if x, ok := x.Value.(*Value_Int64Value); ok {
+			return x.Int64Value
+		}
+	}
+	return 0
+}
+
+func (x *Value) GetUint64Value() uint64 {
+	if x != nil {
+		if x, ok := x.Value.(*Value_Uint64Value); ok {
+			return x.Uint64Value
+		}
+	}
+	return 0
+}
+
+func (x *Value) GetFloat64Value() float64 {
+	if x != nil {
+		if x, ok := x.Value.(*Value_Float64Value); ok {
+			return x.Float64Value
+		}
+	}
+	return 0
+}
+
+type isValue_Value interface {
+	isValue_Value()
+}
+
+type Value_Int64Value struct {
+	Int64Value int64 `protobuf:"varint,1,opt,name=int64_value,json=int64Value,proto3,oneof"`
+}
+
+type Value_Uint64Value struct {
+	Uint64Value uint64 `protobuf:"varint,2,opt,name=uint64_value,json=uint64Value,proto3,oneof"`
+}
+
+type Value_Float64Value struct {
+	Float64Value float64 `protobuf:"fixed64,3,opt,name=float64_value,json=float64Value,proto3,oneof"`
+}
+
+func (*Value_Int64Value) isValue_Value() {}
+
+func (*Value_Uint64Value) isValue_Value() {}
+
+func (*Value_Float64Value) isValue_Value() {}
+
+type IncrementRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Delta *Value                 `protobuf:"bytes,2,opt,name=delta,proto3" json:"delta,omitempty"`
+	// event_id drop increment already applied with same id, need counter dedupe configured
+	EventId       string `protobuf:"bytes,3,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IncrementRequest) Reset() {
+	*x = IncrementRequest{}
+	mi := &file_counter_v1_counter_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IncrementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncrementRequest) ProtoMessage() {}
+
+func (x *IncrementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_counter_v1_counter_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncrementRequest.ProtoReflect.Descriptor instead.
+func (*IncrementRequest) Descriptor() ([]byte, []int) {
+	return file_counter_v1_counter_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *IncrementRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *IncrementRequest) GetDelta() *Value {
+	if x != nil {
+		return x.Delta
+	}
+	return nil
+}
+
+func (x *IncrementRequest) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+type IncrementResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Record *v1.KeyRecord          `protobuf:"bytes,1,opt,name=record,proto3" json:"record,omitempty"`
+	// applied false when increment dropped as duplicate event
+	Applied       bool `protobuf:"varint,2,opt,name=applied,proto3" json:"applied,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IncrementResponse) Reset() {
+	*x = IncrementResponse{}
+	mi := &file_counter_v1_counter_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IncrementResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncrementResponse) ProtoMessage() {}
+
+func (x *IncrementResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_counter_v1_counter_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncrementResponse.ProtoReflect.Descriptor instead.
+func (*IncrementResponse) Descriptor() ([]byte, []int) {
+	return file_counter_v1_counter_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *IncrementResponse) GetRecord() *v1.KeyRecord {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+func (x *IncrementResponse) GetApplied() bool {
+	if x != nil {
+		return x.Applied
+	}
+	return false
+}
+
+type PutRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         *Value                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutRequest) Reset() {
+	*x = PutRequest{}
+	mi := &file_counter_v1_counter_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutRequest) ProtoMessage() {}
+
+func (x *PutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_counter_v1_counter_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutRequest.ProtoReflect.Descriptor instead.
+func (*PutRequest) Descriptor() ([]byte, []int) {
+	return file_counter_v1_counter_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PutRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *PutRequest) GetValue() *Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type PutResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Record        *v1.KeyRecord          `protobuf:"bytes,1,opt,name=record,proto3" json:"record,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutResponse) Reset() {
+	*x = PutResponse{}
+	mi := &file_counter_v1_counter_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutResponse) ProtoMessage() {}
+
+func (x *PutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_counter_v1_counter_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutResponse.ProtoReflect.Descriptor instead.
+func (*PutResponse) Descriptor() ([]byte, []int) {
+	return file_counter_v1_counter_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PutResponse) GetRecord() *v1.KeyRecord {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_counter_v1_counter_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_counter_v1_counter_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_counter_v1_counter_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type GetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Record        *v1.KeyRecord          `protobuf:"bytes,1,opt,name=record,proto3" json:"record,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetResponse) Reset() {
+	*x = GetResponse{}
+	mi := &file_counter_v1_counter_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResponse) ProtoMessage() {}
+
+func (x *GetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_counter_v1_counter_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResponse.ProtoReflect.Descriptor instead.
+func (*GetResponse) Descriptor() ([]byte, []int) {
+	return file_counter_v1_counter_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetResponse) GetRecord() *v1.KeyRecord {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+type BatchGetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetRequest) Reset() {
+	*x = BatchGetRequest{}
+	mi := &file_counter_v1_counter_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetRequest) ProtoMessage() {}
+
+func (x *BatchGetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_counter_v1_counter_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetRequest) Descriptor() ([]byte, []int) {
+	return file_counter_v1_counter_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BatchGetRequest) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type BatchGetResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// records of key found, in request order
+	Records []*v1.KeyRecord `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	// missing key never written
+	Missing       []string `protobuf:"bytes,2,rep,name=missing,proto3" json:"missing,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetResponse) Reset() {
+	*x = BatchGetResponse{}
+	mi := &file_counter_v1_counter_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetResponse) ProtoMessage() {}
+
+func (x *BatchGetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_counter_v1_counter_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetResponse) Descriptor() ([]byte, []int) {
+	return file_counter_v1_counter_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BatchGetResponse) GetRecords() []*v1.KeyRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+func (x *BatchGetResponse) GetMissing() []string {
+	if x != nil {
+		return x.Missing
+	}
+	return nil
+}
+
+type MergeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Kind          v1.CounterKind         `protobuf:"varint,2,opt,name=kind,proto3,enum=snapshot_message.v1.CounterKind" json:"kind,omitempty"`
+	Op            v1.MergeOp             `protobuf:"varint,3,opt,name=op,proto3,enum=snapshot_message.v1.MergeOp" json:"op,omitempty"`
+	Sources       []string               `protobuf:"bytes,4,rep,name=sources,proto3" json:"sources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MergeRequest) Reset() {
+	*x = MergeRequest{}
+	mi := &file_counter_v1_counter_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MergeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MergeRequest) ProtoMessage() {}
+
+func (x *MergeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_counter_v1_counter_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MergeRequest.ProtoReflect.Descriptor instead.
+func (*MergeRequest) Descriptor() ([]byte, []int) {
+	return file_counter_v1_counter_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *MergeRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *MergeRequest) GetKind() v1.CounterKind {
+	if x != nil {
+		return x.Kind
+	}
+	return v1.CounterKind(0)
+}
+
+func (x *MergeRequest) GetOp() v1.MergeOp {
+	if x != nil {
+		return x.Op
+	}
+	return v1.MergeOp(0)
+}
+
+func (x *MergeRequest) GetSources() []string {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+type MergeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Record        *v1.KeyRecord          `protobuf:"bytes,1,opt,name=record,proto3" json:"record,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MergeResponse) Reset() {
+	*x = MergeResponse{}
+	mi := &file_counter_v1_counter_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MergeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MergeResponse) ProtoMessage() {}
+
+func (x *MergeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_counter_v1_counter_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MergeResponse.ProtoReflect.Descriptor instead.
+func (*MergeResponse) Descriptor() ([]byte, []int) {
+	return file_counter_v1_counter_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *MergeResponse) GetRecord() *v1.KeyRecord {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+type SnapshotRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// prefix empty is all key
+	Prefix string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// since only key updated at or after since in unix millisecond, 0 is all key
+	Since         int64 `protobuf:"varint,2,opt,name=since,proto3" json:"since,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotRequest) Reset() {
+	*x = SnapshotRequest{}
+	mi := &file_counter_v1_counter_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotRequest) ProtoMessage() {}
+
+func (x *SnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_counter_v1_counter_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotRequest.ProtoReflect.Descriptor instead.
+func (*SnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_counter_v1_counter_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SnapshotRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *SnapshotRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+// SnapshotResponse one batch of record, all batch consistent at time snapshot opened
+type SnapshotResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Records       []*v1.KeyRecord        `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotResponse) Reset() {
+	*x = SnapshotResponse{}
+	mi := &file_counter_v1_counter_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotResponse) ProtoMessage() {}
+
+func (x *SnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_counter_v1_counter_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotResponse.ProtoReflect.Descriptor instead.
+func (*SnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_counter_v1_counter_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SnapshotResponse) GetRecords() []*v1.KeyRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+type WatchRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Prefix string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// buffer change buffered for slow client, stream closed when buffer full
+	Buffer        int32 `protobuf:"varint,2,opt,name=buffer,proto3" json:"buffer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_counter_v1_counter_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_counter_v1_counter_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_counter_v1_counter_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *WatchRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *WatchRequest) GetBuffer() int32 {
+	if x != nil {
+		return x.Buffer
+	}
+	return 0
+}
+
+type WatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Seq   uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Key   string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Kind  v1.CounterKind         `protobuf:"varint,3,opt,name=kind,proto3,enum=snapshot_message.v1.CounterKind" json:"kind,omitempty"`
+	// old unset when key created
+	Old *Value `protobuf:"bytes,4,opt,name=old,proto3" json:"old,omitempty"`
+	New *Value `protobuf:"bytes,5,opt,name=new,proto3" json:"new,omitempty"`
+	// timestamp in unix millisecond
+	Timestamp     int64 `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchResponse) Reset() {
+	*x = WatchResponse{}
+	mi := &file_counter_v1_counter_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchResponse) ProtoMessage() {}
+
+func (x *WatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_counter_v1_counter_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchResponse.ProtoReflect.Descriptor instead.
+func (*WatchResponse) Descriptor() ([]byte, []int) {
+	return file_counter_v1_counter_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *WatchResponse) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *WatchResponse) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *WatchResponse) GetKind() v1.CounterKind {
+	if x != nil {
+		return x.Kind
+	}
+	return v1.CounterKind(0)
+}
+
+func (x *WatchResponse) GetOld() *Value {
+	if x != nil {
+		return x.Old
+	}
+	return nil
+}
+
+func (x *WatchResponse) GetNew() *Value {
+	if x != nil {
+		return x.New
+	}
+	return nil
+}
+
+func (x *WatchResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// KindMismatch error detail of update with kind different from existing counter
+type KindMismatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Kind          v1.CounterKind         `protobuf:"varint,2,opt,name=kind,proto3,enum=snapshot_message.v1.CounterKind" json:"kind,omitempty"`
+	ExistingKind  v1.CounterKind         `protobuf:"varint,3,opt,name=existing_kind,json=existingKind,proto3,enum=snapshot_message.v1.CounterKind" json:"existing_kind,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KindMismatch) Reset() {
+	*x = KindMismatch{}
+	mi := &file_counter_v1_counter_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KindMismatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KindMismatch) ProtoMessage() {}
+
+func (x *KindMismatch) ProtoReflect() protoreflect.Message {
+	mi := &file_counter_v1_counter_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KindMismatch.ProtoReflect.Descriptor instead.
+func (*KindMismatch) Descriptor() ([]byte, []int) {
+	return file_counter_v1_counter_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *KindMismatch) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *KindMismatch) GetKind() v1.CounterKind {
+	if x != nil {
+		return x.Kind
+	}
+	return v1.CounterKind(0)
+}
+
+func (x *KindMismatch) GetExistingKind() v1.CounterKind {
+	if x != nil {
+		return x.ExistingKind
+	}
+	return v1.CounterKind(0)
+}
+
+var File_counter_v1_counter_proto protoreflect.FileDescriptor
+
+const file_counter_v1_counter_proto_rawDesc = "" +
+	"\n" +
+	"\x18counter/v1/counter.proto\x12\n" +
+	"counter.v1\x1a\"snapshot_message/v1/snapshot.proto\"\x7f\n" +
+	"\x05Value\x12!\n" +
+	"\vint64_value\x18\x01 \x01(\x03H\x00R\n" +
+	"int64Value\x12#\n" +
+	"\fuint64_value\x18\x02 \x01(\x04H\x00R\vuint64Value\x12%\n" +
+	"\rfloat64_value\x18\x03 \x01(\x01H\x00R\ffloat64ValueB\a\n" +
+	"\x05value\"h\n" +
+	"\x10IncrementRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12'\n" +
+	"\x05delta\x18\x02 \x01(\v2\x11.counter.v1.ValueR\x05delta\x12\x19\n" +
+	"\bevent_id\x18\x03 \x01(\tR\aeventId\"e\n" +
+	"\x11IncrementResponse\x126\n" +
+	"\x06record\x18\x01 \x01(\v2\x1e.snapshot_message.v1.KeyRecordR\x06record\x12\x18\n" +
+	"\aapplied\x18\x02 \x01(\bR\aapplied\"G\n" +
+	"\n" +
+	"PutRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12'\n" +
+	"\x05value\x18\x02 \x01(\v2\x11.counter.v1.ValueR\x05value\"E\n" +
+	"\vPutResponse\x126\n" +
+	"\x06record\x18\x01 \x01(\v2\x1e.snapshot_message.v1.KeyRecordR\x06record\"\x1e\n" +
+	"\n" +
+	"GetRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"E\n" +
+	"\vGetResponse\x126\n" +
+	"\x06record\x18\x01 \x01(\v2\x1e.snapshot_message.v1.KeyRecordR\x06record\"%\n" +
+	"\x0fBatchGetRequest\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\"f\n" +
+	"\x10BatchGetResponse\x128\n" +
+	"\arecords\x18\x01 \x03(\v2\x1e.snapshot_message.v1.KeyRecordR\arecords\x12\x18\n" +
+	"\amissing\x18\x02 \x03(\tR\amissing\"\x9e\x01\n" +
+	"\fMergeRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x124\n" +
+	"\x04kind\x18\x02 \x01(\x0e2 .snapshot_message.v1.CounterKindR\x04kind\x12,\n" +
+	"\x02op\x18\x03 \x01(\x0e2\x1c.snapshot_message.v1.MergeOpR\x02op\x12\x18\n" +
+	"\asources\x18\x04 \x03(\tR\asources\"G\n" +
+	"\rMergeResponse\x126\n" +
+	"\x06record\x18\x01 \x01(\v2\x1e.snapshot_message.v1.KeyRecordR\x06record\"?\n" +
+	"\x0fSnapshotRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12\x14\n" +
+	"\x05since\x18\x02 \x01(\x03R\x05since\"L\n" +
+	"\x10SnapshotResponse\x128\n" +
+	"\arecords\x18\x01 \x03(\v2\x1e.snapshot_message.v1.KeyRecordR\arecords\">\n" +
+	"\fWatchRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12\x16\n" +
+	"\x06buffer\x18\x02 \x01(\x05R\x06buffer\"\xd1\x01\n" +
+	"\rWatchResponse\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x124\n" +
+	"\x04kind\x18\x03 \x01(\x0e2 .snapshot_message.v1.CounterKindR\x04kind\x12#\n" +
+	"\x03old\x18\x04 \x01(\v2\x11.counter.v1.ValueR\x03old\x12#\n" +
+	"\x03new\x18\x05 \x01(\v2\x11.counter.v1.ValueR\x03new\x12\x1c\n" +
+	"\ttimestamp\x18\x06 \x01(\x03R\ttimestamp\"\x9d\x01\n" +
+	"\fKindMismatch\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x124\n" +
+	"\x04kind\x18\x02 \x01(\x0e2 .snapshot_message.v1.CounterKindR\x04kind\x12E\n" +
+	"\rexisting_kind\x18\x03 \x01(\x0e2 .snapshot_message.v1.CounterKindR\fexistingKind2\xe6\x03\n" +
+	"\x0eCounterService\x12J\n" +
+	"\tIncrement\x12\x1c.counter.v1.IncrementRequest\x1a\x1d.counter.v1.IncrementResponse\"\x00\x128\n" +
+	"\x03Put\x12\x16.counter.v1.PutRequest\x1a\x17.counter.v1.PutResponse\"\x00\x128\n" +
+	"\x03Get\x12\x16.counter.v1.GetRequest\x1a\x17.counter.v1.GetResponse\"\x00\x12G\n" +
+	"\bBatchGet\x12\x1b.counter.v1.BatchGetRequest\x1a\x1c.counter.v1.BatchGetResponse\"\x00\x12>\n" +
+	"\x05Merge\x12\x18.counter.v1.MergeRequest\x1a\x19.counter.v1.MergeResponse\"\x00\x12I\n" +
+	"\bSnapshot\x12\x1b.counter.v1.SnapshotRequest\x1a\x1c.counter.v1.SnapshotResponse\"\x000\x01\x12@\n" +
+	"\x05Watch\x12\x18.counter.v1.WatchRequest\x1a\x19.counter.v1.WatchResponse\"\x000\x01B\xaa\x01\n" +
+	"\x0ecom.counter.v1B\fCounterProtoP\x01ZAgithub.com/wargasipil/stream_engine/proto_core/counter/v1;counter\xa2\x02\x03CXX\xaa\x02\n" +
+	"Counter.V1\xca\x02\n" +
+	"Counter\\V1\xe2\x02\x16Counter\\V1\\GPBMetadata\xea\x02\vCounter::V1b\x06proto3"
+
+var (
+	file_counter_v1_counter_proto_rawDescOnce sync.Once
+	file_counter_v1_counter_proto_rawDescData []byte
+)
+
+func file_counter_v1_counter_proto_rawDescGZIP() []byte {
+	file_counter_v1_counter_proto_rawDescOnce.Do(func() {
+		file_counter_v1_counter_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_counter_v1_counter_proto_rawDesc), len(file_counter_v1_counter_proto_rawDesc)))
+	})
+	return file_counter_v1_counter_proto_rawDescData
+}
+
+var file_counter_v1_counter_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_counter_v1_counter_proto_goTypes = []any{
+	(*Value)(nil),             // 0: counter.v1.Value
+	(*IncrementRequest)(nil),  // 1: counter.v1.IncrementRequest
+	(*IncrementResponse)(nil), // 2: counter.v1.IncrementResponse
+	(*PutRequest)(nil),        // 3: counter.v1.PutRequest
+	(*PutResponse)(nil),       // 4: counter.v1.PutResponse
+	(*GetRequest)(nil),        // 5: counter.v1.GetRequest
+	(*GetResponse)(nil),       // 6: counter.v1.GetResponse
+	(*BatchGetRequest)(nil),   // 7: counter.v1.BatchGetRequest
+	(*BatchGetResponse)(nil),  // 8: counter.v1.BatchGetResponse
+	(*MergeRequest)(nil),      // 9: counter.v1.MergeRequest
+	(*MergeResponse)(nil),     // 10: counter.v1.MergeResponse
+	(*SnapshotRequest)(nil),   // 11: counter.v1.SnapshotRequest
+	(*SnapshotResponse)(nil),  // 12: counter.v1.SnapshotResponse
+	(*WatchRequest)(nil),      // 13: counter.v1.WatchRequest
+	(*WatchResponse)(nil),     // 14: counter.v1.WatchResponse
+	(*KindMismatch)(nil),      // 15: counter.v1.KindMismatch
+	(*v1.KeyRecord)(nil),      // 16: snapshot_message.v1.KeyRecord
+	(v1.CounterKind)(0),       // 17: snapshot_message.v1.CounterKind
+	(v1.MergeOp)(0),           // 18: snapshot_message.v1.MergeOp
+}
+var file_counter_v1_counter_proto_depIdxs = []int32{
+	0,  // 0: counter.v1.IncrementRequest.delta:type_name -> counter.v1.Value
+	16, // 1: counter.v1.IncrementResponse.record:type_name -> snapshot_message.v1.KeyRecord
+	0,  // 2: counter.v1.PutRequest.value:type_name -> counter.v1.Value
+	16, // 3: counter.v1.PutResponse.record:type_name -> snapshot_message.v1.KeyRecord
+	16, // 4: counter.v1.GetResponse.record:type_name -> snapshot_message.v1.KeyRecord
+	16, // 5: counter.v1.BatchGetResponse.records:type_name -> snapshot_message.v1.KeyRecord
+	17, // 6: counter.v1.MergeRequest.kind:type_name -> snapshot_message.v1.CounterKind
+	18, // 7: counter.v1.MergeRequest.op:type_name -> snapshot_message.v1.MergeOp
+	16, // 8: counter.v1.MergeResponse.record:type_name -> snapshot_message.v1.KeyRecord
+	16, // 9: counter.v1.SnapshotResponse.records:type_name -> snapshot_message.v1.KeyRecord
+	17, // 10: counter.v1.WatchResponse.kind:type_name -> snapshot_message.v1.CounterKind
+	0,  // 11: counter.v1.WatchResponse.old:type_name -> counter.v1.Value
+	0,  // 12: counter.v1.WatchResponse.new:type_name -> counter.v1.Value
+	17, // 13: counter.v1.KindMismatch.kind:type_name -> snapshot_message.v1.CounterKind
+	17, // 14: counter.v1.KindMismatch.existing_kind:type_name -> snapshot_message.v1.CounterKind
+	1,  // 15: counter.v1.CounterService.Increment:input_type -> counter.v1.IncrementRequest
+	3,  // 16: counter.v1.CounterService.Put:input_type -> counter.v1.PutRequest
+	5,  // 17: counter.v1.CounterService.Get:input_type -> counter.v1.GetRequest
+	7,  // 18: counter.v1.CounterService.BatchGet:input_type -> counter.v1.BatchGetRequest
+	9,  // 19: counter.v1.CounterService.Merge:input_type -> counter.v1.MergeRequest
+	11, // 20: counter.v1.CounterService.Snapshot:input_type -> counter.v1.SnapshotRequest
+	13, // 21: counter.v1.CounterService.Watch:input_type -> counter.v1.WatchRequest
+	2,  // 22: counter.v1.CounterService.Increment:output_type -> counter.v1.IncrementResponse
+	4,  // 23: counter.v1.CounterService.Put:output_type -> counter.v1.PutResponse
+	6,  // 24: counter.v1.CounterService.Get:output_type -> counter.v1.GetResponse
+	8,  // 25: counter.v1.CounterService.BatchGet:output_type -> counter.v1.BatchGetResponse
+	10, // 26: counter.v1.CounterService.Merge:output_type -> counter.v1.MergeResponse
+	12, // 27: counter.v1.CounterService.Snapshot:output_type -> counter.v1.SnapshotResponse
+	14, // 28: counter.v1.CounterService.Watch:output_type -> counter.v1.WatchResponse
+	22, // [22:29] is the sub-list for method output_type
+	15, // [15:22] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_counter_v1_counter_proto_init() }
+func file_counter_v1_counter_proto_init() {
+	if File_counter_v1_counter_proto != nil {
+		return
+	}
+	file_counter_v1_counter_proto_msgTypes[0].OneofWrappers = []any{
+		(*Value_Int64Value)(nil),
+		(*Value_Uint64Value)(nil),
+		(*Value_Float64Value)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_counter_v1_counter_proto_rawDesc), len(file_counter_v1_counter_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_counter_v1_counter_proto_goTypes,
+		DependencyIndexes: file_counter_v1_counter_proto_depIdxs,
+		MessageInfos:      file_counter_v1_counter_proto_msgTypes,
+	}.Build()
+	File_counter_v1_counter_proto = out.File
+	file_counter_v1_counter_proto_goTypes = nil
+	file_counter_v1_counter_proto_depIdxs = nil
+}
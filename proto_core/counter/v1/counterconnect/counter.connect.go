@@ -0,0 +1,277 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: counter/v1/counter.proto
+
+package counterconnect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	v1 "github.com/wargasipil/stream_engine/proto_core/counter/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// CounterServiceName is the fully-qualified name of the CounterService service.
+	CounterServiceName = "counter.v1.CounterService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// CounterServiceIncrementProcedure is the fully-qualified name of the CounterService's Increment
+	// RPC.
+	CounterServiceIncrementProcedure = "/counter.v1.CounterService/Increment"
+	// CounterServicePutProcedure is the fully-qualified name of the CounterService's Put RPC.
+	CounterServicePutProcedure = "/counter.v1.CounterService/Put"
+	// CounterServiceGetProcedure is the fully-qualified name of the CounterService's Get RPC.
+	CounterServiceGetProcedure = "/counter.v1.CounterService/Get"
+	// CounterServiceBatchGetProcedure is the fully-qualified name of the CounterService's BatchGet RPC.
+	CounterServiceBatchGetProcedure = "/counter.v1.CounterService/BatchGet"
+	// CounterServiceMergeProcedure is the fully-qualified name of the CounterService's Merge RPC.
+	CounterServiceMergeProcedure = "/counter.v1.CounterService/Merge"
+	// CounterServiceSnapshotProcedure is the fully-qualified name of the CounterService's Snapshot RPC.
+	CounterServiceSnapshotProcedure = "/counter.v1.CounterService/Snapshot"
+	// CounterServiceWatchProcedure is the fully-qualified name of the CounterService's Watch RPC.
+	CounterServiceWatchProcedure = "/counter.v1.CounterService/Watch"
+)
+
+// CounterServiceClient is a client for the counter.v1.CounterService service.
+type CounterServiceClient interface {
+	Increment(context.Context, *connect.Request[v1.IncrementRequest]) (*connect.Response[v1.IncrementResponse], error)
+	Put(context.Context, *connect.Request[v1.PutRequest]) (*connect.Response[v1.PutResponse], error)
+	Get(context.Context, *connect.Request[v1.GetRequest]) (*connect.Response[v1.GetResponse], error)
+	BatchGet(context.Context, *connect.Request[v1.BatchGetRequest]) (*connect.Response[v1.BatchGetResponse], error)
+	Merge(context.Context, *connect.Request[v1.MergeRequest]) (*connect.Response[v1.MergeResponse], error)
+	Snapshot(context.Context, *connect.Request[v1.SnapshotRequest]) (*connect.ServerStreamForClient[v1.SnapshotResponse], error)
+	Watch(context.Context, *connect.Request[v1.WatchRequest]) (*connect.ServerStreamForClient[v1.WatchResponse], error)
+}
+
+// NewCounterServiceClient constructs a client for the counter.v1.CounterService service. By
+// default, it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses,
+// and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the
+// connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewCounterServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) CounterServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	counterServiceMethods := v1.File_counter_v1_counter_proto.Services().ByName("CounterService").Methods()
+	return &counterServiceClient{
+		increment: connect.NewClient[v1.IncrementRequest, v1.IncrementResponse](
+			httpClient,
+			baseURL+CounterServiceIncrementProcedure,
+			connect.WithSchema(counterServiceMethods.ByName("Increment")),
+			connect.WithClientOptions(opts...),
+		),
+		put: connect.NewClient[v1.PutRequest, v1.PutResponse](
+			httpClient,
+			baseURL+CounterServicePutProcedure,
+			connect.WithSchema(counterServiceMethods.ByName("Put")),
+			connect.WithClientOptions(opts...),
+		),
+		get: connect.NewClient[v1.GetRequest, v1.GetResponse](
+			httpClient,
+			baseURL+CounterServiceGetProcedure,
+			connect.WithSchema(counterServiceMethods.ByName("Get")),
+			connect.WithClientOptions(opts...),
+		),
+		batchGet: connect.NewClient[v1.BatchGetRequest, v1.BatchGetResponse](
+			httpClient,
+			baseURL+CounterServiceBatchGetProcedure,
+			connect.WithSchema(counterServiceMethods.ByName("BatchGet")),
+			connect.WithClientOptions(opts...),
+		),
+		merge: connect.NewClient[v1.MergeRequest, v1.MergeResponse](
+			httpClient,
+			baseURL+CounterServiceMergeProcedure,
+			connect.WithSchema(counterServiceMethods.ByName("Merge")),
+			connect.WithClientOptions(opts...),
+		),
+		snapshot: connect.NewClient[v1.SnapshotRequest, v1.SnapshotResponse](
+			httpClient,
+			baseURL+CounterServiceSnapshotProcedure,
+			connect.WithSchema(counterServiceMethods.ByName("Snapshot")),
+			connect.WithClientOptions(opts...),
+		),
+		watch: connect.NewClient[v1.WatchRequest, v1.WatchResponse](
+			httpClient,
+			baseURL+CounterServiceWatchProcedure,
+			connect.WithSchema(counterServiceMethods.ByName("Watch")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// counterServiceClient implements CounterServiceClient.
+type counterServiceClient struct {
+	increment *connect.Client[v1.IncrementRequest, v1.IncrementResponse]
+	put       *connect.Client[v1.PutRequest, v1.PutResponse]
+	get       *connect.Client[v1.GetRequest, v1.GetResponse]
+	batchGet  *connect.Client[v1.BatchGetRequest, v1.BatchGetResponse]
+	merge     *connect.Client[v1.MergeRequest, v1.MergeResponse]
+	snapshot  *connect.Client[v1.SnapshotRequest, v1.SnapshotResponse]
+	watch     *connect.Client[v1.WatchRequest, v1.WatchResponse]
+}
+
+// Increment calls counter.v1.CounterService.Increment.
+func (c *counterServiceClient) Increment(ctx context.Context, req *connect.Request[v1.IncrementRequest]) (*connect.Response[v1.IncrementResponse], error) {
+	return c.increment.CallUnary(ctx, req)
+}
+
+// Put calls counter.v1.CounterService.Put.
+func (c *counterServiceClient) Put(ctx context.Context, req *connect.Request[v1.PutRequest]) (*connect.Response[v1.PutResponse], error) {
+	return c.put.CallUnary(ctx, req)
+}
+
+// Get calls counter.v1.CounterService.Get.
+func (c *counterServiceClient) Get(ctx context.Context, req *connect.Request[v1.GetRequest]) (*connect.Response[v1.GetResponse], error) {
+	return c.get.CallUnary(ctx, req)
+}
+
+// BatchGet calls counter.v1.CounterService.BatchGet.
+func (c *counterServiceClient) BatchGet(ctx context.Context, req *connect.Request[v1.BatchGetRequest]) (*connect.Response[v1.BatchGetResponse], error) {
+	return c.batchGet.CallUnary(ctx, req)
+}
+
+// Merge calls counter.v1.CounterService.Merge.
+func (c *counterServiceClient) Merge(ctx context.Context, req *connect.Request[v1.MergeRequest]) (*connect.Response[v1.MergeResponse], error) {
+	return c.merge.CallUnary(ctx, req)
+}
+
+// Snapshot calls counter.v1.CounterService.Snapshot.
+func (c *counterServiceClient) Snapshot(ctx context.Context, req *connect.Request[v1.SnapshotRequest]) (*connect.ServerStreamForClient[v1.SnapshotResponse], error) {
+	return c.snapshot.CallServerStream(ctx, req)
+}
+
+// Watch calls counter.v1.CounterService.Watch.
+func (c *counterServiceClient) Watch(ctx context.Context, req *connect.Request[v1.WatchRequest]) (*connect.ServerStreamForClient[v1.WatchResponse], error) {
+	return c.watch.CallServerStream(ctx, req)
+}
+
+// CounterServiceHandler is an implementation of the counter.v1.CounterService service.
+type CounterServiceHandler interface {
+	Increment(context.Context, *connect.Request[v1.IncrementRequest]) (*connect.Response[v1.IncrementResponse], error)
+	Put(context.Context, *connect.Request[v1.PutRequest]) (*connect.Response[v1.PutResponse], error)
+	Get(context.Context, *connect.Request[v1.GetRequest]) (*connect.Response[v1.GetResponse], error)
+	BatchGet(context.Context, *connect.Request[v1.BatchGetRequest]) (*connect.Response[v1.BatchGetResponse], error)
+	Merge(context.Context, *connect.Request[v1.MergeRequest]) (*connect.Response[v1.MergeResponse], error)
+	Snapshot(context.Context, *connect.Request[v1.SnapshotRequest], *connect.ServerStream[v1.SnapshotResponse]) error
+	Watch(context.Context, *connect.Request[v1.WatchRequest], *connect.ServerStream[v1.WatchResponse]) error
+}
+
+// NewCounterServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewCounterServiceHandler(svc CounterServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	counterServiceMethods := v1.File_counter_v1_counter_proto.Services().ByName("CounterService").Methods()
+	counterServiceIncrementHandler := connect.NewUnaryHandler(
+		CounterServiceIncrementProcedure,
+		svc.Increment,
+		connect.WithSchema(counterServiceMethods.ByName("Increment")),
+		connect.WithHandlerOptions(opts...),
+	)
+	counterServicePutHandler := connect.NewUnaryHandler(
+		CounterServicePutProcedure,
+		svc.Put,
+		connect.WithSchema(counterServiceMethods.ByName("Put")),
+		connect.WithHandlerOptions(opts...),
+	)
+	counterServiceGetHandler := connect.NewUnaryHandler(
+		CounterServiceGetProcedure,
+		svc.Get,
+		connect.WithSchema(counterServiceMethods.ByName("Get")),
+		connect.WithHandlerOptions(opts...),
+	)
+	counterServiceBatchGetHandler := connect.NewUnaryHandler(
+		CounterServiceBatchGetProcedure,
+		svc.BatchGet,
+		connect.WithSchema(counterServiceMethods.ByName("BatchGet")),
+		connect.WithHandlerOptions(opts...),
+	)
+	counterServiceMergeHandler := connect.NewUnaryHandler(
+		CounterServiceMergeProcedure,
+		svc.Merge,
+		connect.WithSchema(counterServiceMethods.ByName("Merge")),
+		connect.WithHandlerOptions(opts...),
+	)
+	counterServiceSnapshotHandler := connect.NewServerStreamHandler(
+		CounterServiceSnapshotProcedure,
+		svc.Snapshot,
+		connect.WithSchema(counterServiceMethods.ByName("Snapshot")),
+		connect.WithHandlerOptions(opts...),
+	)
+	counterServiceWatchHandler := connect.NewServerStreamHandler(
+		CounterServiceWatchProcedure,
+		svc.Watch,
+		connect.WithSchema(counterServiceMethods.ByName("Watch")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/counter.v1.CounterService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case CounterServiceIncrementProcedure:
+			counterServiceIncrementHandler.ServeHTTP(w, r)
+		case CounterServicePutProcedure:
+			counterServicePutHandler.ServeHTTP(w, r)
+		case CounterServiceGetProcedure:
+			counterServiceGetHandler.ServeHTTP(w, r)
+		case CounterServiceBatchGetProcedure:
+			counterServiceBatchGetHandler.ServeHTTP(w, r)
+		case CounterServiceMergeProcedure:
+			counterServiceMergeHandler.ServeHTTP(w, r)
+		case CounterServiceSnapshotProcedure:
+			counterServiceSnapshotHandler.ServeHTTP(w, r)
+		case CounterServiceWatchProcedure:
+			counterServiceWatchHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedCounterServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedCounterServiceHandler struct{}
+
+func (UnimplementedCounterServiceHandler) Increment(context.Context, *connect.Request[v1.IncrementRequest]) (*connect.Response[v1.IncrementResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("counter.v1.CounterService.Increment is not implemented"))
+}
+
+func (UnimplementedCounterServiceHandler) Put(context.Context, *connect.Request[v1.PutRequest]) (*connect.Response[v1.PutResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("counter.v1.CounterService.Put is not implemented"))
+}
+
+func (UnimplementedCounterServiceHandler) Get(context.Context, *connect.Request[v1.GetRequest]) (*connect.Response[v1.GetResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("counter.v1.CounterService.Get is not implemented"))
+}
+
+func (UnimplementedCounterServiceHandler) BatchGet(context.Context, *connect.Request[v1.BatchGetRequest]) (*connect.Response[v1.BatchGetResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("counter.v1.CounterService.BatchGet is not implemented"))
+}
+
+func (UnimplementedCounterServiceHandler) Merge(context.Context, *connect.Request[v1.MergeRequest]) (*connect.Response[v1.MergeResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("counter.v1.CounterService.Merge is not implemented"))
+}
+
+func (UnimplementedCounterServiceHandler) Snapshot(context.Context, *connect.Request[v1.SnapshotRequest], *connect.ServerStream[v1.SnapshotResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("counter.v1.CounterService.Snapshot is not implemented"))
+}
+
+func (UnimplementedCounterServiceHandler) Watch(context.Context, *connect.Request[v1.WatchRequest], *connect.ServerStream[v1.WatchResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("counter.v1.CounterService.Watch is not implemented"))
+}
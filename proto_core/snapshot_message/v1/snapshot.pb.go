@@ -0,0 +1,416 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: snapshot_message/v1/snapshot.proto
+
+package snapshot_message
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CounterKind int32
+
+const (
+	CounterKind_COUNTER_KIND_UNSPECIFIED CounterKind = 0
+	CounterKind_COUNTER_KIND_INT64       CounterKind = 1
+	CounterKind_COUNTER_KIND_UINT64      CounterKind = 2
+	CounterKind_COUNTER_KIND_FLOAT64     CounterKind = 3
+)
+
+// Enum value maps for CounterKind.
+var (
+	CounterKind_name = map[int32]string{
+		0: "COUNTER_KIND_UNSPECIFIED",
+		1: "COUNTER_KIND_INT64",
+		2: "COUNTER_KIND_UINT64",
+		3: "COUNTER_KIND_FLOAT64",
+	}
+	CounterKind_value = map[string]int32{
+		"COUNTER_KIND_UNSPECIFIED": 0,
+		"COUNTER_KIND_INT64":       1,
+		"COUNTER_KIND_UINT64":      2,
+		"COUNTER_KIND_FLOAT64":     3,
+	}
+)
+
+func (x CounterKind) Enum() *CounterKind {
+	p := new(CounterKind)
+	*p = x
+	return p
+}
+
+func (x CounterKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CounterKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_snapshot_message_v1_snapshot_proto_enumTypes[0].Descriptor()
+}
+
+func (CounterKind) Type() protoreflect.EnumType {
+	return &file_snapshot_message_v1_snapshot_proto_enumTypes[0]
+}
+
+func (x CounterKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CounterKind.Descriptor instead.
+func (CounterKind) EnumDescriptor() ([]byte, []int) {
+	return file_snapshot_message_v1_snapshot_proto_rawDescGZIP(), []int{0}
+}
+
+type MergeOp int32
+
+const (
+	MergeOp_MERGE_OP_UNSPECIFIED MergeOp = 0
+	MergeOp_MERGE_OP_ADD         MergeOp = 1
+	MergeOp_MERGE_OP_MIN         MergeOp = 2
+	MergeOp_MERGE_OP_MULTIPLY    MergeOp = 3
+	MergeOp_MERGE_OP_DIVIDE      MergeOp = 4
+)
+
+// Enum value maps for MergeOp.
+var (
+	MergeOp_name = map[int32]string{
+		0: "MERGE_OP_UNSPECIFIED",
+		1: "MERGE_OP_ADD",
+		2: "MERGE_OP_MIN",
+		3: "MERGE_OP_MULTIPLY",
+		4: "MERGE_OP_DIVIDE",
+	}
+	MergeOp_value = map[string]int32{
+		"MERGE_OP_UNSPECIFIED": 0,
+		"MERGE_OP_ADD":         1,
+		"MERGE_OP_MIN":         2,
+		"MERGE_OP_MULTIPLY":    3,
+		"MERGE_OP_DIVIDE":      4,
+	}
+)
+
+func (x MergeOp) Enum() *MergeOp {
+	p := new(MergeOp)
+	*p = x
+	return p
+}
+
+func (x MergeOp) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MergeOp) Descriptor() protoreflect.EnumDescriptor {
+	return file_snapshot_message_v1_snapshot_proto_enumTypes[1].Descriptor()
+}
+
+func (MergeOp) Type() protoreflect.EnumType {
+	return &file_snapshot_message_v1_snapshot_proto_enumTypes[1]
+}
+
+func (x MergeOp) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MergeOp.Descriptor instead.
+func (MergeOp) EnumDescriptor() ([]byte, []int) {
+	return file_snapshot_message_v1_snapshot_proto_rawDescGZIP(), []int{1}
+}
+
+type MergeDefinition struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Op            MergeOp                `protobuf:"varint,1,opt,name=op,proto3,enum=snapshot_message.v1.MergeOp" json:"op,omitempty"`
+	Sources       []string               `protobuf:"bytes,2,rep,name=sources,proto3" json:"sources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MergeDefinition) Reset() {
+	*x = MergeDefinition{}
+	mi := &file_snapshot_message_v1_snapshot_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MergeDefinition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MergeDefinition) ProtoMessage() {}
+
+func (x *MergeDefinition) ProtoReflect() protoreflect.Message {
+	mi := &file_snapshot_message_v1_snapshot_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MergeDefinition.ProtoReflect.Descriptor instead.
+func (*MergeDefinition) Descriptor() ([]byte, []int) {
+	return file_snapshot_message_v1_snapshot_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MergeDefinition) GetOp() MergeOp {
+	if x != nil {
+		return x.Op
+	}
+	return MergeOp_MERGE_OP_UNSPECIFIED
+}
+
+func (x *MergeDefinition) GetSources() []string {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+type KeyRecord struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Kind  CounterKind            `protobuf:"varint,2,opt,name=kind,proto3,enum=snapshot_message.v1.CounterKind" json:"kind,omitempty"`
+	// Types that are valid to be assigned to Value:
+	//
+	//	*KeyRecord_Int64Value
+	//	*KeyRecord_Uint64Value
+	//	*KeyRecord_Float64Value
+	Value isKeyRecord_Value `protobuf_oneof:"value"`
+	// last update timestamp in unix millisecond
+	UpdatedAt int64 `protobuf:"varint,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// only set for computed key
+	Merge *MergeDefinition `protobuf:"bytes,7,opt,name=merge,proto3" json:"merge,omitempty"`
+	// key written by put, synced to storage as put instead of increment
+	Replace       bool `protobuf:"varint,8,opt,name=replace,proto3" json:"replace,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeyRecord) Reset() {
+	*x = KeyRecord{}
+	mi := &file_snapshot_message_v1_snapshot_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeyRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyRecord) ProtoMessage() {}
+
+func (x *KeyRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_snapshot_message_v1_snapshot_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyRecord.ProtoReflect.Descriptor instead.
+func (*KeyRecord) Descriptor() ([]byte, []int) {
+	return file_snapshot_message_v1_snapshot_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *KeyRecord) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *KeyRecord) GetKind() CounterKind {
+	if x != nil {
+		return x.Kind
+	}
+	return CounterKind_COUNTER_KIND_UNSPECIFIED
+}
+
+func (x *KeyRecord) GetValue() isKeyRecord_Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *KeyRecord) GetInt64Value() int64 {
+	if x != nil {
+		if x, ok := x.Value.(*KeyRecord_Int64Value); ok {
+			return x.Int64Value
+		}
+	}
+	return 0
+}
+
+func (x *KeyRecord) GetUint64Value() uint64 {
+	if x != nil {
+		if x, ok := x.Value.(*KeyRecord_Uint64Value); ok {
+			return x.Uint64Value
+		}
+	}
+	return 0
+}
+
+func (x *KeyRecord) GetFloat64Value() float64 {
+	if x != nil {
+		if x, ok := x.Value.(*KeyRecord_Float64Value); ok {
+			return x.Float64Value
+		}
+	}
+	return 0
+}
+
+func (x *KeyRecord) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+func (x *KeyRecord) GetMerge() *MergeDefinition {
+	if x != nil {
+		return x.Merge
+	}
+	return nil
+}
+
+func (x *KeyRecord) GetReplace() bool {
+	if x != nil {
+		return x.Replace
+	}
+	return false
+}
+
+type isKeyRecord_Value interface {
+	isKeyRecord_Value()
+}
+
+type KeyRecord_Int64Value struct {
+	Int64Value int64 `protobuf:"varint,3,opt,name=int64_value,json=int64Value,proto3,oneof"`
+}
+
+type KeyRecord_Uint64Value struct {
+	Uint64Value uint64 `protobuf:"varint,4,opt,name=uint64_value,json=uint64Value,proto3,oneof"`
+}
+
+type KeyRecord_Float64Value struct {
+	Float64Value float64 `protobuf:"fixed64,5,opt,name=float64_value,json=float64Value,proto3,oneof"`
+}
+
+func (*KeyRecord_Int64Value) isKeyRecord_Value() {}
+
+func (*KeyRecord_Uint64Value) isKeyRecord_Value() {}
+
+func (*KeyRecord_Float64Value) isKeyRecord_Value() {}
+
+var File_snapshot_message_v1_snapshot_proto protoreflect.FileDescriptor
+
+const file_snapshot_message_v1_snapshot_proto_rawDesc = "" +
+	"\n" +
+	"\"snapshot_message/v1/snapshot.proto\x12\x13snapshot_message.v1\"Y\n" +
+	"\x0fMergeDefinition\x12,\n" +
+	"\x02op\x18\x01 \x01(\x0e2\x1c.snapshot_message.v1.MergeOpR\x02op\x12\x18\n" +
+	"\asources\x18\x02 \x03(\tR\asources\"\xc0\x02\n" +
+	"\tKeyRecord\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x124\n" +
+	"\x04kind\x18\x02 \x01(\x0e2 .snapshot_message.v1.CounterKindR\x04kind\x12!\n" +
+	"\vint64_value\x18\x03 \x01(\x03H\x00R\n" +
+	"int64Value\x12#\n" +
+	"\fuint64_value\x18\x04 \x01(\x04H\x00R\vuint64Value\x12%\n" +
+	"\rfloat64_value\x18\x05 \x01(\x01H\x00R\ffloat64Value\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\x03R\tupdatedAt\x12:\n" +
+	"\x05merge\x18\a \x01(\v2$.snapshot_message.v1.MergeDefinitionR\x05merge\x12\x18\n" +
+	"\areplace\x18\b \x01(\bR\areplaceB\a\n" +
+	"\x05value*v\n" +
+	"\vCounterKind\x12\x1c\n" +
+	"\x18COUNTER_KIND_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12COUNTER_KIND_INT64\x10\x01\x12\x17\n" +
+	"\x13COUNTER_KIND_UINT64\x10\x02\x12\x18\n" +
+	"\x14COUNTER_KIND_FLOAT64\x10\x03*s\n" +
+	"\aMergeOp\x12\x18\n" +
+	"\x14MERGE_OP_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fMERGE_OP_ADD\x10\x01\x12\x10\n" +
+	"\fMERGE_OP_MIN\x10\x02\x12\x15\n" +
+	"\x11MERGE_OP_MULTIPLY\x10\x03\x12\x13\n" +
+	"\x0fMERGE_OP_DIVIDE\x10\x04B\xe6\x01\n" +
+	"\x17com.snapshot_message.v1B\rSnapshotProtoP\x01ZSgithub.com/wargasipil/stream_engine/proto_core/snapshot_message/v1;snapshot_message\xa2\x02\x03SXX\xaa\x02\x12SnapshotMessage.V1\xca\x02\x12SnapshotMessage\\V1\xe2\x02\x1eSnapshotMessage\\V1\\GPBMetadata\xea\x02\x13SnapshotMessage::V1b\x06proto3"
+
+var (
+	file_snapshot_message_v1_snapshot_proto_rawDescOnce sync.Once
+	file_snapshot_message_v1_snapshot_proto_rawDescData []byte
+)
+
+func file_snapshot_message_v1_snapshot_proto_rawDescGZIP() []byte {
+	file_snapshot_message_v1_snapshot_proto_rawDescOnce.Do(func() {
+		file_snapshot_message_v1_snapshot_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_snapshot_message_v1_snapshot_proto_rawDesc), len(file_snapshot_message_v1_snapshot_proto_rawDesc)))
+	})
+	return file_snapshot_message_v1_snapshot_proto_rawDescData
+}
+
+var file_snapshot_message_v1_snapshot_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_snapshot_message_v1_snapshot_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_snapshot_message_v1_snapshot_proto_goTypes = []any{
+	(CounterKind)(0),        // 0: snapshot_message.v1.CounterKind
+	(MergeOp)(0),            // 1: snapshot_message.v1.MergeOp
+	(*MergeDefinition)(nil), // 2: snapshot_message.v1.MergeDefinition
+	(*KeyRecord)(nil),       // 3: snapshot_message.v1.KeyRecord
+}
+var file_snapshot_message_v1_snapshot_proto_depIdxs = []int32{
+	1, // 0: snapshot_message.v1.MergeDefinition.op:type_name -> snapshot_message.v1.MergeOp
+	0, // 1: snapshot_message.v1.KeyRecord.kind:type_name -> snapshot_message.v1.CounterKind
+	2, // 2: snapshot_message.v1.KeyRecord.merge:type_name -> snapshot_message.v1.MergeDefinition
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_snapshot_message_v1_snapshot_proto_init() }
+func file_snapshot_message_v1_snapshot_proto_init() {
+	if File_snapshot_message_v1_snapshot_proto != nil {
+		return
+	}
+	file_snapshot_message_v1_snapshot_proto_msgTypes[1].OneofWrappers = []any{
+		(*KeyRecord_Int64Value)(nil),
+		(*KeyRecord_Uint64Value)(nil),
+		(*KeyRecord_Float64Value)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_snapshot_message_v1_snapshot_proto_rawDesc), len(file_snapshot_message_v1_snapshot_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_snapshot_message_v1_snapshot_proto_goTypes,
+		DependencyIndexes: file_snapshot_message_v1_snapshot_proto_depIdxs,
+		EnumInfos:         file_snapshot_message_v1_snapshot_proto_enumTypes,
+		MessageInfos:      file_snapshot_message_v1_snapshot_proto_msgTypes,
+	}.Build()
+	File_snapshot_message_v1_snapshot_proto = out.File
+	file_snapshot_message_v1_snapshot_proto_goTypes = nil
+	file_snapshot_message_v1_snapshot_proto_depIdxs = nil
+}
@@ -0,0 +1,481 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: wal_message/v1/batch.proto
+
+package wal_message
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SlotImage hashmap slot content before batch applied
+type SlotImage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slot          int64                  `protobuf:"varint,1,opt,name=slot,proto3" json:"slot,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SlotImage) Reset() {
+	*x = SlotImage{}
+	mi := &file_wal_message_v1_batch_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SlotImage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SlotImage) ProtoMessage() {}
+
+func (x *SlotImage) ProtoReflect() protoreflect.Message {
+	mi := &file_wal_message_v1_batch_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SlotImage.ProtoReflect.Descriptor instead.
+func (*SlotImage) Descriptor() ([]byte, []int) {
+	return file_wal_message_v1_batch_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SlotImage) GetSlot() int64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *SlotImage) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// BatchUndo written before batch applied, restored on open when batch never committed
+type BatchUndo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slots         []*SlotImage           `protobuf:"bytes,1,rep,name=slots,proto3" json:"slots,omitempty"`
+	KeyCount      uint64                 `protobuf:"varint,2,opt,name=key_count,json=keyCount,proto3" json:"key_count,omitempty"`
+	DynamicOffset int64                  `protobuf:"varint,3,opt,name=dynamic_offset,json=dynamicOffset,proto3" json:"dynamic_offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchUndo) Reset() {
+	*x = BatchUndo{}
+	mi := &file_wal_message_v1_batch_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchUndo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchUndo) ProtoMessage() {}
+
+func (x *BatchUndo) ProtoReflect() protoreflect.Message {
+	mi := &file_wal_message_v1_batch_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchUndo.ProtoReflect.Descriptor instead.
+func (*BatchUndo) Descriptor() ([]byte, []int) {
+	return file_wal_message_v1_batch_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BatchUndo) GetSlots() []*SlotImage {
+	if x != nil {
+		return x.Slots
+	}
+	return nil
+}
+
+func (x *BatchUndo) GetKeyCount() uint64 {
+	if x != nil {
+		return x.KeyCount
+	}
+	return 0
+}
+
+func (x *BatchUndo) GetDynamicOffset() int64 {
+	if x != nil {
+		return x.DynamicOffset
+	}
+	return 0
+}
+
+// BatchCommit written after batch flushed, carry source offset updated by batch
+type BatchCommit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Offsets       map[string]int64       `protobuf:"bytes,1,rep,name=offsets,proto3" json:"offsets,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCommit) Reset() {
+	*x = BatchCommit{}
+	mi := &file_wal_message_v1_batch_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCommit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCommit) ProtoMessage() {}
+
+func (x *BatchCommit) ProtoReflect() protoreflect.Message {
+	mi := &file_wal_message_v1_batch_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCommit.ProtoReflect.Descriptor instead.
+func (*BatchCommit) Descriptor() ([]byte, []int) {
+	return file_wal_message_v1_batch_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchCommit) GetOffsets() map[string]int64 {
+	if x != nil {
+		return x.Offsets
+	}
+	return nil
+}
+
+// BatchAbort written after failed batch rolled back
+type BatchAbort struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchAbort) Reset() {
+	*x = BatchAbort{}
+	mi := &file_wal_message_v1_batch_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchAbort) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchAbort) ProtoMessage() {}
+
+func (x *BatchAbort) ProtoReflect() protoreflect.Message {
+	mi := &file_wal_message_v1_batch_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchAbort.ProtoReflect.Descriptor instead.
+func (*BatchAbort) Descriptor() ([]byte, []int) {
+	return file_wal_message_v1_batch_proto_rawDescGZIP(), []int{3}
+}
+
+// Checkpoint first record of wal after truncated, carry all source offset
+type Checkpoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Offsets       map[string]int64       `protobuf:"bytes,1,rep,name=offsets,proto3" json:"offsets,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Checkpoint) Reset() {
+	*x = Checkpoint{}
+	mi := &file_wal_message_v1_batch_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Checkpoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Checkpoint) ProtoMessage() {}
+
+func (x *Checkpoint) ProtoReflect() protoreflect.Message {
+	mi := &file_wal_message_v1_batch_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Checkpoint.ProtoReflect.Descriptor instead.
+func (*Checkpoint) Descriptor() ([]byte, []int) {
+	return file_wal_message_v1_batch_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Checkpoint) GetOffsets() map[string]int64 {
+	if x != nil {
+		return x.Offsets
+	}
+	return nil
+}
+
+type BatchRecord struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Record:
+	//
+	//	*BatchRecord_Undo
+	//	*BatchRecord_Commit
+	//	*BatchRecord_Abort
+	//	*BatchRecord_Checkpoint
+	Record        isBatchRecord_Record `protobuf_oneof:"record"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchRecord) Reset() {
+	*x = BatchRecord{}
+	mi := &file_wal_message_v1_batch_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchRecord) ProtoMessage() {}
+
+func (x *BatchRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_wal_message_v1_batch_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchRecord.ProtoReflect.Descriptor instead.
+func (*BatchRecord) Descriptor() ([]byte, []int) {
+	return file_wal_message_v1_batch_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchRecord) GetRecord() isBatchRecord_Record {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+func (x *BatchRecord) GetUndo() *BatchUndo {
+	if x != nil {
+		if x, ok := x.Record.(*BatchRecord_Undo); ok {
+			return x.Undo
+		}
+	}
+	return nil
+}
+
+func (x *BatchRecord) GetCommit() *BatchCommit {
+	if x != nil {
+		if x, ok := x.Record.(*BatchRecord_Commit); ok {
+			return x.Commit
+		}
+	}
+	return nil
+}
+
+func (x *BatchRecord) GetAbort() *BatchAbort {
+	if x != nil {
+		if x, ok := x.Record.(*BatchRecord_Abort); ok {
+			return x.Abort
+		}
+	}
+	return nil
+}
+
+func (x *BatchRecord) GetCheckpoint() *Checkpoint {
+	if x != nil {
+		if x, ok := x.Record.(*BatchRecord_Checkpoint); ok {
+			return x.Checkpoint
+		}
+	}
+	return nil
+}
+
+type isBatchRecord_Record interface {
+	isBatchRecord_Record()
+}
+
+type BatchRecord_Undo struct {
+	Undo *BatchUndo `protobuf:"bytes,1,opt,name=undo,proto3,oneof"`
+}
+
+type BatchRecord_Commit struct {
+	Commit *BatchCommit `protobuf:"bytes,2,opt,name=commit,proto3,oneof"`
+}
+
+type BatchRecord_Abort struct {
+	Abort *BatchAbort `protobuf:"bytes,3,opt,name=abort,proto3,oneof"`
+}
+
+type BatchRecord_Checkpoint struct {
+	Checkpoint *Checkpoint `protobuf:"bytes,4,opt,name=checkpoint,proto3,oneof"`
+}
+
+func (*BatchRecord_Undo) isBatchRecord_Record() {}
+
+func (*BatchRecord_Commit) isBatchRecord_Record() {}
+
+func (*BatchRecord_Abort) isBatchRecord_Record() {}
+
+func (*BatchRecord_Checkpoint) isBatchRecord_Record() {}
+
+var File_wal_message_v1_batch_proto protoreflect.FileDescriptor
+
+const file_wal_message_v1_batch_proto_rawDesc = "" +
+	"\n" +
+	"\x1awal_message/v1/batch.proto\x12\x0ewal_message.v1\"3\n" +
+	"\tSlotImage\x12\x12\n" +
+	"\x04slot\x18\x01 \x01(\x03R\x04slot\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"\x80\x01\n" +
+	"\tBatchUndo\x12/\n" +
+	"\x05slots\x18\x01 \x03(\v2\x19.wal_message.v1.SlotImageR\x05slots\x12\x1b\n" +
+	"\tkey_count\x18\x02 \x01(\x04R\bkeyCount\x12%\n" +
+	"\x0edynamic_offset\x18\x03 \x01(\x03R\rdynamicOffset\"\x8d\x01\n" +
+	"\vBatchCommit\x12B\n" +
+	"\aoffsets\x18\x01 \x03(\v2(.wal_message.v1.BatchCommit.OffsetsEntryR\aoffsets\x1a:\n" +
+	"\fOffsetsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\f\n" +
+	"\n" +
+	"BatchAbort\"\x8b\x01\n" +
+	"\n" +
+	"Checkpoint\x12A\n" +
+	"\aoffsets\x18\x01 \x03(\v2'.wal_message.v1.Checkpoint.OffsetsEntryR\aoffsets\x1a:\n" +
+	"\fOffsetsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\xf1\x01\n" +
+	"\vBatchRecord\x12/\n" +
+	"\x04undo\x18\x01 \x01(\v2\x19.wal_message.v1.BatchUndoH\x00R\x04undo\x125\n" +
+	"\x06commit\x18\x02 \x01(\v2\x1b.wal_message.v1.BatchCommitH\x00R\x06commit\x122\n" +
+	"\x05abort\x18\x03 \x01(\v2\x1a.wal_message.v1.BatchAbortH\x00R\x05abort\x12<\n" +
+	"\n" +
+	"checkpoint\x18\x04 \x01(\v2\x1a.wal_message.v1.CheckpointH\x00R\n" +
+	"checkpointB\b\n" +
+	"\x06recordB\xc0\x01\n" +
+	"\x12com.wal_message.v1B\n" +
+	"BatchProtoP\x01ZIgithub.com/wargasipil/stream_engine/proto_core/wal_message/v1;wal_message\xa2\x02\x03WXX\xaa\x02\rWalMessage.V1\xca\x02\rWalMessage\\V1\xe2\x02\x19WalMessage\\V1\\GPBMetadata\xea\x02\x0eWalMessage::V1b\x06proto3"
+
+var (
+	file_wal_message_v1_batch_proto_rawDescOnce sync.Once
+	file_wal_message_v1_batch_proto_rawDescData []byte
+)
+
+func file_wal_message_v1_batch_proto_rawDescGZIP() []byte {
+	file_wal_message_v1_batch_proto_rawDescOnce.Do(func() {
+		file_wal_message_v1_batch_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_wal_message_v1_batch_proto_rawDesc), len(file_wal_message_v1_batch_proto_rawDesc)))
+	})
+	return file_wal_message_v1_batch_proto_rawDescData
+}
+
+var file_wal_message_v1_batch_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_wal_message_v1_batch_proto_goTypes = []any{
+	(*SlotImage)(nil),   // 0: wal_message.v1.SlotImage
+	(*BatchUndo)(nil),   // 1: wal_message.v1.BatchUndo
+	(*BatchCommit)(nil), // 2: wal_message.v1.BatchCommit
+	(*BatchAbort)(nil),  // 3: wal_message.v1.BatchAbort
+	(*Checkpoint)(nil),  // 4: wal_message.v1.Checkpoint
+	(*BatchRecord)(nil), // 5: wal_message.v1.BatchRecord
+	nil,                 // 6: wal_message.v1.BatchCommit.OffsetsEntry
+	nil,                 // 7: wal_message.v1.Checkpoint.OffsetsEntry
+}
+var file_wal_message_v1_batch_proto_depIdxs = []int32{
+	0, // 0: wal_message.v1.BatchUndo.slots:type_name -> wal_message.v1.SlotImage
+	6, // 1: wal_message.v1.BatchCommit.offsets:type_name -> wal_message.v1.BatchCommit.OffsetsEntry
+	7, // 2: wal_message.v1.Checkpoint.offsets:type_name -> wal_message.v1.Checkpoint.OffsetsEntry
+	1, // 3: wal_message.v1.BatchRecord.undo:type_name -> wal_message.v1.BatchUndo
+	2, // 4: wal_message.v1.BatchRecord.commit:type_name -> wal_message.v1.BatchCommit
+	3, // 5: wal_message.v1.BatchRecord.abort:type_name -> wal_message.v1.BatchAbort
+	4, // 6: wal_message.v1.BatchRecord.checkpoint:type_name -> wal_message.v1.Checkpoint
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_wal_message_v1_batch_proto_init() }
+func file_wal_message_v1_batch_proto_init() {
+	if File_wal_message_v1_batch_proto != nil {
+		return
+	}
+	file_wal_message_v1_batch_proto_msgTypes[5].OneofWrappers = []any{
+		(*BatchRecord_Undo)(nil),
+		(*BatchRecord_Commit)(nil),
+		(*BatchRecord_Abort)(nil),
+		(*BatchRecord_Checkpoint)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_wal_message_v1_batch_proto_rawDesc), len(file_wal_message_v1_batch_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_wal_message_v1_batch_proto_goTypes,
+		DependencyIndexes: file_wal_message_v1_batch_proto_depIdxs,
+		MessageInfos:      file_wal_message_v1_batch_proto_msgTypes,
+	}.Build()
+	File_wal_message_v1_batch_proto = out.File
+	file_wal_message_v1_batch_proto_goTypes = nil
+	file_wal_message_v1_batch_proto_depIdxs = nil
+}
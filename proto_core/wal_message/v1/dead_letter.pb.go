@@ -0,0 +1,279 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: wal_message/v1/dead_letter.proto
+
+package wal_message
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DeadLetterOp int32
+
+const (
+	DeadLetterOp_DEAD_LETTER_OP_UNSPECIFIED DeadLetterOp = 0
+	DeadLetterOp_DEAD_LETTER_OP_INCREMENT   DeadLetterOp = 1
+	DeadLetterOp_DEAD_LETTER_OP_PUT         DeadLetterOp = 2
+)
+
+// Enum value maps for DeadLetterOp.
+var (
+	DeadLetterOp_name = map[int32]string{
+		0: "DEAD_LETTER_OP_UNSPECIFIED",
+		1: "DEAD_LETTER_OP_INCREMENT",
+		2: "DEAD_LETTER_OP_PUT",
+	}
+	DeadLetterOp_value = map[string]int32{
+		"DEAD_LETTER_OP_UNSPECIFIED": 0,
+		"DEAD_LETTER_OP_INCREMENT":   1,
+		"DEAD_LETTER_OP_PUT":         2,
+	}
+)
+
+func (x DeadLetterOp) Enum() *DeadLetterOp {
+	p := new(DeadLetterOp)
+	*p = x
+	return p
+}
+
+func (x DeadLetterOp) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DeadLetterOp) Descriptor() protoreflect.EnumDescriptor {
+	return file_wal_message_v1_dead_letter_proto_enumTypes[0].Descriptor()
+}
+
+func (DeadLetterOp) Type() protoreflect.EnumType {
+	return &file_wal_message_v1_dead_letter_proto_enumTypes[0]
+}
+
+func (x DeadLetterOp) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DeadLetterOp.Descriptor instead.
+func (DeadLetterOp) EnumDescriptor() ([]byte, []int) {
+	return file_wal_message_v1_dead_letter_proto_rawDescGZIP(), []int{0}
+}
+
+// DeadLetter mutation not delivered to key storage
+type DeadLetter struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Op    DeadLetterOp           `protobuf:"varint,1,opt,name=op,proto3,enum=wal_message.v1.DeadLetterOp" json:"op,omitempty"`
+	Path  string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Field string                 `protobuf:"bytes,3,opt,name=field,proto3" json:"field,omitempty"`
+	// value of int64 kind
+	Value int64 `protobuf:"varint,4,opt,name=value,proto3" json:"value,omitempty"`
+	// unix millisecond
+	FailedAt int64  `protobuf:"varint,5,opt,name=failed_at,json=failedAt,proto3" json:"failed_at,omitempty"`
+	Attempt  uint32 `protobuf:"varint,6,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	Error    string `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	// reflect.Kind of value, zero is int64
+	Kind       uint32  `protobuf:"varint,8,opt,name=kind,proto3" json:"kind,omitempty"`
+	UintValue  uint64  `protobuf:"varint,9,opt,name=uint_value,json=uintValue,proto3" json:"uint_value,omitempty"`
+	FloatValue float64 `protobuf:"fixed64,10,opt,name=float_value,json=floatValue,proto3" json:"float_value,omitempty"`
+	// uint_value is decrease of uint64 delta
+	Negative      bool `protobuf:"varint,11,opt,name=negative,proto3" json:"negative,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeadLetter) Reset() {
+	*x = DeadLetter{}
+	mi := &file_wal_message_v1_dead_letter_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeadLetter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeadLetter) ProtoMessage() {}
+
+func (x *DeadLetter) ProtoReflect() protoreflect.Message {
+	mi := &file_wal_message_v1_dead_letter_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeadLetter.ProtoReflect.Descriptor instead.
+func (*DeadLetter) Descriptor() ([]byte, []int) {
+	return file_wal_message_v1_dead_letter_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DeadLetter) GetOp() DeadLetterOp {
+	if x != nil {
+		return x.Op
+	}
+	return DeadLetterOp_DEAD_LETTER_OP_UNSPECIFIED
+}
+
+func (x *DeadLetter) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *DeadLetter) GetFailedAt() int64 {
+	if x != nil {
+		return x.FailedAt
+	}
+	return 0
+}
+
+func (x *DeadLetter) GetAttempt() uint32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *DeadLetter) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetKind() uint32 {
+	if x != nil {
+		return x.Kind
+	}
+	return 0
+}
+
+func (x *DeadLetter) GetUintValue() uint64 {
+	if x != nil {
+		return x.UintValue
+	}
+	return 0
+}
+
+func (x *DeadLetter) GetFloatValue() float64 {
+	if x != nil {
+		return x.FloatValue
+	}
+	return 0
+}
+
+func (x *DeadLetter) GetNegative() bool {
+	if x != nil {
+		return x.Negative
+	}
+	return false
+}
+
+var File_wal_message_v1_dead_letter_proto protoreflect.FileDescriptor
+
+const file_wal_message_v1_dead_letter_proto_rawDesc = "" +
+	"\n" +
+	" wal_message/v1/dead_letter.proto\x12\x0ewal_message.v1\"\xb7\x02\n" +
+	"\n" +
+	"DeadLetter\x12,\n" +
+	"\x02op\x18\x01 \x01(\x0e2\x1c.wal_message.v1.DeadLetterOpR\x02op\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x14\n" +
+	"\x05field\x18\x03 \x01(\tR\x05field\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\x03R\x05value\x12\x1b\n" +
+	"\tfailed_at\x18\x05 \x01(\x03R\bfailedAt\x12\x18\n" +
+	"\aattempt\x18\x06 \x01(\rR\aattempt\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\x12\x12\n" +
+	"\x04kind\x18\b \x01(\rR\x04kind\x12\x1d\n" +
+	"\n" +
+	"uint_value\x18\t \x01(\x04R\tuintValue\x12\x1f\n" +
+	"\vfloat_value\x18\n" +
+	" \x01(\x01R\n" +
+	"floatValue\x12\x1a\n" +
+	"\bnegative\x18\v \x01(\bR\bnegative*d\n" +
+	"\fDeadLetterOp\x12\x1e\n" +
+	"\x1aDEAD_LETTER_OP_UNSPECIFIED\x10\x00\x12\x1c\n" +
+	"\x18DEAD_LETTER_OP_INCREMENT\x10\x01\x12\x16\n" +
+	"\x12DEAD_LETTER_OP_PUT\x10\x02B\xc5\x01\n" +
+	"\x12com.wal_message.v1B\x0fDeadLetterProtoP\x01ZIgithub.com/wargasipil/stream_engine/proto_core/wal_message/v1;wal_message\xa2\x02\x03WXX\xaa\x02\rWalMessage.V1\xca\x02\rWalMessage\\V1\xe2\x02\x19WalMessage\\V1\\GPBMetadata\xea\x02\x0eWalMessage::V1b\x06proto3"
+
+var (
+	file_wal_message_v1_dead_letter_proto_rawDescOnce sync.Once
+	file_wal_message_v1_dead_letter_proto_rawDescData []byte
+)
+
+func file_wal_message_v1_dead_letter_proto_rawDescGZIP() []byte {
+	file_wal_message_v1_dead_letter_proto_rawDescOnce.Do(func() {
+		file_wal_message_v1_dead_letter_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_wal_message_v1_dead_letter_proto_rawDesc), len(file_wal_message_v1_dead_letter_proto_rawDesc)))
+	})
+	return file_wal_message_v1_dead_letter_proto_rawDescData
+}
+
+var file_wal_message_v1_dead_letter_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_wal_message_v1_dead_letter_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_wal_message_v1_dead_letter_proto_goTypes = []any{
+	(DeadLetterOp)(0),  // 0: wal_message.v1.DeadLetterOp
+	(*DeadLetter)(nil), // 1: wal_message.v1.DeadLetter
+}
+var file_wal_message_v1_dead_letter_proto_depIdxs = []int32{
+	0, // 0: wal_message.v1.DeadLetter.op:type_name -> wal_message.v1.DeadLetterOp
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_wal_message_v1_dead_letter_proto_init() }
+func file_wal_message_v1_dead_letter_proto_init() {
+	if File_wal_message_v1_dead_letter_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_wal_message_v1_dead_letter_proto_rawDesc), len(file_wal_message_v1_dead_letter_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_wal_message_v1_dead_letter_proto_goTypes,
+		DependencyIndexes: file_wal_message_v1_dead_letter_proto_depIdxs,
+		EnumInfos:         file_wal_message_v1_dead_letter_proto_enumTypes,
+		MessageInfos:      file_wal_message_v1_dead_letter_proto_msgTypes,
+	}.Build()
+	File_wal_message_v1_dead_letter_proto = out.File
+	file_wal_message_v1_dead_letter_proto_goTypes = nil
+	file_wal_message_v1_dead_letter_proto_depIdxs = nil
+}
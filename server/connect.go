@@ -0,0 +1,324 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	counter "github.com/wargasipil/stream_engine/proto_core/counter/v1"
+	"github.com/wargasipil/stream_engine/proto_core/counter/v1/counterconnect"
+	"github.com/wargasipil/stream_engine/proto_core/snapshot_message/v1"
+	"github.com/wargasipil/stream_engine/snapshot"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+// MaxBatchGetKeys max key in one batch get request
+const MaxBatchGetKeys = 10_000
+
+// CounterService counter.v1.CounterService over hashmap counter
+type CounterService struct {
+	hm *stream_core.HashMapCounter
+}
+
+var _ counterconnect.CounterServiceHandler = (*CounterService)(nil)
+
+func NewCounterService(hm *stream_core.HashMapCounter) *CounterService {
+	return &CounterService{hm}
+}
+
+// NewConnectHandler connect, grpc and grpc-web handler of counter service, return path to mount handler
+func NewConnectHandler(hm *stream_core.HashMapCounter, opts ...connect.HandlerOption) (string, http.Handler) {
+	return counterconnect.NewCounterServiceHandler(NewCounterService(hm), opts...)
+}
+
+// Increment implements counterconnect.CounterServiceHandler.
+func (s *CounterService) Increment(ctx context.Context, req *connect.Request[counter.IncrementRequest]) (*connect.Response[counter.IncrementResponse], error) {
+	msg := req.Msg
+	delta, err := ValueFromMessage(msg.Delta)
+	if err != nil {
+		return nil, invalidArgument(msg.Key, err)
+	}
+	if msg.Key == "" {
+		return nil, invalidArgument(msg.Key, errors.New("key empty"))
+	}
+
+	if msg.EventId != "" {
+		applied, err := s.hm.IncWithID(msg.EventId, msg.Key, delta)
+		if err != nil {
+			return nil, connectError(err)
+		}
+		rec, _, err := s.hm.GetRecord(msg.Key)
+		if err != nil {
+			return nil, connectError(err)
+		}
+		return connect.NewResponse(&counter.IncrementResponse{
+			Record:  snapshot.RecordMessage(rec),
+			Applied: applied,
+		}), nil
+	}
+
+	value, err := s.hm.Inc(msg.Key, delta)
+	if err != nil {
+		return nil, connectError(err)
+	}
+	return connect.NewResponse(&counter.IncrementResponse{
+		Record:  updatedRecord(msg.Key, value),
+		Applied: true,
+	}), nil
+}
+
+// Put implements counterconnect.CounterServiceHandler.
+func (s *CounterService) Put(ctx context.Context, req *connect.Request[counter.PutRequest]) (*connect.Response[counter.PutResponse], error) {
+	msg := req.Msg
+	value, err := ValueFromMessage(msg.Value)
+	if err != nil {
+		return nil, invalidArgument(msg.Key, err)
+	}
+	if msg.Key == "" {
+		return nil, invalidArgument(msg.Key, errors.New("key empty"))
+	}
+
+	value, err = s.hm.Put(msg.Key, value)
+	if err != nil {
+		return nil, connectError(err)
+	}
+	return connect.NewResponse(&counter.PutResponse{
+		Record: updatedRecord(msg.Key, value),
+	}), nil
+}
+
+// Get implements counterconnect.CounterServiceHandler.
+func (s *CounterService) Get(ctx context.Context, req *connect.Request[counter.GetRequest]) (*connect.Response[counter.GetResponse], error) {
+	rec, ok, err := s.hm.GetRecord(req.Msg.Key)
+	if err != nil {
+		return nil, connectError(err)
+	}
+	if !ok {
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("%s not found", req.Msg.Key))
+	}
+	return connect.NewResponse(&counter.GetResponse{
+		Record: snapshot.RecordMessage(rec),
+	}), nil
+}
+
+// BatchGet implements counterconnect.CounterServiceHandler.
+func (s *CounterService) BatchGet(ctx context.Context, req *connect.Request[counter.BatchGetRequest]) (*connect.Response[counter.BatchGetResponse], error) {
+	keys := req.Msg.Keys
+	if len(keys) > MaxBatchGetKeys {
+		return nil, invalidArgument("", fmt.Errorf("batch get have %d key, max %d", len(keys), MaxBatchGetKeys))
+	}
+
+	res := &counter.BatchGetResponse{}
+	for _, key := range keys {
+		rec, ok, err := s.hm.GetRecord(key)
+		if err != nil {
+			return nil, connectError(err)
+		}
+		if !ok {
+			res.Missing = append(res.Missing, key)
+			continue
+		}
+		res.Records = append(res.Records, snapshot.RecordMessage(rec))
+	}
+	return connect.NewResponse(res), nil
+}
+
+// Merge implements counterconnect.CounterServiceHandler.
+func (s *CounterService) Merge(ctx context.Context, req *connect.Request[counter.MergeRequest]) (*connect.Response[counter.MergeResponse], error) {
+	msg := req.Msg
+	kind := snapshot.KindFromProto(msg.Kind)
+	if kind == reflect.Invalid {
+		return nil, invalidArgument(msg.Key, errors.New("kind unspecified"))
+	}
+	if msg.Op == snapshot_message.MergeOp_MERGE_OP_UNSPECIFIED {
+		return nil, invalidArgument(msg.Key, errors.New("merge op unspecified"))
+	}
+	if msg.Op < snapshot_message.MergeOp_MERGE_OP_UNSPECIFIED || msg.Op > snapshot_message.MergeOp_MERGE_OP_DIVIDE {
+		return nil, invalidArgument(msg.Key, fmt.Errorf("merge op %d not supported", msg.Op))
+	}
+	if msg.Key == "" {
+		return nil, invalidArgument(msg.Key, errors.New("key empty"))
+	}
+
+	op := stream_core.MergeOps(msg.Op - 1)
+	value, err := s.hm.Merge(op, kind, msg.Key, msg.Sources...)
+	if err != nil {
+		// merge source invalid or definition changed
+		return nil, connect.NewError(connect.CodeFailedPrecondition, err)
+	}
+
+	rec := updatedRecord(msg.Key, value)
+	rec.Merge = &snapshot_message.MergeDefinition{
+		Op:      msg.Op,
+		Sources: msg.Sources,
+	}
+	return connect.NewResponse(&counter.MergeResponse{Record: rec}), nil
+}
+
+// Snapshot implements counterconnect.CounterServiceHandler.
+func (s *CounterService) Snapshot(ctx context.Context, req *connect.Request[counter.SnapshotRequest], stream *connect.ServerStream[counter.SnapshotResponse]) error {
+	var since time.Time
+	if req.Msg.Since > 0 {
+		since = time.UnixMilli(req.Msg.Since)
+	}
+
+	view := s.hm.OpenSnapshot()
+	defer view.Close()
+
+	batch := &counter.SnapshotResponse{}
+	err := view.Iterate(since, func(rec *stream_core.KeyRecord) error {
+		if !strings.HasPrefix(rec.Key, req.Msg.Prefix) {
+			return nil
+		}
+
+		batch.Records = append(batch.Records, snapshot.RecordMessage(rec))
+		if len(batch.Records) < stream_core.SnapshotBatchSize {
+			return nil
+		}
+
+		err := stream.Send(batch)
+		batch = &counter.SnapshotResponse{}
+		return err
+	})
+	if err != nil {
+		return connectError(err)
+	}
+
+	if len(batch.Records) == 0 {
+		return nil
+	}
+	return stream.Send(batch)
+}
+
+// Watch implements counterconnect.CounterServiceHandler.
+func (s *CounterService) Watch(ctx context.Context, req *connect.Request[counter.WatchRequest], stream *connect.ServerStream[counter.WatchResponse]) error {
+	// disconnect slow client instead of blocking writer or silently dropping change
+	ch := s.hm.SubscribeWithOption(req.Msg.Prefix, &stream_core.SubscribeOption{
+		Buffer: int(req.Msg.Buffer),
+		Policy: stream_core.SlowConsumerDisconnect,
+	})
+	defer s.hm.Unsubscribe(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case change, ok := <-ch:
+			if !ok {
+				return connect.NewError(connect.CodeAborted, errors.New("watch closed, client too slow or counter closed"))
+			}
+
+			res := &counter.WatchResponse{
+				Seq:       change.Seq,
+				Key:       change.Key,
+				Kind:      snapshot.ProtoKind(change.Kind),
+				New:       ValueMessage(change.New),
+				Timestamp: change.Timestamp.UnixMilli(),
+			}
+			if change.Old != nil {
+				res.Old = ValueMessage(change.Old)
+			}
+
+			err := stream.Send(res)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// updatedRecord record of value returned by update
+func updatedRecord(key string, value any) *snapshot_message.KeyRecord {
+	return snapshot.RecordMessage(&stream_core.KeyRecord{
+		Key:       key,
+		Kind:      reflect.ValueOf(value).Kind(),
+		Value:     value,
+		UpdatedAt: time.Now(),
+	})
+}
+
+// ValueMessage convert counter value of int64, uint64 or float64 to counter.Value
+func ValueMessage(value any) *counter.Value {
+	switch val := value.(type) {
+	case int64:
+		return &counter.Value{Value: &counter.Value_Int64Value{Int64Value: val}}
+	case uint64:
+		return &counter.Value{Value: &counter.Value_Uint64Value{Uint64Value: val}}
+	case float64:
+		return &counter.Value{Value: &counter.Value_Float64Value{Float64Value: val}}
+	default:
+		return &counter.Value{}
+	}
+}
+
+// ValueFromMessage convert counter.Value to counter value, error when value not set
+func ValueFromMessage(msg *counter.Value) (any, error) {
+	switch val := msg.GetValue().(type) {
+	case *counter.Value_Int64Value:
+		return val.Int64Value, nil
+	case *counter.Value_Uint64Value:
+		return val.Uint64Value, nil
+	case *counter.Value_Float64Value:
+		return val.Float64Value, nil
+	default:
+		return nil, errors.New("value empty")
+	}
+}
+
+// KindMismatchFromError get kind mismatch from detail of connect error, nil when error not kind mismatch
+func KindMismatchFromError(err error) *stream_core.KindMismatchError {
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) {
+		return nil
+	}
+
+	for _, detail := range connectErr.Details() {
+		value, err := detail.Value()
+		if err != nil {
+			continue
+		}
+		mismatch, ok := value.(*counter.KindMismatch)
+		if !ok {
+			continue
+		}
+		return &stream_core.KindMismatchError{
+			Key:      mismatch.Key,
+			Kind:     snapshot.KindFromProto(mismatch.Kind),
+			Existing: snapshot.KindFromProto(mismatch.ExistingKind),
+		}
+	}
+	return nil
+}
+
+func invalidArgument(key string, err error) *connect.Error {
+	if key != "" {
+		err = fmt.Errorf("%s: %w", key, err)
+	}
+	return connect.NewError(connect.CodeInvalidArgument, err)
+}
+
+func connectError(err error) *connect.Error {
+	var mismatch *stream_core.KindMismatchError
+	if errors.As(err, &mismatch) {
+		connectErr := connect.NewError(connect.CodeFailedPrecondition, err)
+		detail, detailErr := connect.NewErrorDetail(&counter.KindMismatch{
+			Key:          mismatch.Key,
+			Kind:         snapshot.ProtoKind(mismatch.Kind),
+			ExistingKind: snapshot.ProtoKind(mismatch.Existing),
+		})
+		if detailErr == nil {
+			connectErr.AddDetail(detail)
+		}
+		return connectErr
+	}
+
+	if errors.Is(err, stream_core.ErrDedupeDisabled) {
+		return connect.NewError(connect.CodeFailedPrecondition, err)
+	}
+	return connect.NewError(connect.CodeInternal, err)
+}
@@ -0,0 +1,183 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/client"
+	"github.com/wargasipil/stream_engine/server"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func TestCounterService(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/server_connect_unittest",
+		HashMapCounterSlots: 1 << 20,
+		DynamicValuePath:    "/tmp/stream_engine/server_connect_value_unittest",
+		DedupePath:          "/tmp/stream_engine/server_connect_dedupe_unittest",
+		DedupeExpectedIDs:   1000,
+		DedupeWindow:        1 << 10,
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+	os.Remove(cfg.DedupePath)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer kv.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle(server.NewConnectHandler(kv))
+	ts := httptest.NewUnstartedServer(mux)
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	ctx := context.Background()
+	clients := map[string]*client.Client{
+		"connect": client.New(ts.Client(), ts.URL),
+		"grpc":    client.New(ts.Client(), ts.URL, connect.WithGRPC()),
+	}
+
+	for name, c := range clients {
+		t.Run("testing inc put and get with "+name, func(t *testing.T) {
+			key := "users/" + name + "/order_count"
+			value, err := c.Inc(ctx, key, int64(2))
+			assert.Nil(t, err)
+			assert.Equal(t, int64(2), value)
+
+			value, err = c.Inc(ctx, key, int64(3))
+			assert.Nil(t, err)
+			assert.Equal(t, int64(5), value)
+
+			value, err = c.Put(ctx, "users/"+name+"/balance", float64(10.5))
+			assert.Nil(t, err)
+			assert.Equal(t, float64(10.5), value)
+
+			rec, ok, err := c.Get(ctx, key)
+			assert.Nil(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, reflect.Int64, rec.Kind)
+			assert.Equal(t, int64(5), rec.Value)
+			assert.Equal(t, int64(5), kv.GetInt64(key))
+
+			_, ok, err = c.Get(ctx, "users/"+name+"/missing")
+			assert.Nil(t, err)
+			assert.False(t, ok)
+		})
+	}
+
+	c := clients["connect"]
+
+	t.Run("testing kind mismatch", func(t *testing.T) {
+		_, err := c.Inc(ctx, "users/connect/order_count", float64(1))
+		assert.ErrorIs(t, err, stream_core.ErrKindMismatch)
+		assert.Equal(t, &stream_core.KindMismatchError{
+			Key:      "users/connect/order_count",
+			Kind:     reflect.Float64,
+			Existing: reflect.Int64,
+		}, err)
+
+		_, err = c.Inc(ctx, "users/connect/order_count", nil)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+
+	t.Run("testing inc with event id", func(t *testing.T) {
+		applied, err := c.IncWithID(ctx, "trx-1", "users/dedupe/order_count", uint64(1))
+		assert.Nil(t, err)
+		assert.True(t, applied)
+
+		applied, err = c.IncWithID(ctx, "trx-1", "users/dedupe/order_count", uint64(1))
+		assert.Nil(t, err)
+		assert.False(t, applied)
+		assert.Equal(t, uint64(1), kv.GetUint64("users/dedupe/order_count"))
+	})
+
+	t.Run("testing batch get and merge", func(t *testing.T) {
+		value, err := c.Merge(ctx, stream_core.MergeOpAdd, reflect.Int64, "users/total_order", "users/connect/order_count", "users/grpc/order_count")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(10), value)
+
+		records, err := c.BatchGet(ctx, "users/connect/order_count", "users/total_order", "users/missing")
+		assert.Nil(t, err)
+		assert.Len(t, records, 2)
+		assert.Equal(t, int64(10), records["users/total_order"].Value)
+		assert.Equal(t, &stream_core.MergeDefinition{
+			Op:      stream_core.MergeOpAdd,
+			Sources: []string{"users/connect/order_count", "users/grpc/order_count"},
+		}, records["users/total_order"].Merge)
+
+		_, err = c.Merge(ctx, stream_core.MergeOps(9), reflect.Int64, "users/unknown_op", "users/connect/order_count")
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+
+		_, err = c.Merge(ctx, stream_core.MergeOpAdd, reflect.Int64, "users/unknown_source", "users/connect/order_count", "users/missing")
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+		_, ok, err := c.Get(ctx, "users/unknown_source")
+		assert.Nil(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("testing snapshot", func(t *testing.T) {
+		for i := range 1200 {
+			kv.IncInt64(fmt.Sprintf("snapshot/%04d", i), 1)
+		}
+
+		count := 0
+		err := c.Snapshot(ctx, "snapshot/", time.Time{}, func(rec *stream_core.KeyRecord) error {
+			count++
+			assert.Equal(t, int64(1), rec.Value)
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 1200, count)
+	})
+
+	t.Run("testing watch", func(t *testing.T) {
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		changes := make(chan *stream_core.Change, 10)
+		done := make(chan error, 1)
+		go func() {
+			done <- c.Watch(watchCtx, "watch/", 16, func(change *stream_core.Change) error {
+				changes <- change
+				return nil
+			})
+		}()
+
+		// wait watch subscribed
+		assert.Eventually(t, func() bool {
+			kv.IncInt64("watch/ping", 1)
+			select {
+			case <-changes:
+				return true
+			default:
+				return false
+			}
+		}, 5*time.Second, 10*time.Millisecond)
+
+		kv.IncInt64("other/key", 1)
+		kv.PutFloat64("watch/created", 1.5)
+
+		change := <-changes
+		for change.Key == "watch/ping" {
+			change = <-changes
+		}
+		assert.Equal(t, "watch/created", change.Key)
+		assert.Equal(t, reflect.Float64, change.Kind)
+		assert.Nil(t, change.Old)
+		assert.Equal(t, float64(1.5), change.New)
+
+		cancel()
+		assert.Nil(t, <-done)
+	})
+}
@@ -0,0 +1,290 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+// MaxBatchOps max op in one batch request
+const MaxBatchOps = 10_000
+
+const (
+	ErrorCodeInvalidRequest = "invalid_request"
+	ErrorCodeKindMismatch   = "kind_mismatch"
+	ErrorCodeDedupeDisabled = "dedupe_disabled"
+	ErrorCodeInternal       = "internal"
+)
+
+// CounterRequest body of inc and put, kind default int64 for integer value and float64 otherwise
+type CounterRequest struct {
+	Key   string      `json:"key"`
+	Kind  string      `json:"kind,omitempty"`
+	Value json.Number `json:"value"`
+	// EventID drop inc already applied with same id, need counter dedupe configured
+	EventID string `json:"event_id,omitempty"`
+}
+
+type BatchOp struct {
+	// Op inc or put
+	Op string `json:"op"`
+	CounterRequest
+}
+
+type BatchRequest struct {
+	Ops []*BatchOp `json:"ops"`
+}
+
+type CounterResponse struct {
+	Key   string `json:"key"`
+	Kind  string `json:"kind"`
+	Value any    `json:"value"`
+	// Applied false when inc dropped as duplicate event
+	Applied bool `json:"applied"`
+}
+
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Key     string `json:"key,omitempty"`
+	// Kind and ExistingKind set for kind mismatch
+	Kind         string `json:"kind,omitempty"`
+	ExistingKind string `json:"existing_kind,omitempty"`
+}
+
+type ErrorResponse struct {
+	Error *ErrorBody `json:"error"`
+}
+
+// BatchResult result of each op in request order, op failed have Error set
+type BatchResult struct {
+	*CounterResponse
+	Error *ErrorBody `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Results []*BatchResult `json:"results"`
+	Failed  int            `json:"failed"`
+}
+
+// NewHTTPHandler json api of counter, POST /v1/inc, /v1/put and /v1/batch
+func NewHTTPHandler(hm *stream_core.HashMapCounter) http.Handler {
+	h := &httpHandler{hm}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/inc", h.inc)
+	mux.HandleFunc("POST /v1/put", h.put)
+	mux.HandleFunc("POST /v1/batch", h.batch)
+	return mux
+}
+
+type httpHandler struct {
+	hm *stream_core.HashMapCounter
+}
+
+func (h *httpHandler) inc(w http.ResponseWriter, r *http.Request) {
+	h.single(w, r, "inc")
+}
+
+func (h *httpHandler) put(w http.ResponseWriter, r *http.Request) {
+	h.single(w, r, "put")
+}
+
+func (h *httpHandler) single(w http.ResponseWriter, r *http.Request, op string) {
+	req := &CounterRequest{}
+	if !decodeBody(w, r, req) {
+		return
+	}
+
+	res, err := h.apply(op, req)
+	if err != nil {
+		body := errorBody(err)
+		writeJSON(w, errorStatus(body), &ErrorResponse{body})
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *httpHandler) batch(w http.ResponseWriter, r *http.Request) {
+	req := &BatchRequest{}
+	if !decodeBody(w, r, req) {
+		return
+	}
+	if len(req.Ops) > MaxBatchOps {
+		writeJSON(w, http.StatusBadRequest, &ErrorResponse{&ErrorBody{
+			Code:    ErrorCodeInvalidRequest,
+			Message: fmt.Sprintf("batch have %d op, max %d", len(req.Ops), MaxBatchOps),
+		}})
+		return
+	}
+
+	// op applied in order, failed op not stopping the rest
+	res := &BatchResponse{Results: make([]*BatchResult, len(req.Ops))}
+	for i, op := range req.Ops {
+		counter, err := h.apply(op.Op, &op.CounterRequest)
+		if err != nil {
+			res.Results[i] = &BatchResult{Error: errorBody(err)}
+			res.Failed++
+			continue
+		}
+		res.Results[i] = &BatchResult{CounterResponse: counter}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+type requestError struct {
+	key string
+	msg string
+}
+
+func (e *requestError) Error() string {
+	return e.msg
+}
+
+func (h *httpHandler) apply(op string, req *CounterRequest) (*CounterResponse, error) {
+	if req.Key == "" {
+		return nil, &requestError{msg: "key empty"}
+	}
+
+	value, err := parseValue(req.Kind, req.Value)
+	if err != nil {
+		return nil, &requestError{key: req.Key, msg: err.Error()}
+	}
+
+	res := &CounterResponse{
+		Key:     req.Key,
+		Kind:    reflect.ValueOf(value).Kind().String(),
+		Applied: true,
+	}
+
+	switch op {
+	case "inc":
+		if req.EventID != "" {
+			res.Applied, err = h.hm.IncWithID(req.EventID, req.Key, value)
+			if err != nil {
+				return nil, err
+			}
+			res.Value = h.current(res.Kind, req.Key)
+			return res, nil
+		}
+		res.Value, err = h.hm.Inc(req.Key, value)
+	case "put":
+		res.Value, err = h.hm.Put(req.Key, value)
+	default:
+		return nil, &requestError{key: req.Key, msg: fmt.Sprintf("op %q not supported", op)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (h *httpHandler) current(kind string, key string) any {
+	switch kind {
+	case "uint64":
+		return h.hm.GetUint64(key)
+	case "float64":
+		return h.hm.GetFloat64(key)
+	default:
+		return h.hm.GetInt64(key)
+	}
+}
+
+// parseValue convert json number to counter kind
+func parseValue(kind string, number json.Number) (any, error) {
+	raw := number.String()
+	if raw == "" {
+		return nil, errors.New("value empty")
+	}
+
+	if kind == "" {
+		kind = "int64"
+		if strings.ContainsAny(raw, ".eE") {
+			kind = "float64"
+		}
+	}
+
+	switch kind {
+	case "int64":
+		return strconv.ParseInt(raw, 10, 64)
+	case "uint64":
+		return strconv.ParseUint(raw, 10, 64)
+	case "float64":
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, err
+		}
+		if math.IsInf(f, 0) {
+			return nil, fmt.Errorf("value %s out of range", raw)
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("kind %q not supported", kind)
+	}
+}
+
+func errorBody(err error) *ErrorBody {
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		return &ErrorBody{Code: ErrorCodeInvalidRequest, Message: reqErr.msg, Key: reqErr.key}
+	}
+
+	var mismatch *stream_core.KindMismatchError
+	if errors.As(err, &mismatch) {
+		return &ErrorBody{
+			Code:         ErrorCodeKindMismatch,
+			Message:      mismatch.Error(),
+			Key:          mismatch.Key,
+			Kind:         mismatch.Kind.String(),
+			ExistingKind: mismatch.Existing.String(),
+		}
+	}
+
+	if errors.Is(err, stream_core.ErrDedupeDisabled) {
+		return &ErrorBody{Code: ErrorCodeDedupeDisabled, Message: err.Error()}
+	}
+	return &ErrorBody{Code: ErrorCodeInternal, Message: err.Error()}
+}
+
+func errorStatus(body *ErrorBody) int {
+	switch body.Code {
+	case ErrorCodeInvalidRequest, ErrorCodeDedupeDisabled:
+		return http.StatusBadRequest
+	case ErrorCodeKindMismatch:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// MaxBodySize max request body byte
+const MaxBodySize = 8 << 20
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodySize))
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, &ErrorResponse{&ErrorBody{
+			Code:    ErrorCodeInvalidRequest,
+			Message: err.Error(),
+		}})
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
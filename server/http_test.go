@@ -0,0 +1,130 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/server"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/server_http_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/server_http_value_unittest",
+		DedupePath:          "/tmp/stream_engine/server_http_dedupe_unittest",
+		DedupeExpectedIDs:   1000,
+		DedupeWindow:        1 << 10,
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+	os.Remove(cfg.DedupePath)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer kv.Close()
+
+	ts := httptest.NewServer(server.NewHTTPHandler(kv))
+	defer ts.Close()
+
+	post := func(path string, body string, res any) int {
+		resp, err := http.Post(ts.URL+path, "application/json", bytes.NewBufferString(body))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(res))
+		return resp.StatusCode
+	}
+
+	t.Run("testing inc and put", func(t *testing.T) {
+		res := &server.CounterResponse{}
+		status := post("/v1/inc", `{"key": "users/1/order_count", "value": 2}`, res)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "int64", res.Kind)
+
+		status = post("/v1/inc", `{"key": "users/1/spent", "value": 10.5}`, res)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "float64", res.Kind)
+
+		status = post("/v1/inc", `{"key": "users/1/visit", "kind": "uint64", "value": 18446744073709551615}`, res)
+		assert.Equal(t, http.StatusOK, status)
+
+		status = post("/v1/put", `{"key": "users/1/balance", "kind": "float64", "value": 100}`, res)
+		assert.Equal(t, http.StatusOK, status)
+
+		assert.Equal(t, int64(2), kv.GetInt64("users/1/order_count"))
+		assert.Equal(t, float64(10.5), kv.GetFloat64("users/1/spent"))
+		assert.Equal(t, uint64(18446744073709551615), kv.GetUint64("users/1/visit"))
+		assert.Equal(t, float64(100), kv.GetFloat64("users/1/balance"))
+	})
+
+	t.Run("testing kind mismatch error body", func(t *testing.T) {
+		res := &server.ErrorResponse{}
+		status := post("/v1/inc", `{"key": "users/1/order_count", "value": 1.5}`, res)
+		assert.Equal(t, http.StatusConflict, status)
+		assert.Equal(t, &server.ErrorBody{
+			Code:         server.ErrorCodeKindMismatch,
+			Message:      "users/1/order_count counter kind int64 not match float64",
+			Key:          "users/1/order_count",
+			Kind:         "float64",
+			ExistingKind: "int64",
+		}, res.Error)
+	})
+
+	t.Run("testing invalid request", func(t *testing.T) {
+		for _, body := range []string{
+			`{"key": "", "value": 1}`,
+			`{"key": "a", "value": 1, "kind": "int32"}`,
+			`{"key": "a", "value": -1, "kind": "uint64"}`,
+			`{"key": "a"}`,
+			`{"key": "a", "value": 1, "unknown": 1}`,
+			`not json`,
+		} {
+			res := &server.ErrorResponse{}
+			status := post("/v1/inc", body, res)
+			assert.Equal(t, http.StatusBadRequest, status, body)
+			assert.Equal(t, server.ErrorCodeInvalidRequest, res.Error.Code, body)
+		}
+	})
+
+	t.Run("testing event id dedupe", func(t *testing.T) {
+		res := &server.CounterResponse{}
+		post("/v1/inc", `{"key": "users/2/order_count", "value": 1, "event_id": "trx-1"}`, res)
+		assert.True(t, res.Applied)
+
+		post("/v1/inc", `{"key": "users/2/order_count", "value": 1, "event_id": "trx-1"}`, res)
+		assert.False(t, res.Applied)
+		assert.Equal(t, float64(1), res.Value)
+	})
+
+	t.Run("testing batch", func(t *testing.T) {
+		res := &server.BatchResponse{}
+		status := post("/v1/batch", `{"ops": [
+			{"op": "inc", "key": "users/3/order_count", "value": 1},
+			{"op": "inc", "key": "users/3/order_count", "value": 0.5},
+			{"op": "put", "key": "users/3/last_order", "value": 99},
+			{"op": "dec", "key": "users/3/order_count", "value": 1}
+		]}`, res)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 2, res.Failed)
+		assert.Nil(t, res.Results[0].Error)
+		assert.Equal(t, server.ErrorCodeKindMismatch, res.Results[1].Error.Code)
+		assert.Equal(t, "int64", res.Results[2].Kind)
+		assert.Equal(t, server.ErrorCodeInvalidRequest, res.Results[3].Error.Code)
+
+		assert.Equal(t, int64(1), kv.GetInt64("users/3/order_count"))
+		assert.Equal(t, int64(99), kv.GetInt64("users/3/last_order"))
+	})
+
+	t.Run("testing method not allowed", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/v1/inc")
+		assert.Nil(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
@@ -0,0 +1,199 @@
+package snapshot
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+// csvSourceSeparator merge source written as nested csv record, so source containing separator is quoted
+const csvSourceSeparator = ';'
+
+var csvHeader = []string{"key", "kind", "value", "updated_at", "merge_op", "merge_sources", "replace"}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := csv.NewWriter(w)
+	err := cw.Write(csvHeader)
+	if err != nil {
+		return nil, err
+	}
+	return &csvWriter{cw}, nil
+}
+
+// Write implements RecordWriter.
+func (c *csvWriter) Write(rec *stream_core.KeyRecord) error {
+	var mergeOp, mergeSources string
+	if rec.Merge != nil {
+		mergeOp = rec.Merge.Op.String()
+		var err error
+		mergeSources, err = joinSources(rec.Merge.Sources)
+		if err != nil {
+			return fmt.Errorf("%s %w", rec.Key, err)
+		}
+	}
+
+	return c.w.Write([]string{
+		rec.Key,
+		kindName(rec.Kind),
+		formatValue(rec.Value),
+		rec.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		mergeOp,
+		mergeSources,
+		strconv.FormatBool(rec.Replace),
+	})
+}
+
+// Close implements RecordWriter.
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type csvReader struct {
+	r      *csv.Reader
+	column map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	column := map[string]int{}
+	for i, name := range header {
+		column[name] = i
+	}
+
+	for _, name := range []string{"key", "kind", "value"} {
+		if _, ok := column[name]; !ok {
+			return nil, fmt.Errorf("csv column %s not found", name)
+		}
+	}
+
+	return &csvReader{cr, column}, nil
+}
+
+func (c *csvReader) get(row []string, name string) string {
+	i, ok := c.column[name]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return row[i]
+}
+
+// Read implements recordReader.
+func (c *csvReader) Read() (*stream_core.KeyRecord, error) {
+	row, err := c.r.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	key := c.get(row, "key")
+	kind, value, err := parseValue(c.get(row, "kind"), c.get(row, "value"))
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", key, err)
+	}
+
+	rec := &stream_core.KeyRecord{
+		Key:   key,
+		Kind:  kind,
+		Value: value,
+	}
+
+	if updatedAt := c.get(row, "updated_at"); updatedAt != "" {
+		rec.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s %w", key, err)
+		}
+	}
+
+	if replace := c.get(row, "replace"); replace != "" {
+		rec.Replace, err = strconv.ParseBool(replace)
+		if err != nil {
+			return nil, fmt.Errorf("%s %w", key, err)
+		}
+	}
+
+	if mergeOp := c.get(row, "merge_op"); mergeOp != "" {
+		op, err := stream_core.ParseMergeOps(mergeOp)
+		if err != nil {
+			return nil, fmt.Errorf("%s %w", key, err)
+		}
+		sources, err := splitSources(c.get(row, "merge_sources"))
+		if err != nil {
+			return nil, fmt.Errorf("%s %w", key, err)
+		}
+		rec.Merge = &stream_core.MergeDefinition{
+			Op:      op,
+			Sources: sources,
+		}
+	}
+
+	return rec, nil
+}
+
+func joinSources(sources []string) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Comma = csvSourceSeparator
+	err := w.Write(sources)
+	if err != nil {
+		return "", err
+	}
+	w.Flush()
+	err = w.Error()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// splitSources parse merge_sources written by joinSources, empty source rejected
+func splitSources(field string) ([]string, error) {
+	if field == "" {
+		return nil, fmt.Errorf("merge sources empty")
+	}
+
+	r := csv.NewReader(strings.NewReader(field))
+	r.Comma = csvSourceSeparator
+	sources, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("merge sources %q: %w", field, err)
+	}
+	if _, err := r.Read(); err != io.EOF {
+		return nil, fmt.Errorf("merge sources %q not single record", field)
+	}
+
+	for _, source := range sources {
+		if source == "" {
+			return nil, fmt.Errorf("merge sources %q has empty source", field)
+		}
+	}
+	return sources, nil
+}
+
+func formatValue(value any) string {
+	switch val := value.(type) {
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case uint64:
+		return strconv.FormatUint(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	default:
+		return fmt.Sprint(val)
+	}
+}
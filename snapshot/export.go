@@ -0,0 +1,106 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+type Format string
+
+const (
+	FormatJSONL   Format = "jsonl"
+	FormatCSV     Format = "csv"
+	FormatParquet Format = "parquet"
+	FormatProto   Format = "proto"
+)
+
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case FormatJSONL, FormatCSV, FormatParquet, FormatProto:
+		return Format(name), nil
+	default:
+		return "", fmt.Errorf("snapshot format %s not supported", name)
+	}
+}
+
+type ExportOption struct {
+	Format Format
+	// only export key with prefix, empty export all key
+	Prefix string
+	// only export key updated at or after since, zero export all key
+	Since time.Time
+}
+
+// RecordWriter write key record in snapshot format, Close flush buffered record
+type RecordWriter interface {
+	Write(rec *stream_core.KeyRecord) error
+	Close() error
+}
+
+// NewRecordWriter create record writer of format writing to w
+func NewRecordWriter(format Format, w io.Writer) (RecordWriter, error) {
+	switch format {
+	case FormatJSONL:
+		return newJSONLWriter(w), nil
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatParquet:
+		return newParquetWriter(w), nil
+	case FormatProto:
+		return newProtoWriter(w), nil
+	default:
+		return nil, fmt.Errorf("snapshot format %s not supported", format)
+	}
+}
+
+// Export write key record from snapshot view of counter to w in write order, return count record written.
+// counter lock only held while view read each batch, so slow w not block ingestion and writing counter from w not deadlock
+func Export(hm *stream_core.HashMapCounter, w io.Writer, opt *ExportOption) (int, error) {
+	rw, err := NewRecordWriter(opt.Format, w)
+	if err != nil {
+		return 0, err
+	}
+
+	view := hm.OpenSnapshot()
+	defer view.Close()
+
+	var count int
+	err = view.Iterate(opt.Since, func(rec *stream_core.KeyRecord) error {
+		if !strings.HasPrefix(rec.Key, opt.Prefix) {
+			return nil
+		}
+		count++
+		return rw.Write(rec)
+	})
+	if err != nil {
+		return count, err
+	}
+
+	return count, rw.Close()
+}
+
+// ExportFile export to file in path, existing file is replaced
+func ExportFile(hm *stream_core.HashMapCounter, path string, opt *ExportOption) (int, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := Export(hm, f, opt)
+	if err != nil {
+		f.Close()
+		return count, err
+	}
+
+	return count, f.Close()
+}
+
+func kindName(kind reflect.Kind) string {
+	return kind.String()
+}
@@ -0,0 +1,169 @@
+package snapshot_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/proto_core/snapshot_message/v1"
+	"github.com/wargasipil/stream_engine/snapshot"
+	"github.com/wargasipil/stream_engine/stream_core"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+func TestExport(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/export_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/export_value_unittest",
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer kv.Close()
+
+	kv.IncInt64("teams/1/debit", 10)
+	kv.IncUint64("teams/1/order_count", 3)
+	kv.IncFloat64("teams/1/balance", 1.5)
+	kv.IncInt64("users/1/debit", 20)
+
+	t.Run("testing export jsonl", func(t *testing.T) {
+		var buf bytes.Buffer
+		count, err := snapshot.Export(kv, &buf, &snapshot.ExportOption{
+			Format: snapshot.FormatJSONL,
+			Prefix: "teams/",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, count)
+
+		keys := []string{}
+		scanner := bufio.NewScanner(&buf)
+		for scanner.Scan() {
+			rec := map[string]any{}
+			err := json.Unmarshal(scanner.Bytes(), &rec)
+			assert.Nil(t, err)
+			keys = append(keys, rec["key"].(string))
+
+			if rec["key"] == "teams/1/balance" {
+				assert.Equal(t, "float64", rec["kind"])
+				assert.Equal(t, 1.5, rec["value"])
+				assert.NotEmpty(t, rec["updated_at"])
+			}
+		}
+		assert.Equal(t, []string{"teams/1/debit", "teams/1/order_count", "teams/1/balance"}, keys)
+	})
+
+	t.Run("testing export csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := snapshot.Export(kv, &buf, &snapshot.ExportOption{
+			Format: snapshot.FormatCSV,
+			Prefix: "teams/1/debit",
+		})
+		assert.Nil(t, err)
+
+		rows, err := csv.NewReader(&buf).ReadAll()
+		assert.Nil(t, err)
+		assert.Len(t, rows, 2)
+		assert.Equal(t, []string{"key", "kind", "value", "updated_at", "merge_op", "merge_sources", "replace"}, rows[0])
+		assert.Equal(t, []string{"teams/1/debit", "int64", "10"}, rows[1][:3])
+	})
+
+	t.Run("testing export parquet", func(t *testing.T) {
+		var buf bytes.Buffer
+		count, err := snapshot.Export(kv, &buf, &snapshot.ExportOption{
+			Format: snapshot.FormatParquet,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 4, count)
+
+		type row struct {
+			Key         string  `parquet:"key"`
+			Uint64Value *uint64 `parquet:"uint64_value,optional"`
+		}
+		rows, err := parquet.Read[row](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		assert.Nil(t, err)
+		assert.Len(t, rows, 4)
+		assert.Equal(t, "teams/1/order_count", rows[1].Key)
+		assert.Equal(t, uint64(3), *rows[1].Uint64Value)
+	})
+
+	t.Run("testing export proto", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := snapshot.Export(kv, &buf, &snapshot.ExportOption{
+			Format: snapshot.FormatProto,
+			Prefix: "users/",
+		})
+		assert.Nil(t, err)
+
+		reader := bufio.NewReader(&buf)
+		msgs := []*snapshot_message.KeyRecord{}
+		for {
+			msg := &snapshot_message.KeyRecord{}
+			err := protodelim.UnmarshalFrom(reader, msg)
+			if err == io.EOF {
+				break
+			}
+			assert.Nil(t, err)
+			msgs = append(msgs, msg)
+		}
+		assert.Len(t, msgs, 1)
+		assert.Equal(t, "users/1/debit", msgs[0].Key)
+		assert.Equal(t, snapshot_message.CounterKind_COUNTER_KIND_INT64, msgs[0].Kind)
+		assert.Equal(t, int64(20), msgs[0].GetInt64Value())
+	})
+
+	t.Run("testing export time filter", func(t *testing.T) {
+		var buf bytes.Buffer
+		count, err := snapshot.Export(kv, &buf, &snapshot.ExportOption{
+			Format: snapshot.FormatJSONL,
+			Since:  time.Now().Add(time.Hour),
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("testing export while writing counter", func(t *testing.T) {
+		for i := 0; i < 200; i++ {
+			kv.IncInt64(fmt.Sprintf("export_write/%s/%d", strings.Repeat("x", 64), i), 1)
+		}
+
+		// buffered writer flush many time during export
+		var buf bytes.Buffer
+		w := writerFunc(func(p []byte) (int, error) {
+			kv.IncInt64("export_hook", 1)
+			return buf.Write(p)
+		})
+		count, err := snapshot.Export(kv, w, &snapshot.ExportOption{
+			Format: snapshot.FormatJSONL,
+			Prefix: "export_write/",
+		})
+		assert.Nil(t, err)
+		assert.Greater(t, kv.GetInt64("export_hook"), int64(1))
+
+		// key sharing slot with other key not written, same count as scan
+		scanned := 0
+		_, err = kv.ScanRecord("export_write/", time.Time{}, nil, func(rec *stream_core.KeyRecord) error {
+			scanned++
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, scanned, count)
+	})
+}
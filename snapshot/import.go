@@ -0,0 +1,87 @@
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+type recordReader interface {
+	// Read return io.EOF when no more record
+	Read() (*stream_core.KeyRecord, error)
+}
+
+func newRecordReader(format Format, r io.Reader) (recordReader, error) {
+	switch format {
+	case FormatJSONL:
+		return newJSONLReader(r), nil
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatProto:
+		return newProtoReader(r), nil
+	default:
+		return nil, fmt.Errorf("snapshot import format %s not supported", format)
+	}
+}
+
+// Import bulk load exported snapshot into counter, key in snapshot must not exist in counter.
+// return count record loaded
+func Import(hm *stream_core.HashMapCounter, r io.Reader, format Format) (int, error) {
+	rr, err := newRecordReader(format, r)
+	if err != nil {
+		return 0, err
+	}
+
+	loader := hm.NewBulkLoader()
+	for {
+		rec, err := rr.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			loader.Close()
+			return loader.Count(), err
+		}
+
+		err = loader.Load(rec)
+		if err != nil {
+			loader.Close()
+			return loader.Count(), err
+		}
+	}
+
+	// load rolled back at Close when merge source missing
+	err = loader.Close()
+	return loader.Count(), err
+}
+
+func ImportFile(hm *stream_core.HashMapCounter, path string, format Format) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return Import(hm, f, format)
+}
+
+func parseValue(kind string, value string) (reflect.Kind, any, error) {
+	switch kind {
+	case reflect.Int64.String():
+		val, err := strconv.ParseInt(value, 10, 64)
+		return reflect.Int64, val, err
+	case reflect.Uint64.String():
+		val, err := strconv.ParseUint(value, 10, 64)
+		return reflect.Uint64, val, err
+	case reflect.Float64.String():
+		val, err := strconv.ParseFloat(value, 64)
+		return reflect.Float64, val, err
+	default:
+		return reflect.Invalid, nil, fmt.Errorf("kind %s not supported", kind)
+	}
+}
@@ -0,0 +1,302 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/snapshot"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func TestImport(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/import_source_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/import_source_value_unittest",
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+
+	source, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer source.Close()
+
+	source.IncFloat64("accounts/1/debit", 100.5)
+	source.IncFloat64("accounts/1/credit", 20)
+	source.IncUint64("accounts/1/order_count", 7)
+	source.IncInt64("accounts/1/stock", -3)
+	source.PutInt64("accounts/1/limit", 50)
+	_, err = source.Merge(stream_core.MergeOpAdd, reflect.Float64, "accounts/1/total", "accounts/1/debit", "accounts/1/credit")
+	assert.Nil(t, err)
+
+	expected := collectRecords(t, source)
+
+	for _, format := range []snapshot.Format{snapshot.FormatJSONL, snapshot.FormatCSV, snapshot.FormatProto} {
+		t.Run(fmt.Sprintf("testing import %s", format), func(t *testing.T) {
+			var buf bytes.Buffer
+			_, err := snapshot.Export(source, &buf, &snapshot.ExportOption{Format: format})
+			assert.Nil(t, err)
+
+			// loading into bigger table
+			target := stream_core.CoreConfig{
+				HashMapCounterPath:  "/tmp/stream_engine/import_target_unittest",
+				HashMapCounterSlots: 4096,
+				DynamicValuePath:    "/tmp/stream_engine/import_target_value_unittest",
+			}
+			os.Remove(target.DynamicValuePath)
+			os.Remove(target.HashMapCounterPath)
+
+			kv, err := stream_core.NewHashMapCounter(&target)
+			assert.Nil(t, err)
+			defer kv.Close()
+
+			count, err := snapshot.Import(kv, &buf, format)
+			assert.Nil(t, err)
+			assert.Equal(t, 6, count)
+
+			assert.Equal(t, expected, collectRecords(t, kv))
+
+			t.Run("replace flag restored", func(t *testing.T) {
+				for _, rec := range collectRecords(t, kv) {
+					assert.Equal(t, rec.Key == "accounts/1/limit", rec.Replace, rec.Key)
+				}
+			})
+
+			t.Run("computed key still mergeable", func(t *testing.T) {
+				kv.IncFloat64("accounts/1/debit", 1)
+				value, err := kv.Merge(stream_core.MergeOpAdd, reflect.Float64, "accounts/1/total", "accounts/1/debit", "accounts/1/credit")
+				assert.Nil(t, err)
+				assert.Equal(t, float64(121.5), value)
+			})
+
+			t.Run("existing key rejected", func(t *testing.T) {
+				var buf bytes.Buffer
+				_, err := snapshot.Export(source, &buf, &snapshot.ExportOption{Format: format})
+				assert.Nil(t, err)
+
+				_, err = snapshot.Import(kv, &buf, format)
+				assert.NotNil(t, err)
+			})
+		})
+	}
+}
+
+func TestImportFilteredExport(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/import_filtered_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/import_filtered_value_unittest",
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+
+	source, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer source.Close()
+
+	source.IncInt64("users/1/debit", 3)
+	source.IncInt64("users/2/debit", 4)
+	_, err = source.Merge(stream_core.MergeOpAdd, reflect.Int64, "users/total", "users/1/debit", "users/2/debit")
+	assert.Nil(t, err)
+
+	// export filtered by prefix keep computed key without its source
+	var all, filtered bytes.Buffer
+	_, err = snapshot.Export(source, &all, &snapshot.ExportOption{Format: snapshot.FormatJSONL, Prefix: "users/"})
+	assert.Nil(t, err)
+	_, err = snapshot.Export(source, &filtered, &snapshot.ExportOption{Format: snapshot.FormatJSONL, Prefix: "users/t"})
+	assert.Nil(t, err)
+
+	target := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/import_filtered_target_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/import_filtered_target_value_unittest",
+	}
+	os.Remove(target.DynamicValuePath)
+	os.Remove(target.HashMapCounterPath)
+
+	kv, err := stream_core.NewHashMapCounter(&target)
+	assert.Nil(t, err)
+	defer kv.Close()
+
+	t.Run("testing missing source rejected", func(t *testing.T) {
+		count, err := snapshot.Import(kv, bytes.NewReader(filtered.Bytes()), snapshot.FormatJSONL)
+		assert.ErrorContains(t, err, "users/1/debit")
+		assert.Equal(t, 0, count)
+
+		_, ok, err := kv.GetRecord("users/total")
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		assert.Empty(t, collectRecords(t, kv))
+	})
+
+	t.Run("testing source already in counter", func(t *testing.T) {
+		count, err := snapshot.Import(kv, &all, snapshot.FormatJSONL)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, collectRecords(t, source), collectRecords(t, kv))
+
+		os.Remove(target.DynamicValuePath + "_preloaded")
+		os.Remove(target.HashMapCounterPath + "_preloaded")
+		preloaded, err := stream_core.NewHashMapCounter(&stream_core.CoreConfig{
+			HashMapCounterPath:  target.HashMapCounterPath + "_preloaded",
+			HashMapCounterSlots: 1024,
+			DynamicValuePath:    target.DynamicValuePath + "_preloaded",
+		})
+		assert.Nil(t, err)
+		defer preloaded.Close()
+		preloaded.IncInt64("users/1/debit", 3)
+		preloaded.IncInt64("users/2/debit", 4)
+
+		count, err = snapshot.Import(preloaded, bytes.NewReader(filtered.Bytes()), snapshot.FormatJSONL)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, count)
+
+		rec, ok, err := preloaded.GetRecord("users/total")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, int64(7), rec.Value)
+	})
+}
+
+func TestImportCSVMergeSources(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/import_csv_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/import_csv_value_unittest",
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+
+	source, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer source.Close()
+
+	source.IncInt64("accounts/1;2/debit", 3)
+	source.IncInt64(`accounts/"1"/debit`, 4)
+	_, err = source.Merge(stream_core.MergeOpAdd, reflect.Int64, "accounts/total", "accounts/1;2/debit", `accounts/"1"/debit`)
+	assert.Nil(t, err)
+
+	t.Run("testing source containing separator", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := snapshot.Export(source, &buf, &snapshot.ExportOption{Format: snapshot.FormatCSV})
+		assert.Nil(t, err)
+
+		target := stream_core.CoreConfig{
+			HashMapCounterPath:  "/tmp/stream_engine/import_csv_target_unittest",
+			HashMapCounterSlots: 1024,
+			DynamicValuePath:    "/tmp/stream_engine/import_csv_target_value_unittest",
+		}
+		os.Remove(target.DynamicValuePath)
+		os.Remove(target.HashMapCounterPath)
+
+		kv, err := stream_core.NewHashMapCounter(&target)
+		assert.Nil(t, err)
+		defer kv.Close()
+
+		count, err := snapshot.Import(kv, &buf, snapshot.FormatCSV)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, collectRecords(t, source), collectRecords(t, kv))
+	})
+
+	t.Run("testing empty source rejected", func(t *testing.T) {
+		for _, sources := range []string{"", "accounts/1;2/debit;", ";"} {
+			data := "key,kind,value,merge_op,merge_sources\naccounts/total,int64,7,add," + sources + "\n"
+
+			os.Remove(cfg.DynamicValuePath + "_empty")
+			os.Remove(cfg.HashMapCounterPath + "_empty")
+			kv, err := stream_core.NewHashMapCounter(&stream_core.CoreConfig{
+				HashMapCounterPath:  cfg.HashMapCounterPath + "_empty",
+				HashMapCounterSlots: 1024,
+				DynamicValuePath:    cfg.DynamicValuePath + "_empty",
+			})
+			assert.Nil(t, err)
+
+			_, err = snapshot.Import(kv, bytes.NewBufferString(data), snapshot.FormatCSV)
+			assert.ErrorContains(t, err, "merge sources", sources)
+			assert.Nil(t, kv.Close())
+		}
+	})
+}
+
+func TestImportNonFiniteFloat(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/import_non_finite_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/import_non_finite_value_unittest",
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+
+	source, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer source.Close()
+
+	source.PutFloat64("ratio/nan", math.NaN())
+	source.PutFloat64("ratio/pos_inf", math.Inf(1))
+	source.PutFloat64("ratio/neg_inf", math.Inf(-1))
+
+	for _, format := range []snapshot.Format{snapshot.FormatJSONL, snapshot.FormatCSV, snapshot.FormatProto} {
+		t.Run(fmt.Sprintf("testing round trip %s", format), func(t *testing.T) {
+			var buf bytes.Buffer
+			_, err := snapshot.Export(source, &buf, &snapshot.ExportOption{Format: format})
+			assert.Nil(t, err)
+
+			target := stream_core.CoreConfig{
+				HashMapCounterPath:  "/tmp/stream_engine/import_non_finite_target_unittest",
+				HashMapCounterSlots: 1024,
+				DynamicValuePath:    "/tmp/stream_engine/import_non_finite_target_value_unittest",
+			}
+			os.Remove(target.DynamicValuePath)
+			os.Remove(target.HashMapCounterPath)
+
+			kv, err := stream_core.NewHashMapCounter(&target)
+			assert.Nil(t, err)
+			defer kv.Close()
+
+			count, err := snapshot.Import(kv, &buf, format)
+			assert.Nil(t, err)
+			assert.Equal(t, 3, count)
+
+			assert.True(t, math.IsNaN(kv.GetFloat64("ratio/nan")))
+			assert.True(t, math.IsInf(kv.GetFloat64("ratio/pos_inf"), 1))
+			assert.True(t, math.IsInf(kv.GetFloat64("ratio/neg_inf"), -1))
+		})
+	}
+
+	t.Run("testing jsonl string value only for non finite float", func(t *testing.T) {
+		for _, line := range []string{
+			`{"key":"ratio/a","kind":"float64","value":"1.5"}`,
+			`{"key":"ratio/a","kind":"int64","value":"NaN"}`,
+		} {
+			os.Remove(cfg.DynamicValuePath + "_invalid")
+			os.Remove(cfg.HashMapCounterPath + "_invalid")
+			kv, err := stream_core.NewHashMapCounter(&stream_core.CoreConfig{
+				HashMapCounterPath:  cfg.HashMapCounterPath + "_invalid",
+				HashMapCounterSlots: 1024,
+				DynamicValuePath:    cfg.DynamicValuePath + "_invalid",
+			})
+			assert.Nil(t, err)
+
+			_, err = snapshot.Import(kv, bytes.NewBufferString(line+"\n"), snapshot.FormatJSONL)
+			assert.ErrorContains(t, err, "value is not number", line)
+			assert.Nil(t, kv.Close())
+		}
+	})
+}
+
+func collectRecords(t *testing.T, kv *stream_core.HashMapCounter) []*stream_core.KeyRecord {
+	records := []*stream_core.KeyRecord{}
+	_, err := kv.ScanRecord("", time.Time{}, nil, func(rec *stream_core.KeyRecord) error {
+		records = append(records, rec)
+		return nil
+	})
+	assert.Nil(t, err)
+	return records
+}
@@ -0,0 +1,129 @@
+package snapshot
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+type jsonMerge struct {
+	Op      string   `json:"op"`
+	Sources []string `json:"sources"`
+}
+
+type jsonRecord struct {
+	Key       string     `json:"key"`
+	Kind      string     `json:"kind"`
+	Value     any        `json:"value"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Replace   bool       `json:"replace,omitempty"`
+	Merge     *jsonMerge `json:"merge,omitempty"`
+}
+
+type jsonlWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	buf := bufio.NewWriter(w)
+	return &jsonlWriter{
+		buf: buf,
+		enc: json.NewEncoder(buf),
+	}
+}
+
+// Write implements RecordWriter.
+func (j *jsonlWriter) Write(rec *stream_core.KeyRecord) error {
+	jrec := &jsonRecord{
+		Key:       rec.Key,
+		Kind:      kindName(rec.Kind),
+		Value:     rec.Value,
+		UpdatedAt: rec.UpdatedAt.UTC(),
+		Replace:   rec.Replace,
+	}
+
+	// json has no literal for non finite float, written as string instead
+	if value, ok := rec.Value.(float64); ok && (math.IsNaN(value) || math.IsInf(value, 0)) {
+		jrec.Value = strconv.FormatFloat(value, 'g', -1, 64)
+	}
+
+	if rec.Merge != nil {
+		jrec.Merge = &jsonMerge{
+			Op:      rec.Merge.Op.String(),
+			Sources: rec.Merge.Sources,
+		}
+	}
+
+	return j.enc.Encode(jrec)
+}
+
+// Close implements RecordWriter.
+func (j *jsonlWriter) Close() error {
+	return j.buf.Flush()
+}
+
+type jsonlReader struct {
+	dec *json.Decoder
+}
+
+func newJSONLReader(r io.Reader) *jsonlReader {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	dec.UseNumber()
+	return &jsonlReader{dec}
+}
+
+// Read implements recordReader.
+func (j *jsonlReader) Read() (*stream_core.KeyRecord, error) {
+	var jrec jsonRecord
+	err := j.dec.Decode(&jrec)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw string
+	switch value := jrec.Value.(type) {
+	case json.Number:
+		raw = value.String()
+	case string:
+		if jrec.Kind != reflect.Float64.String() || (value != "NaN" && value != "+Inf" && value != "-Inf") {
+			return nil, fmt.Errorf("%s value is not number", jrec.Key)
+		}
+		raw = value
+	default:
+		return nil, fmt.Errorf("%s value is not number", jrec.Key)
+	}
+
+	kind, value, err := parseValue(jrec.Kind, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", jrec.Key, err)
+	}
+
+	rec := &stream_core.KeyRecord{
+		Key:       jrec.Key,
+		Kind:      kind,
+		Value:     value,
+		UpdatedAt: jrec.UpdatedAt,
+		Replace:   jrec.Replace,
+	}
+
+	if jrec.Merge != nil {
+		op, err := stream_core.ParseMergeOps(jrec.Merge.Op)
+		if err != nil {
+			return nil, fmt.Errorf("%s %w", jrec.Key, err)
+		}
+		rec.Merge = &stream_core.MergeDefinition{
+			Op:      op,
+			Sources: jrec.Merge.Sources,
+		}
+	}
+
+	return rec, nil
+}
@@ -0,0 +1,62 @@
+package snapshot
+
+import (
+	"io"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+type parquetRecord struct {
+	Key          string    `parquet:"key"`
+	Kind         string    `parquet:"kind"`
+	Int64Value   *int64    `parquet:"int64_value,optional"`
+	Uint64Value  *uint64   `parquet:"uint64_value,optional"`
+	Float64Value *float64  `parquet:"float64_value,optional"`
+	UpdatedAt    time.Time `parquet:"updated_at,timestamp(millisecond)"`
+	Replace      bool      `parquet:"replace"`
+	MergeOp      *string   `parquet:"merge_op,optional"`
+	MergeSources []string  `parquet:"merge_sources,list"`
+}
+
+type parquetWriter struct {
+	w *parquet.GenericWriter[parquetRecord]
+}
+
+func newParquetWriter(w io.Writer) *parquetWriter {
+	return &parquetWriter{parquet.NewGenericWriter[parquetRecord](w)}
+}
+
+// Write implements RecordWriter.
+func (p *parquetWriter) Write(rec *stream_core.KeyRecord) error {
+	row := parquetRecord{
+		Key:       rec.Key,
+		Kind:      kindName(rec.Kind),
+		UpdatedAt: rec.UpdatedAt.UTC(),
+		Replace:   rec.Replace,
+	}
+
+	switch val := rec.Value.(type) {
+	case int64:
+		row.Int64Value = &val
+	case uint64:
+		row.Uint64Value = &val
+	case float64:
+		row.Float64Value = &val
+	}
+
+	if rec.Merge != nil {
+		op := rec.Merge.Op.String()
+		row.MergeOp = &op
+		row.MergeSources = rec.Merge.Sources
+	}
+
+	_, err := p.w.Write([]parquetRecord{row})
+	return err
+}
+
+// Close implements RecordWriter.
+func (p *parquetWriter) Close() error {
+	return p.w.Close()
+}
@@ -0,0 +1,139 @@
+package snapshot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/wargasipil/stream_engine/proto_core/snapshot_message/v1"
+	"github.com/wargasipil/stream_engine/stream_core"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+// protoWriter write varint length prefixed snapshot_message.KeyRecord
+type protoWriter struct {
+	buf *bufio.Writer
+}
+
+func newProtoWriter(w io.Writer) *protoWriter {
+	return &protoWriter{bufio.NewWriter(w)}
+}
+
+// Write implements RecordWriter.
+func (p *protoWriter) Write(rec *stream_core.KeyRecord) error {
+	_, err := protodelim.MarshalTo(p.buf, RecordMessage(rec))
+	return err
+}
+
+// RecordMessage convert key record to snapshot_message.KeyRecord
+func RecordMessage(rec *stream_core.KeyRecord) *snapshot_message.KeyRecord {
+	msg := &snapshot_message.KeyRecord{
+		Key:       rec.Key,
+		Kind:      ProtoKind(rec.Kind),
+		UpdatedAt: rec.UpdatedAt.UnixMilli(),
+		Replace:   rec.Replace,
+	}
+
+	switch val := rec.Value.(type) {
+	case int64:
+		msg.Value = &snapshot_message.KeyRecord_Int64Value{Int64Value: val}
+	case uint64:
+		msg.Value = &snapshot_message.KeyRecord_Uint64Value{Uint64Value: val}
+	case float64:
+		msg.Value = &snapshot_message.KeyRecord_Float64Value{Float64Value: val}
+	}
+
+	if rec.Merge != nil {
+		msg.Merge = &snapshot_message.MergeDefinition{
+			Op:      snapshot_message.MergeOp(rec.Merge.Op + 1),
+			Sources: rec.Merge.Sources,
+		}
+	}
+
+	return msg
+}
+
+// Close implements RecordWriter.
+func (p *protoWriter) Close() error {
+	return p.buf.Flush()
+}
+
+type protoReader struct {
+	buf *bufio.Reader
+}
+
+func newProtoReader(r io.Reader) *protoReader {
+	return &protoReader{bufio.NewReader(r)}
+}
+
+// Read implements recordReader.
+func (p *protoReader) Read() (*stream_core.KeyRecord, error) {
+	msg := &snapshot_message.KeyRecord{}
+	err := protodelim.UnmarshalFrom(p.buf, msg)
+	if err != nil {
+		return nil, err
+	}
+	return RecordFromMessage(msg)
+}
+
+// RecordFromMessage convert snapshot_message.KeyRecord to key record
+func RecordFromMessage(msg *snapshot_message.KeyRecord) (*stream_core.KeyRecord, error) {
+	rec := &stream_core.KeyRecord{
+		Key:       msg.Key,
+		UpdatedAt: time.UnixMilli(msg.UpdatedAt),
+		Replace:   msg.Replace,
+	}
+
+	switch val := msg.Value.(type) {
+	case *snapshot_message.KeyRecord_Int64Value:
+		rec.Kind, rec.Value = reflect.Int64, val.Int64Value
+	case *snapshot_message.KeyRecord_Uint64Value:
+		rec.Kind, rec.Value = reflect.Uint64, val.Uint64Value
+	case *snapshot_message.KeyRecord_Float64Value:
+		rec.Kind, rec.Value = reflect.Float64, val.Float64Value
+	default:
+		return nil, fmt.Errorf("%s value empty", msg.Key)
+	}
+
+	if msg.Merge != nil {
+		if msg.Merge.Op == snapshot_message.MergeOp_MERGE_OP_UNSPECIFIED {
+			return nil, fmt.Errorf("%s merge op unspecified", msg.Key)
+		}
+		rec.Merge = &stream_core.MergeDefinition{
+			Op:      stream_core.MergeOps(msg.Merge.Op - 1),
+			Sources: msg.Merge.Sources,
+		}
+	}
+
+	return rec, nil
+}
+
+// ProtoKind convert counter kind to snapshot_message.CounterKind
+func ProtoKind(kind reflect.Kind) snapshot_message.CounterKind {
+	switch kind {
+	case reflect.Int64:
+		return snapshot_message.CounterKind_COUNTER_KIND_INT64
+	case reflect.Uint64:
+		return snapshot_message.CounterKind_COUNTER_KIND_UINT64
+	case reflect.Float64:
+		return snapshot_message.CounterKind_COUNTER_KIND_FLOAT64
+	default:
+		return snapshot_message.CounterKind_COUNTER_KIND_UNSPECIFIED
+	}
+}
+
+// KindFromProto convert snapshot_message.CounterKind to counter kind, invalid for unspecified
+func KindFromProto(kind snapshot_message.CounterKind) reflect.Kind {
+	switch kind {
+	case snapshot_message.CounterKind_COUNTER_KIND_INT64:
+		return reflect.Int64
+	case snapshot_message.CounterKind_COUNTER_KIND_UINT64:
+		return reflect.Uint64
+	case snapshot_message.CounterKind_COUNTER_KIND_FLOAT64:
+		return reflect.Float64
+	default:
+		return reflect.Invalid
+	}
+}
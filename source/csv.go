@@ -0,0 +1,77 @@
+package source
+
+import (
+	"encoding/csv"
+	"fmt"
+
+	"github.com/wargasipil/stream_engine/pipeline"
+)
+
+// CSVSource first row is header, each field of record is event field named by header with string value
+type CSVSource struct {
+	file   *fileReader
+	reader *csv.Reader
+	header []string
+	// base offset of reader input
+	base int64
+}
+
+func OpenCSV(path string, opt *Option) (Source, error) {
+	opt = optionOf(opt)
+	file, err := openFile(path, 0, opt.Gzip)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(file)
+	header, err := reader.Read()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("reading csv header: %w", err)
+	}
+
+	s := &CSVSource{
+		file:   file,
+		reader: reader,
+		header: header,
+	}
+
+	if opt.Offset <= reader.InputOffset() {
+		return s, nil
+	}
+
+	// header read from start, record read from resumed offset
+	file.Close()
+	s.file, err = openFile(path, opt.Offset, opt.Gzip)
+	if err != nil {
+		return nil, err
+	}
+	s.reader = csv.NewReader(s.file)
+	s.reader.FieldsPerRecord = len(header)
+	s.base = opt.Offset
+	return s, nil
+}
+
+func (s *CSVSource) Next() (*Frame, error) {
+	fields, err := s.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Frame{
+		Offset: s.base + s.reader.InputOffset(),
+		fields: fields,
+	}, nil
+}
+
+func (s *CSVSource) Decode(frame *Frame) (pipeline.Event, error) {
+	event := make(pipeline.Event, len(s.header))
+	for i, name := range s.header {
+		event[name] = frame.fields[i]
+	}
+	return event, nil
+}
+
+func (s *CSVSource) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,93 @@
+package source
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/wargasipil/stream_engine/pipeline"
+)
+
+type decodeJob struct {
+	frame *Frame
+	event pipeline.Event
+	err   error
+	done  chan struct{}
+}
+
+// Run decode frame of source across workers and call handler in source order, so update of same key applied in order they read.
+// handler get frame offset to persist resume position, Run stop at first decode or handler error
+func Run(src Source, workers int, handler func(frame *Frame, event pipeline.Event) error) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make(chan *decodeJob, workers*2)
+	ordered := make(chan *decodeJob, workers*2)
+	stop := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.event, job.err = src.Decode(job.frame)
+				close(job.done)
+			}
+		}()
+	}
+
+	// reading frame sequentially, ordered channel keep read order for handler
+	var readErr error
+	go func() {
+		defer close(ordered)
+		defer close(jobs)
+		for {
+			frame, err := src.Next()
+			if err != nil {
+				if err != io.EOF {
+					readErr = err
+				}
+				return
+			}
+
+			job := &decodeJob{frame: frame, done: make(chan struct{})}
+			select {
+			case jobs <- job:
+			case <-stop:
+				return
+			}
+			select {
+			case ordered <- job:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var err error
+	for job := range ordered {
+		<-job.done
+		if job.err != nil {
+			err = fmt.Errorf("decoding frame ending at offset %d: %w", job.frame.Offset, job.err)
+			break
+		}
+
+		err = handler(job.frame, job.event)
+		if err != nil {
+			break
+		}
+	}
+
+	close(stop)
+	for range ordered {
+	}
+	wg.Wait()
+
+	if err != nil {
+		return err
+	}
+	return readErr
+}
@@ -0,0 +1,80 @@
+package source
+
+import (
+	"github.com/wargasipil/stream_engine/pipeline"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+const DefaultIngestBatchSize = 1000
+
+type IngestOption struct {
+	// Name key of committed offset, like file path or topic partition
+	Name string
+	// BatchSize event per committed batch
+	BatchSize int
+	Workers   int
+}
+
+type IngestResult struct {
+	// Resumed offset ingestion started from
+	Resumed int64
+	Events  int
+	Offset  int64
+}
+
+// Ingest apply rule to event of source in batch committed together with source offset,
+// open is called with last committed offset so restarted ingestion continue where committed state left off
+func Ingest(hm *stream_core.HashMapCounter, rules *pipeline.Pipeline, open func(offset int64) (Source, error), opt *IngestOption) (*IngestResult, error) {
+	batchSize := opt.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultIngestBatchSize
+	}
+
+	offset, _ := hm.SourceOffset(opt.Name)
+	result := &IngestResult{Resumed: offset, Offset: offset}
+
+	src, err := open(offset)
+	if err != nil {
+		return result, err
+	}
+	defer src.Close()
+
+	batch := stream_core.NewBatch()
+	pending := 0
+	var lastOffset int64
+	commit := func() error {
+		if pending == 0 {
+			return nil
+		}
+
+		err := hm.ApplyBatch(batch)
+		if err != nil {
+			return err
+		}
+		result.Events += pending
+		result.Offset = lastOffset
+		batch.Reset()
+		pending = 0
+		return nil
+	}
+
+	err = Run(src, opt.Workers, func(frame *Frame, event pipeline.Event) error {
+		err := rules.AddToBatch(batch, event)
+		if err != nil {
+			return err
+		}
+		batch.SetOffset(opt.Name, frame.Offset)
+		lastOffset = frame.Offset
+		pending++
+
+		if pending < batchSize {
+			return nil
+		}
+		return commit()
+	})
+	if err != nil {
+		return result, err
+	}
+
+	return result, commit()
+}
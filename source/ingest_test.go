@@ -0,0 +1,89 @@
+package source_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/pipeline"
+	"github.com/wargasipil/stream_engine/source"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+const ingestRules = `
+rules:
+  - name: debit
+    key: "accounts/{account}/debit"
+    value: debit
+    kind: int64
+    op: inc
+`
+
+func TestIngest(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		WalDir:              "/tmp/stream_engine/ingest_wal_unittest",
+		HashMapCounterPath:  "/tmp/stream_engine/ingest_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/ingest_value_unittest",
+	}
+	os.RemoveAll(cfg.WalDir)
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer func() { kv.Close() }()
+
+	rs, err := pipeline.ParseRuleSet([]byte(ingestRules))
+	assert.Nil(t, err)
+	rules, err := pipeline.New(rs)
+	assert.Nil(t, err)
+
+	lines := func(from, to int) string {
+		var b strings.Builder
+		for i := from; i < to; i++ {
+			fmt.Fprintf(&b, "{\"account\": \"a%d\", \"debit\": %d}\n", i%2, i)
+		}
+		return b.String()
+	}
+
+	path := writeFile(t, "ingest.jsonl", []byte(lines(0, 10)))
+	open := func(offset int64) (source.Source, error) {
+		return source.OpenJSONL(path, &source.Option{Offset: offset})
+	}
+	opt := &source.IngestOption{Name: path, BatchSize: 3, Workers: 2}
+
+	t.Run("testing ingest commit offset", func(t *testing.T) {
+		result, err := source.Ingest(kv, rules, open, opt)
+		assert.Nil(t, err)
+		assert.Equal(t, 10, result.Events)
+		assert.Equal(t, int64(0+2+4+6+8), kv.GetInt64("accounts/a0/debit"))
+
+		offset, _ := kv.SourceOffset(path)
+		assert.Equal(t, int64(len(lines(0, 10))), offset)
+	})
+
+	t.Run("testing restart continue after committed offset", func(t *testing.T) {
+		assert.Nil(t, kv.Close())
+		kv, err = stream_core.NewHashMapCounter(&cfg)
+		assert.Nil(t, err)
+
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		assert.Nil(t, err)
+		f.WriteString(lines(10, 14) + "{broken\n")
+		f.Close()
+
+		result, err := source.Ingest(kv, rules, open, opt)
+		assert.NotNil(t, err)
+		// last event before broken line not committed yet
+		assert.Equal(t, 3, result.Events)
+		assert.Equal(t, int64(len(lines(0, 10))), result.Resumed)
+		assert.Equal(t, int64(20+10+12), kv.GetInt64("accounts/a0/debit"))
+		assert.Equal(t, int64(25+11), kv.GetInt64("accounts/a1/debit"))
+
+		offset, _ := kv.SourceOffset(path)
+		assert.Equal(t, int64(len(lines(0, 13))), offset)
+	})
+}
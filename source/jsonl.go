@@ -0,0 +1,56 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/wargasipil/stream_engine/pipeline"
+)
+
+// JSONLSource one json object per line, empty line skipped
+type JSONLSource struct {
+	file   *fileReader
+	reader *countingReader
+}
+
+func OpenJSONL(path string, opt *Option) (Source, error) {
+	opt = optionOf(opt)
+	file, err := openFile(path, opt.Offset, opt.Gzip)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JSONLSource{
+		file: file,
+		reader: &countingReader{
+			r:      bufio.NewReader(file),
+			offset: opt.Offset,
+		},
+	}, nil
+}
+
+func (s *JSONLSource) Next() (*Frame, error) {
+	for {
+		line, err := s.reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		data := bytes.TrimSpace(line)
+		if len(data) > 0 {
+			return &Frame{Offset: s.reader.offset, Data: data}, nil
+		}
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+	}
+}
+
+func (s *JSONLSource) Decode(frame *Frame) (pipeline.Event, error) {
+	return pipeline.DecodeJSONEvent(frame.Data)
+}
+
+func (s *JSONLSource) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,178 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/wargasipil/stream_engine/pipeline"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+const DefaultKafkaMaxPollRecords = 1000
+
+type KafkaConfig struct {
+	Brokers []string
+	Topic   string
+	Group   string
+	// MaxPollRecords record applied in one counter batch
+	MaxPollRecords int
+	// Decode record value to event, default json
+	Decode func(value []byte) (pipeline.Event, error)
+	// Options extra client option, like sasl or tls
+	Options []kgo.Opt
+}
+
+// KafkaSource consume topic as consumer group member and apply rule to counter.
+// every poll applied as one counter batch together with partition offset, group offset only committed
+// after batch durable, record already in counter skipped when group offset behind counter offset
+type KafkaSource struct {
+	hm     *stream_core.HashMapCounter
+	rules  *pipeline.Pipeline
+	cfg    *KafkaConfig
+	client *kgo.Client
+	decode func(value []byte) (pipeline.Event, error)
+}
+
+// KafkaOffsetName key of partition offset committed in counter
+func KafkaOffsetName(topic string, partition int32) string {
+	return fmt.Sprintf("kafka/%s/%d", topic, partition)
+}
+
+func NewKafkaSource(hm *stream_core.HashMapCounter, rules *pipeline.Pipeline, cfg *KafkaConfig) (*KafkaSource, error) {
+	k := &KafkaSource{
+		hm:     hm,
+		rules:  rules,
+		cfg:    cfg,
+		decode: cfg.Decode,
+	}
+	if k.decode == nil {
+		k.decode = pipeline.DecodeJSONEvent
+	}
+
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(cfg.Brokers...),
+		kgo.ConsumeTopics(cfg.Topic),
+		kgo.ConsumerGroup(cfg.Group),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+		// revoke only happen between poll, so every polled record already applied when partition revoked
+		kgo.BlockRebalanceOnPoll(),
+		kgo.AutoCommitMarks(),
+		kgo.AdjustFetchOffsetsFn(k.adjustOffsets),
+		kgo.OnPartitionsRevoked(k.flush),
+	}
+	opts = append(opts, cfg.Options...)
+
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+	k.client = client
+	return k, nil
+}
+
+// adjustOffsets start partition from counter offset when group commit lost after batch applied
+func (k *KafkaSource) adjustOffsets(ctx context.Context, offsets map[string]map[int32]kgo.Offset) (map[string]map[int32]kgo.Offset, error) {
+	for topic, partitions := range offsets {
+		for partition, offset := range partitions {
+			committed, ok := k.hm.SourceOffset(KafkaOffsetName(topic, partition))
+			if !ok || committed <= offset.EpochOffset().Offset {
+				continue
+			}
+			partitions[partition] = kgo.NewOffset().At(committed).WithEpoch(-1)
+		}
+	}
+	return offsets, nil
+}
+
+// flush commit offset of applied record before partition moved to other member
+func (k *KafkaSource) flush(ctx context.Context, client *kgo.Client, revoked map[string][]int32) {
+	err := client.CommitMarkedOffsets(ctx)
+	if err != nil {
+		log.Printf("commit offset on rebalance failed: %v", err)
+	}
+}
+
+// Run consume until context canceled or record failed to apply
+func (k *KafkaSource) Run(ctx context.Context) error {
+	for {
+		fetches := k.client.PollRecords(ctx, k.maxPollRecords())
+		if fetches.IsClientClosed() {
+			return nil
+		}
+		if ctx.Err() != nil {
+			k.client.AllowRebalance()
+			return ctx.Err()
+		}
+		fetches.EachError(func(topic string, partition int32, err error) {
+			log.Printf("fetching %s partition %d failed: %v", topic, partition, err)
+		})
+
+		records := fetches.Records()
+		err := k.apply(records)
+		if err != nil {
+			k.client.AllowRebalance()
+			return err
+		}
+
+		k.client.MarkCommitRecords(records...)
+		k.client.AllowRebalance()
+	}
+}
+
+func (k *KafkaSource) maxPollRecords() int {
+	if k.cfg.MaxPollRecords <= 0 {
+		return DefaultKafkaMaxPollRecords
+	}
+	return k.cfg.MaxPollRecords
+}
+
+// apply record as one batch, record before committed counter offset already applied
+func (k *KafkaSource) apply(records []*kgo.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	batch := stream_core.NewBatch()
+	committed := map[string]int64{}
+	pending := 0
+	for _, rec := range records {
+		name := KafkaOffsetName(rec.Topic, rec.Partition)
+		offset, ok := committed[name]
+		if !ok {
+			offset, _ = k.hm.SourceOffset(name)
+			committed[name] = offset
+		}
+		if rec.Offset < offset {
+			continue
+		}
+
+		event, err := k.decode(rec.Value)
+		if err != nil {
+			return fmt.Errorf("decoding %s partition %d offset %d: %w", rec.Topic, rec.Partition, rec.Offset, err)
+		}
+		err = k.rules.AddToBatch(batch, event)
+		if err != nil {
+			return fmt.Errorf("%s partition %d offset %d: %w", rec.Topic, rec.Partition, rec.Offset, err)
+		}
+		batch.SetOffset(name, rec.Offset+1)
+		pending++
+	}
+
+	if pending == 0 {
+		return nil
+	}
+	return k.hm.ApplyBatch(batch)
+}
+
+// Close commit offset of applied record and leave group
+func (k *KafkaSource) Close() error {
+	err := k.client.CommitMarkedOffsets(context.Background())
+	k.client.CloseAllowingRebalance()
+	if errors.Is(err, kgo.ErrClientClosed) {
+		return nil
+	}
+	return err
+}
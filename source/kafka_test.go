@@ -0,0 +1,109 @@
+package source_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/wargasipil/stream_engine/pipeline"
+	"github.com/wargasipil/stream_engine/source"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func TestKafkaSource(t *testing.T) {
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(2, "events"))
+	assert.Nil(t, err)
+	defer cluster.Close()
+
+	producer, err := kgo.NewClient(kgo.SeedBrokers(cluster.ListenAddrs()...))
+	assert.Nil(t, err)
+	defer producer.Close()
+
+	produce := func(from, to int) {
+		for i := from; i < to; i++ {
+			rec := &kgo.Record{
+				Topic: "events",
+				Key:   []byte(fmt.Sprintf("a%d", i%2)),
+				Value: []byte(fmt.Sprintf(`{"account": "a%d", "debit": %d}`, i%2, i)),
+			}
+			assert.Nil(t, producer.ProduceSync(context.Background(), rec).FirstErr())
+		}
+	}
+
+	cfg := stream_core.CoreConfig{
+		WalDir:              "/tmp/stream_engine/kafka_wal_unittest",
+		HashMapCounterPath:  "/tmp/stream_engine/kafka_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/kafka_value_unittest",
+	}
+	os.RemoveAll(cfg.WalDir)
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer kv.Close()
+
+	rs, err := pipeline.ParseRuleSet([]byte(ingestRules))
+	assert.Nil(t, err)
+	rules, err := pipeline.New(rs)
+	assert.Nil(t, err)
+
+	total := func() int64 {
+		return kv.GetInt64("accounts/a0/debit") + kv.GetInt64("accounts/a1/debit")
+	}
+
+	// consume until counter reach expected total
+	consume := func(t *testing.T, group string, expected int64) {
+		src, err := source.NewKafkaSource(kv, rules, &source.KafkaConfig{
+			Brokers:        cluster.ListenAddrs(),
+			Topic:          "events",
+			Group:          group,
+			MaxPollRecords: 3,
+		})
+		assert.Nil(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- src.Run(ctx)
+		}()
+
+		deadline := time.Now().Add(10 * time.Second)
+		for total() < expected && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+		assert.Nil(t, src.Close())
+	}
+
+	t.Run("testing consume group", func(t *testing.T) {
+		produce(0, 10)
+		consume(t, "counter", 45)
+		assert.Equal(t, int64(0+2+4+6+8), kv.GetInt64("accounts/a0/debit"))
+		assert.Equal(t, int64(1+3+5+7+9), kv.GetInt64("accounts/a1/debit"))
+
+		offsets := kv.SourceOffsets()
+		assert.Equal(t, int64(10), offsets[source.KafkaOffsetName("events", 0)]+offsets[source.KafkaOffsetName("events", 1)])
+	})
+
+	t.Run("testing restart continue from committed offset", func(t *testing.T) {
+		produce(10, 14)
+		consume(t, "counter", 45+10+11+12+13)
+		// source stopped inside consume, record delivered twice already applied here
+		assert.Equal(t, int64(45+10+11+12+13), total())
+	})
+
+	t.Run("testing record already in counter skipped when group offset lost", func(t *testing.T) {
+		produce(14, 15)
+		// new group start from beginning of topic
+		consume(t, "counter_new_group", 45+10+11+12+13+14)
+		assert.Equal(t, int64(45+10+11+12+13+14), total())
+	})
+}
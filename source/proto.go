@@ -0,0 +1,79 @@
+package source
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/wargasipil/stream_engine/pipeline"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// MaxProtoFrameSize reject corrupted length prefix
+const MaxProtoFrameSize = 64 << 20
+
+// ProtoSource varint length delimited protobuf message, event field named by proto field name
+type ProtoSource struct {
+	file       *fileReader
+	reader     *countingReader
+	newMessage func() proto.Message
+}
+
+func OpenProto(path string, newMessage func() proto.Message, opt *Option) (Source, error) {
+	opt = optionOf(opt)
+	file, err := openFile(path, opt.Offset, opt.Gzip)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProtoSource{
+		file: file,
+		reader: &countingReader{
+			r:      bufio.NewReader(file),
+			offset: opt.Offset,
+		},
+		newMessage: newMessage,
+	}, nil
+}
+
+func (s *ProtoSource) Next() (*Frame, error) {
+	start := s.reader.offset
+	size, err := binary.ReadUvarint(s.reader)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("reading frame size at offset %d: %w", start, err)
+	}
+	if size > MaxProtoFrameSize {
+		return nil, fmt.Errorf("frame at offset %d size %d too large", start, size)
+	}
+
+	data := make([]byte, size)
+	_, err = io.ReadFull(s.reader, data)
+	if err != nil {
+		return nil, fmt.Errorf("reading frame at offset %d: %w", start, err)
+	}
+
+	return &Frame{Offset: s.reader.offset, Data: data}, nil
+}
+
+func (s *ProtoSource) Decode(frame *Frame) (pipeline.Event, error) {
+	msg := s.newMessage()
+	err := proto.Unmarshal(frame.Data, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return pipeline.DecodeJSONEvent(data)
+}
+
+func (s *ProtoSource) Close() error {
+	return s.file.Close()
+}
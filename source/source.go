@@ -0,0 +1,129 @@
+package source
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/wargasipil/stream_engine/pipeline"
+)
+
+// Frame one raw record split from source, Offset is byte offset right after record,
+// opening source with that offset resume from next record
+type Frame struct {
+	Offset int64
+	Data   []byte
+	// fields of csv record
+	fields []string
+}
+
+// Source split input to frame sequentially, Decode is safe to call concurrently so frame can be decoded by many worker
+type Source interface {
+	// Next return io.EOF when no more frame
+	Next() (*Frame, error)
+	Decode(frame *Frame) (pipeline.Event, error)
+	Close() error
+}
+
+type Option struct {
+	// Offset resume reading from byte offset returned by frame, for gzip file offset is in uncompressed stream
+	Offset int64
+	// Gzip input compressed, file with .gz suffix always read as gzip
+	Gzip bool
+}
+
+// Open open jsonl or csv file
+func Open(format string, path string, opt *Option) (Source, error) {
+	switch format {
+	case "jsonl":
+		return OpenJSONL(path, opt)
+	case "csv":
+		return OpenCSV(path, opt)
+	default:
+		return nil, fmt.Errorf("source format %s not supported", format)
+	}
+}
+
+// countingReader count byte consumed from buffered reader
+type countingReader struct {
+	r      *bufio.Reader
+	offset int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.offset += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.offset++
+	}
+	return b, err
+}
+
+func (c *countingReader) ReadBytes(delim byte) ([]byte, error) {
+	data, err := c.r.ReadBytes(delim)
+	c.offset += int64(len(data))
+	return data, err
+}
+
+type fileReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (f *fileReader) Close() error {
+	var err error
+	for i := len(f.closers) - 1; i >= 0; i-- {
+		if cerr := f.closers[i].Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// openFile open file positioned at offset, gzip stream is decompressed and skipped to offset
+func openFile(path string, offset int64, compressed bool) (*fileReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	reader := &fileReader{Reader: f, closers: []io.Closer{f}}
+
+	if !compressed && !strings.HasSuffix(path, ".gz") {
+		_, err = f.Seek(offset, io.SeekStart)
+		if err != nil {
+			reader.Close()
+			return nil, err
+		}
+		return reader, nil
+	}
+
+	gz, err := gzip.NewReader(bufio.NewReader(f))
+	if err != nil {
+		reader.Close()
+		return nil, err
+	}
+	reader.Reader = gz
+	reader.closers = append(reader.closers, gz)
+
+	_, err = io.CopyN(io.Discard, gz, offset)
+	if err != nil {
+		reader.Close()
+		return nil, fmt.Errorf("skipping to offset %d: %w", offset, err)
+	}
+	return reader, nil
+}
+
+func optionOf(opt *Option) *Option {
+	if opt == nil {
+		return &Option{}
+	}
+	return opt
+}
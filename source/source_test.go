@@ -0,0 +1,167 @@
+package source_test
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/pipeline"
+	snapshot_message "github.com/wargasipil/stream_engine/proto_core/snapshot_message/v1"
+	"github.com/wargasipil/stream_engine/source"
+	"google.golang.org/protobuf/proto"
+)
+
+const testDir = "/tmp/stream_engine/source_unittest"
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	os.MkdirAll(testDir, 0755)
+	path := testDir + "/" + name
+	os.Remove(path)
+	assert.Nil(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func readAll(t *testing.T, src source.Source) ([]pipeline.Event, []int64) {
+	events := []pipeline.Event{}
+	offsets := []int64{}
+	err := source.Run(src, 4, func(frame *source.Frame, event pipeline.Event) error {
+		events = append(events, event)
+		offsets = append(offsets, frame.Offset)
+		return nil
+	})
+	assert.Nil(t, err)
+	return events, offsets
+}
+
+func TestSource(t *testing.T) {
+	lines := []string{}
+	for i := 0; i < 100; i++ {
+		lines = append(lines, fmt.Sprintf(`{"account": "a%d", "seq": %d}`, i%3, i))
+	}
+	jsonl := strings.Join(lines, "\n\n") + "\n"
+
+	t.Run("testing jsonl keep order", func(t *testing.T) {
+		src, err := source.OpenJSONL(writeFile(t, "events.jsonl", []byte(jsonl)), nil)
+		assert.Nil(t, err)
+		defer src.Close()
+
+		events, offsets := readAll(t, src)
+		assert.Len(t, events, 100)
+		for i, event := range events {
+			seq, _ := event["seq"].(fmt.Stringer)
+			assert.Equal(t, fmt.Sprint(i), seq.String())
+		}
+		assert.Equal(t, int64(len(jsonl)), offsets[99])
+	})
+
+	t.Run("testing jsonl resume from offset", func(t *testing.T) {
+		path := writeFile(t, "events.jsonl", []byte(jsonl))
+		src, err := source.OpenJSONL(path, nil)
+		assert.Nil(t, err)
+		_, offsets := readAll(t, src)
+		src.Close()
+
+		src, err = source.OpenJSONL(path, &source.Option{Offset: offsets[49]})
+		assert.Nil(t, err)
+		defer src.Close()
+
+		events, _ := readAll(t, src)
+		assert.Len(t, events, 50)
+		assert.Equal(t, "50", fmt.Sprint(events[0]["seq"]))
+	})
+
+	t.Run("testing gzip resume from offset", func(t *testing.T) {
+		var buf strings.Builder
+		gz := gzip.NewWriter(&buf)
+		gz.Write([]byte(jsonl))
+		gz.Close()
+		path := writeFile(t, "events.jsonl.gz", []byte(buf.String()))
+
+		src, err := source.OpenJSONL(path, nil)
+		assert.Nil(t, err)
+		events, offsets := readAll(t, src)
+		src.Close()
+		assert.Len(t, events, 100)
+
+		src, err = source.OpenJSONL(path, &source.Option{Offset: offsets[89]})
+		assert.Nil(t, err)
+		defer src.Close()
+
+		events, _ = readAll(t, src)
+		assert.Len(t, events, 10)
+		assert.Equal(t, "90", fmt.Sprint(events[0]["seq"]))
+	})
+
+	t.Run("testing csv header mapped", func(t *testing.T) {
+		data := "account,note,amount\na1,\"first\nline\",10\na2,second,20\na1,third,30\n"
+		path := writeFile(t, "events.csv", []byte(data))
+
+		src, err := source.OpenCSV(path, nil)
+		assert.Nil(t, err)
+		events, offsets := readAll(t, src)
+		src.Close()
+		assert.Equal(t, []pipeline.Event{
+			{"account": "a1", "note": "first\nline", "amount": "10"},
+			{"account": "a2", "note": "second", "amount": "20"},
+			{"account": "a1", "note": "third", "amount": "30"},
+		}, events)
+
+		src, err = source.OpenCSV(path, &source.Option{Offset: offsets[0]})
+		assert.Nil(t, err)
+		defer src.Close()
+
+		events, _ = readAll(t, src)
+		assert.Len(t, events, 2)
+		assert.Equal(t, "second", events[0]["note"])
+	})
+
+	t.Run("testing length delimited proto", func(t *testing.T) {
+		data := []byte{}
+		for i := 0; i < 20; i++ {
+			msg, err := proto.Marshal(&snapshot_message.KeyRecord{
+				Key:       fmt.Sprintf("key_%d", i),
+				UpdatedAt: int64(i),
+			})
+			assert.Nil(t, err)
+			data = binary.AppendUvarint(data, uint64(len(msg)))
+			data = append(data, msg...)
+		}
+		path := writeFile(t, "events.pb", data)
+		newMessage := func() proto.Message { return &snapshot_message.KeyRecord{} }
+
+		src, err := source.OpenProto(path, newMessage, nil)
+		assert.Nil(t, err)
+		events, offsets := readAll(t, src)
+		src.Close()
+		assert.Len(t, events, 20)
+		assert.Equal(t, "key_3", events[3]["key"])
+		assert.Equal(t, "3", events[3]["updated_at"])
+		assert.Equal(t, int64(len(data)), offsets[19])
+
+		src, err = source.OpenProto(path, newMessage, &source.Option{Offset: offsets[9]})
+		assert.Nil(t, err)
+		defer src.Close()
+
+		events, _ = readAll(t, src)
+		assert.Len(t, events, 10)
+		assert.Equal(t, "key_10", events[0]["key"])
+	})
+
+	t.Run("testing decode error stop run", func(t *testing.T) {
+		src, err := source.OpenJSONL(writeFile(t, "broken.jsonl", []byte("{\"seq\": 1}\n{broken\n{\"seq\": 3}\n")), nil)
+		assert.Nil(t, err)
+		defer src.Close()
+
+		count := 0
+		err = source.Run(src, 2, func(frame *source.Frame, event pipeline.Event) error {
+			count++
+			return nil
+		})
+		assert.NotNil(t, err)
+		assert.Equal(t, 1, count)
+	})
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var boltBucket = []byte("key_storage")
+
+// BoltKeyStorage keep value in local bolt file, key is path and field separated by zero byte,
+// value is | 1 kind | 8 value |
+type BoltKeyStorage struct {
+	db *bolt.DB
+}
+
+func NewBoltKeyStorage(path string) (KeyStorage, error) {
+	db, err := bolt.Open(path, 0644, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltKeyStorage{db}, nil
+}
+
+func boltKey(path string, field string) []byte {
+	key := make([]byte, 0, len(path)+len(field)+1)
+	key = append(key, path...)
+	key = append(key, 0)
+	return append(key, field...)
+}
+
+func boltEncode(value Value) []byte {
+	data := make([]byte, 9)
+	data[0] = byte(value.Kind)
+	binary.LittleEndian.PutUint64(data[1:], value.bits())
+	return data
+}
+
+func boltDecode(data []byte) (Value, error) {
+	switch len(data) {
+	case 8:
+		// written before value typed
+		return Int64Value(int64(binary.LittleEndian.Uint64(data))), nil
+	case 9:
+		return valueFromBits(reflect.Kind(data[0]), binary.LittleEndian.Uint64(data[1:])), nil
+	default:
+		return Value{}, errors.New("key storage value corrupted")
+	}
+}
+
+func boltIncrement(bucket *bolt.Bucket, path string, field string, delta Value) error {
+	err := validateMutation(path, field, delta)
+	if err != nil {
+		return err
+	}
+
+	key := boltKey(path, field)
+	data := bucket.Get(key)
+	if data != nil {
+		value, err := boltDecode(data)
+		if err != nil {
+			return err
+		}
+
+		delta, err = value.Add(delta)
+		if err != nil {
+			return err
+		}
+	}
+
+	return bucket.Put(key, boltEncode(delta))
+}
+
+// Increment implements KeyStorage.
+func (b *BoltKeyStorage) Increment(path string, field string, delta Value) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return boltIncrement(tx.Bucket(boltBucket), path, field, delta)
+	})
+}
+
+// IncrementBatch implements KeyStorage.
+// all valid mutation written in single transaction
+func (b *BoltKeyStorage) IncrementBatch(mutations []Mutation) error {
+	batchErr := &BatchError{Errors: map[int]error{}}
+
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltBucket)
+		for i, mut := range mutations {
+			err := boltIncrement(bucket, mut.Path, mut.Field, mut.Delta)
+			if err == nil {
+				continue
+			}
+
+			if permanentError(err) {
+				batchErr.Errors[i] = err
+				continue
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(batchErr.Errors) > 0 {
+		return batchErr
+	}
+	return nil
+}
+
+// Put implements KeyStorage.
+func (b *BoltKeyStorage) Put(path string, field string, value Value) error {
+	err := validateMutation(path, field, value)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Put(boltKey(path, field), boltEncode(value))
+	})
+}
+
+// Get implements KeyStorage.
+func (b *BoltKeyStorage) Get(path string, field string) (Value, error) {
+	var value Value
+	err := b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(boltBucket).Get(boltKey(path, field))
+		if data == nil {
+			return ErrNotFound
+		}
+
+		var err error
+		value, err = boltDecode(data)
+		return err
+	})
+
+	return value, err
+}
+
+func (b *BoltKeyStorage) Close() error {
+	return b.db.Close()
+}
@@ -0,0 +1,47 @@
+package storage_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/storage"
+	"github.com/wargasipil/stream_engine/storage/storagetest"
+)
+
+func TestBoltKeyStorage(t *testing.T) {
+	os.MkdirAll("/tmp/stream_engine", 0755)
+
+	count := 0
+	storagetest.Run(t, func(t *testing.T) storage.KeyStorage {
+		count++
+		path := fmt.Sprintf("/tmp/stream_engine/bolt_storage_unittest_%d", count)
+		os.Remove(path)
+
+		store, err := storage.NewBoltKeyStorage(path)
+		assert.Nil(t, err)
+		return store
+	})
+
+	t.Run("testing value persisted after reopen", func(t *testing.T) {
+		path := "/tmp/stream_engine/bolt_storage_reopen_unittest"
+		os.Remove(path)
+
+		store, err := storage.NewBoltKeyStorage(path)
+		assert.Nil(t, err)
+		assert.Nil(t, store.Increment("users/1", "visit", storage.Uint64Value(4)))
+		assert.Nil(t, store.Close())
+
+		store, err = storage.NewBoltKeyStorage(path)
+		assert.Nil(t, err)
+		defer store.Close()
+
+		value, err := store.Get("users/1", "visit")
+		assert.Nil(t, err)
+		assert.Equal(t, storage.Uint64Value(4), value)
+
+		err = store.Increment("users/1", "visit", storage.Float64Value(1))
+		assert.ErrorIs(t, err, storage.ErrKindMismatch)
+	})
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("key storage circuit open")
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker open after threshold consecutive failure, after cooldown one trial call is allowed
+// and its result close or reopen the circuit
+type circuitBreaker struct {
+	lock      sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     breakerState
+	failures  int
+	openUntil time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Now().Before(b.openUntil) {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// trial call still running
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) success() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.state = breakerClosed
+	b.failures = 0
+}
+
+func (b *circuitBreaker) failure() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+func (b *circuitBreaker) open() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.state != breakerClosed
+}
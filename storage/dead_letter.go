@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	wal_message "github.com/wargasipil/stream_engine/proto_core/wal_message/v1"
+	"github.com/wargasipil/stream_engine/stream_core"
+	"google.golang.org/protobuf/proto"
+)
+
+type RedriveResult struct {
+	Delivered int
+	// Failed mutation written back to dead letter dir
+	Failed int
+}
+
+// RedriveDeadLetter push dead lettered mutation in dir to storage one by one, dir must not used by running writer.
+// dir is moved aside before pushing, so mutation failed again written to fresh dead letter in dir.
+// processed count is persisted after every mutation, interrupted redrive continue after last persisted mutation.
+// delivery is at least once, increment delivered right before crash is pushed again on next redrive
+func RedriveDeadLetter(dir string, storage KeyStorage) (*RedriveResult, error) {
+	redriveDir := filepath.Clean(dir) + ".redrive"
+	progressPath := filepath.Join(redriveDir, "redrive_progress")
+
+	// previous redrive interrupted, continue it first
+	_, err := os.Stat(redriveDir)
+	if errors.Is(err, os.ErrNotExist) {
+		_, err = os.Stat(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			return &RedriveResult{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		err = os.Rename(dir, redriveDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := readRedriveProgress(progressPath)
+	if err != nil {
+		return nil, err
+	}
+
+	letters := []*wal_message.DeadLetter{}
+	var decodeErr error
+	err = stream_core.Replay(redriveDir, func(data []byte) {
+		letter := &wal_message.DeadLetter{}
+		err := proto.Unmarshal(data, letter)
+		if err != nil && decodeErr == nil {
+			decodeErr = err
+		}
+		letters = append(letters, letter)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	if progress > uint64(len(letters)) {
+		return nil, fmt.Errorf("redrive progress %d beyond %d dead letter", progress, len(letters))
+	}
+
+	retry, err := stream_core.OpenWAL(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer retry.Close()
+
+	result := &RedriveResult{}
+	for i := progress; i < uint64(len(letters)); i++ {
+		err = redriveLetter(letters[i], storage, retry, result)
+		if err != nil {
+			return result, err
+		}
+
+		err = writeRedriveProgress(progressPath, i+1)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, os.RemoveAll(redriveDir)
+}
+
+// redriveLetter deliver one dead letter, letter failed again appended to retry
+func redriveLetter(letter *wal_message.DeadLetter, storage KeyStorage, retry *stream_core.WAL, result *RedriveResult) error {
+	var err error
+	switch letter.Op {
+	case wal_message.DeadLetterOp_DEAD_LETTER_OP_INCREMENT:
+		err = storage.Increment(letter.Path, letter.Field, deadLetterValue(letter))
+	case wal_message.DeadLetterOp_DEAD_LETTER_OP_PUT:
+		err = storage.Put(letter.Path, letter.Field, deadLetterValue(letter))
+	default:
+		return fmt.Errorf("dead letter op %s not supported", letter.Op)
+	}
+	if err == nil {
+		result.Delivered++
+		return nil
+	}
+
+	letter.Attempt++
+	letter.Error = err.Error()
+	err = retry.Append(letter)
+	if err != nil {
+		return err
+	}
+	result.Failed++
+	return nil
+}
+
+func deadLetterValue(letter *wal_message.DeadLetter) Value {
+	switch reflect.Kind(letter.Kind) {
+	case reflect.Uint64:
+		return Value{Kind: reflect.Uint64, Uint: letter.UintValue, Neg: letter.Negative}
+	case reflect.Float64:
+		return Float64Value(letter.FloatValue)
+	default:
+		return Int64Value(letter.Value)
+	}
+}
+
+func readRedriveProgress(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	if len(data) != 8 {
+		return 0, errors.New("redrive progress corrupted")
+	}
+	return binary.LittleEndian.Uint64(data), nil
+}
+
+func writeRedriveProgress(path string, processed uint64) error {
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, processed)
+
+	tmpPath := path + ".tmp"
+	err := os.WriteFile(tmpPath, data, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
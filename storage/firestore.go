@@ -2,10 +2,18 @@ package storage
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"reflect"
 
 	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// FirestoreMaxBatchWrite max write per batch in firestore
+const FirestoreMaxBatchWrite = 500
+
 type FirestoreKeyStorage struct {
 	ctx    context.Context
 	client *firestore.Client
@@ -19,17 +27,187 @@ func NewFirestoreKeyStorage(ctx context.Context, projectID, database string) (Ke
 	return &FirestoreKeyStorage{ctx, client}, nil
 }
 
+// firestoreValue firestore only has int64 and double number, uint64 stored as int64 and decrease sent negative
+func firestoreValue(value Value) (any, error) {
+	switch value.Kind {
+	case reflect.Int64:
+		return value.Int, nil
+	case reflect.Uint64:
+		if value.Uint > math.MaxInt64 {
+			return nil, fmt.Errorf("%w: uint value %s firestore integer", ErrValueOverflow, value)
+		}
+		return value.Int64(), nil
+	case reflect.Float64:
+		return value.Float, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKind, value.Kind)
+	}
+}
+
 // Increment implements KeyStorage.
-func (f *FirestoreKeyStorage) Increment(path string, field string, delta int64) error {
+func (f *FirestoreKeyStorage) Increment(path string, field string, delta Value) error {
+	err := validateMutation(path, field, delta)
+	if err != nil {
+		return err
+	}
+
+	number, err := firestoreValue(delta)
+	if err != nil {
+		return err
+	}
+
 	doc := f.client.Doc(path)
 
-	_, err := doc.Set(f.ctx, map[string]interface{}{
-		field: firestore.Increment(delta),
+	_, err = doc.Set(f.ctx, map[string]interface{}{
+		field: firestore.Increment(number),
 	}, firestore.MergeAll)
 
 	return err
 }
 
+type firestoreDocWrite struct {
+	path   string
+	fields map[string]Value
+	// index of mutation written in this document
+	indexes []int
+}
+
+// IncrementBatch implements KeyStorage.
+// mutation on same document merged into single write, because bulk writer not allow writing same document twice
+func (f *FirestoreKeyStorage) IncrementBatch(mutations []Mutation) error {
+	batchErr := &BatchError{Errors: map[int]error{}}
+	docs := []*firestoreDocWrite{}
+	docIndex := map[string]*firestoreDocWrite{}
+	for i, mut := range mutations {
+		err := validateMutation(mut.Path, mut.Field, mut.Delta)
+		if err != nil {
+			batchErr.Errors[i] = err
+			continue
+		}
+
+		doc, ok := docIndex[mut.Path]
+		if !ok {
+			doc = &firestoreDocWrite{
+				path:   mut.Path,
+				fields: map[string]Value{},
+			}
+			docIndex[mut.Path] = doc
+			docs = append(docs, doc)
+		}
+
+		delta := mut.Delta
+		prev, ok := doc.fields[mut.Field]
+		if ok {
+			delta, err = prev.Add(delta)
+			if err != nil {
+				batchErr.Errors[i] = err
+				continue
+			}
+		}
+		doc.fields[mut.Field] = delta
+		doc.indexes = append(doc.indexes, i)
+	}
+
+	for start := 0; start < len(docs); start += FirestoreMaxBatchWrite {
+		end := min(start+FirestoreMaxBatchWrite, len(docs))
+		f.writeChunk(docs[start:end], batchErr)
+	}
+
+	if len(batchErr.Errors) > 0 {
+		return batchErr
+	}
+	return nil
+}
+
+func (f *FirestoreKeyStorage) writeChunk(docs []*firestoreDocWrite, batchErr *BatchError) {
+	bw := f.client.BulkWriter(f.ctx)
+	jobs := make([]*firestore.BulkWriterJob, len(docs))
+
+	for i, doc := range docs {
+		data := make(map[string]interface{}, len(doc.fields))
+		var err error
+		for field, delta := range doc.fields {
+			var number any
+			number, err = firestoreValue(delta)
+			if err != nil {
+				break
+			}
+			data[field] = firestore.Increment(number)
+		}
+
+		if err == nil {
+			jobs[i], err = bw.Set(f.client.Doc(doc.path), data, firestore.MergeAll)
+		}
+		if err != nil {
+			for _, index := range doc.indexes {
+				batchErr.Errors[index] = err
+			}
+		}
+	}
+
+	bw.End()
+
+	for i, job := range jobs {
+		if job == nil {
+			continue
+		}
+
+		_, err := job.Results()
+		if err != nil {
+			for _, index := range docs[i].indexes {
+				batchErr.Errors[index] = err
+			}
+		}
+	}
+}
+
+// Put implements KeyStorage.
+func (f *FirestoreKeyStorage) Put(path string, field string, value Value) error {
+	err := validateMutation(path, field, value)
+	if err != nil {
+		return err
+	}
+
+	number, err := firestoreValue(value)
+	if err != nil {
+		return err
+	}
+
+	doc := f.client.Doc(path)
+
+	_, err = doc.Set(f.ctx, map[string]interface{}{
+		field: number,
+	}, firestore.MergeAll)
+
+	return err
+}
+
+// Get implements KeyStorage.
+// integer field returned as int64 value
+func (f *FirestoreKeyStorage) Get(path string, field string) (Value, error) {
+	snap, err := f.client.Doc(path).Get(f.ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return Value{}, ErrNotFound
+		}
+		return Value{}, err
+	}
+
+	value, ok := snap.Data()[field]
+	if !ok {
+		return Value{}, ErrNotFound
+	}
+
+	switch val := value.(type) {
+	case int64:
+		return Int64Value(val), nil
+	case float64:
+		return Float64Value(val), nil
+	default:
+		return Value{}, fmt.Errorf("%s field %s type %T not supported", path, field, value)
+	}
+}
+
 func (f *FirestoreKeyStorage) Close() error {
 	return f.client.Close()
 }
@@ -0,0 +1,72 @@
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/storage"
+	"github.com/wargasipil/stream_engine/storage/storagetest"
+)
+
+// running against emulator, gcloud emulators firestore start --host-port=localhost:8080
+// and FIRESTORE_EMULATOR_HOST=localhost:8080
+func TestFirestoreKeyStorage(t *testing.T) {
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST not set")
+	}
+
+	storagetest.Run(t, func(t *testing.T) storage.KeyStorage {
+		store, err := storage.NewFirestoreKeyStorage(context.Background(), "stream-engine-test", "(default)")
+		assert.Nil(t, err)
+		return store
+	})
+
+	store, err := storage.NewFirestoreKeyStorage(context.Background(), "stream-engine-test", "(default)")
+	assert.Nil(t, err)
+	defer store.Close()
+
+	t.Run("testing increment batch more than write limit", func(t *testing.T) {
+		mutations := []storage.Mutation{}
+		for i := 0; i < storage.FirestoreMaxBatchWrite+100; i++ {
+			mutations = append(mutations, storage.Mutation{
+				Path:  fmt.Sprintf("unittest_batch/%d", i),
+				Field: "count",
+				Delta: storage.Int64Value(1),
+			})
+		}
+		// same document written twice in batch
+		mutations = append(mutations, storage.Mutation{Path: "unittest_batch/0", Field: "count", Delta: storage.Int64Value(2)})
+		mutations = append(mutations, storage.Mutation{Path: "unittest_batch/0", Field: "other", Delta: storage.Int64Value(3)})
+
+		err := store.IncrementBatch(mutations)
+		assert.Nil(t, err)
+
+		value, err := store.Get("unittest_batch/0", "count")
+		assert.Nil(t, err)
+		assert.Equal(t, storage.Int64Value(3), value)
+
+		value, err = store.Get("unittest_batch/0", "other")
+		assert.Nil(t, err)
+		assert.Equal(t, storage.Int64Value(3), value)
+
+		value, err = store.Get(fmt.Sprintf("unittest_batch/%d", storage.FirestoreMaxBatchWrite+99), "count")
+		assert.Nil(t, err)
+		assert.Equal(t, storage.Int64Value(1), value)
+	})
+
+	t.Run("testing per item error", func(t *testing.T) {
+		err := store.IncrementBatch([]storage.Mutation{
+			{Path: "unittest_batch/ok", Field: "count", Delta: storage.Int64Value(1)},
+			// collection path is not a document
+			{Path: "unittest_batch", Field: "count", Delta: storage.Int64Value(1)},
+		})
+
+		batchErr, ok := err.(*storage.BatchError)
+		assert.True(t, ok)
+		assert.Len(t, batchErr.Errors, 1)
+		assert.NotNil(t, batchErr.Errors[1])
+	})
+}
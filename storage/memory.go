@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"errors"
+	"maps"
+	"sync"
+)
+
+var ErrStorageClosed = errors.New("key storage closed")
+
+// MemoryKeyStorage keep value in memory, intended for test
+type MemoryKeyStorage struct {
+	lock   sync.Mutex
+	docs   map[string]map[string]Value
+	writes int
+	closed bool
+}
+
+func NewMemoryKeyStorage() *MemoryKeyStorage {
+	return &MemoryKeyStorage{
+		docs: map[string]map[string]Value{},
+	}
+}
+
+// increment must called with lock held
+func (m *MemoryKeyStorage) increment(path string, field string, delta Value) error {
+	err := validateMutation(path, field, delta)
+	if err != nil {
+		return err
+	}
+
+	doc := m.doc(path)
+	value, ok := doc[field]
+	if ok {
+		delta, err = value.Add(delta)
+		if err != nil {
+			return err
+		}
+	}
+
+	m.writes++
+	doc[field] = delta
+	return nil
+}
+
+// Increment implements KeyStorage.
+func (m *MemoryKeyStorage) Increment(path string, field string, delta Value) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.closed {
+		return ErrStorageClosed
+	}
+
+	return m.increment(path, field, delta)
+}
+
+// IncrementBatch implements KeyStorage.
+func (m *MemoryKeyStorage) IncrementBatch(mutations []Mutation) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.closed {
+		return ErrStorageClosed
+	}
+
+	batchErr := &BatchError{Errors: map[int]error{}}
+	for i, mut := range mutations {
+		err := m.increment(mut.Path, mut.Field, mut.Delta)
+		if err != nil {
+			batchErr.Errors[i] = err
+		}
+	}
+
+	if len(batchErr.Errors) > 0 {
+		return batchErr
+	}
+	return nil
+}
+
+// Put implements KeyStorage.
+func (m *MemoryKeyStorage) Put(path string, field string, value Value) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.closed {
+		return ErrStorageClosed
+	}
+
+	err := validateMutation(path, field, value)
+	if err != nil {
+		return err
+	}
+
+	m.writes++
+	m.doc(path)[field] = value
+	return nil
+}
+
+// Get implements KeyStorage.
+func (m *MemoryKeyStorage) Get(path string, field string) (Value, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.closed {
+		return Value{}, ErrStorageClosed
+	}
+
+	value, ok := m.docs[path][field]
+	if !ok {
+		return Value{}, ErrNotFound
+	}
+	return value, nil
+}
+
+func (m *MemoryKeyStorage) Close() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.closed = true
+	return nil
+}
+
+// Document copy of all field in path, nil when path never written
+func (m *MemoryKeyStorage) Document(path string) map[string]Value {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	doc, ok := m.docs[path]
+	if !ok {
+		return nil
+	}
+	return maps.Clone(doc)
+}
+
+// Documents copy of all document keyed by path
+func (m *MemoryKeyStorage) Documents() map[string]map[string]Value {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	docs := make(map[string]map[string]Value, len(m.docs))
+	for path, doc := range m.docs {
+		docs[path] = maps.Clone(doc)
+	}
+	return docs
+}
+
+// Writes count field written, each mutation in batch counted
+func (m *MemoryKeyStorage) Writes() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.writes
+}
+
+// Reset remove all document and write count
+func (m *MemoryKeyStorage) Reset() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.docs = map[string]map[string]Value{}
+	m.writes = 0
+}
+
+func (m *MemoryKeyStorage) doc(path string) map[string]Value {
+	doc, ok := m.docs[path]
+	if !ok {
+		doc = map[string]Value{}
+		m.docs[path] = doc
+	}
+	return doc
+}
@@ -0,0 +1,58 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/storage"
+	"github.com/wargasipil/stream_engine/storage/storagetest"
+)
+
+func TestMemoryKeyStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.KeyStorage {
+		return storage.NewMemoryKeyStorage()
+	})
+
+	t.Run("testing inspection helper", func(t *testing.T) {
+		store := storage.NewMemoryKeyStorage()
+		defer store.Close()
+
+		assert.Nil(t, store.IncrementBatch([]storage.Mutation{
+			{Path: "users/1", Field: "visit", Delta: storage.Int64Value(1)},
+			{Path: "users/1", Field: "visit", Delta: storage.Int64Value(2)},
+			{Path: "users/2", Field: "visit", Delta: storage.Int64Value(5)},
+		}))
+
+		assert.Equal(t, 3, store.Writes())
+		assert.Equal(t, map[string]storage.Value{"visit": storage.Int64Value(3)}, store.Document("users/1"))
+		assert.Nil(t, store.Document("users/3"))
+		assert.Equal(t, map[string]map[string]storage.Value{
+			"users/1": {"visit": storage.Int64Value(3)},
+			"users/2": {"visit": storage.Int64Value(5)},
+		}, store.Documents())
+
+		store.Reset()
+		assert.Equal(t, 0, store.Writes())
+		assert.Empty(t, store.Documents())
+	})
+
+	t.Run("testing closed", func(t *testing.T) {
+		store := storage.NewMemoryKeyStorage()
+		store.Close()
+		assert.Equal(t, storage.ErrStorageClosed, store.Increment("users/1", "visit", storage.Int64Value(1)))
+	})
+
+	t.Run("testing kind mismatch", func(t *testing.T) {
+		store := storage.NewMemoryKeyStorage()
+		defer store.Close()
+
+		assert.Nil(t, store.Increment("users/1", "spent", storage.Float64Value(1.5)))
+		err := store.Increment("users/1", "spent", storage.Int64Value(1))
+		assert.ErrorIs(t, err, storage.ErrKindMismatch)
+
+		// put replace kind
+		assert.Nil(t, store.Put("users/1", "spent", storage.Int64Value(1)))
+		assert.Nil(t, store.Increment("users/1", "spent", storage.Int64Value(1)))
+		assert.Equal(t, storage.Int64Value(2), store.Document("users/1")["spent"])
+	})
+}
@@ -0,0 +1,230 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresConfig struct {
+	// Schema of table, default public
+	Schema string
+	// Table keep path, field and value row, default key_storage
+	Table string
+}
+
+func (cfg *PostgresConfig) tableName() string {
+	schema := cfg.Schema
+	if schema == "" {
+		schema = "public"
+	}
+	table := cfg.Table
+	if table == "" {
+		table = "key_storage"
+	}
+	return pgx.Identifier{schema, table}.Sanitize()
+}
+
+// PostgresMigrationSQL statement creating schema and table used by PostgresKeyStorage
+func PostgresMigrationSQL(cfg *PostgresConfig) []string {
+	schema := cfg.Schema
+	if schema == "" {
+		schema = "public"
+	}
+	table := cfg.tableName()
+
+	return []string{
+		fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", pgx.Identifier{schema}.Sanitize()),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	path text NOT NULL,
+	field text NOT NULL,
+	kind smallint NOT NULL DEFAULT %d,
+	value numeric NOT NULL DEFAULT 0,
+	updated_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (path, field)
+)`, table, reflect.Int64),
+	}
+}
+
+// PostgresKeyStorage keep each field of path as row, increment is upsert adding to existing value.
+// value is numeric so uint64 and float64 kept exactly, kind of row never change
+type PostgresKeyStorage struct {
+	ctx   context.Context
+	pool  *pgxpool.Pool
+	cfg   *PostgresConfig
+	table string
+}
+
+func NewPostgresKeyStorage(ctx context.Context, dsn string, cfg *PostgresConfig) (*PostgresKeyStorage, error) {
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	err = pool.Ping(ctx)
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	if cfg == nil {
+		cfg = &PostgresConfig{}
+	}
+
+	return &PostgresKeyStorage{ctx, pool, cfg, cfg.tableName()}, nil
+}
+
+// Migrate create schema and table when not exist
+func (p *PostgresKeyStorage) Migrate() error {
+	for _, query := range PostgresMigrationSQL(p.cfg) {
+		_, err := p.pool.Exec(p.ctx, query)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Increment implements KeyStorage.
+func (p *PostgresKeyStorage) Increment(path string, field string, delta Value) error {
+	err := validateMutation(path, field, delta)
+	if err != nil {
+		return err
+	}
+
+	// row with other kind not updated
+	tag, err := p.pool.Exec(p.ctx, fmt.Sprintf(`INSERT INTO %s AS t (path, field, kind, value) VALUES ($1, $2, $3, $4::numeric)
+ON CONFLICT (path, field) DO UPDATE SET value = t.value + EXCLUDED.value, updated_at = now()
+WHERE t.kind = EXCLUDED.kind`, p.table), path, field, int16(delta.Kind), delta.String())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrKindMismatch
+	}
+	return nil
+}
+
+type postgresField struct {
+	path  string
+	field string
+}
+
+// IncrementBatch implements KeyStorage.
+// mutation copied to temporary table then upserted in single transaction, so database error fail whole batch
+func (p *PostgresKeyStorage) IncrementBatch(mutations []Mutation) error {
+	batchErr := &BatchError{Errors: map[int]error{}}
+	rows := make([][]any, 0, len(mutations))
+	for i, mut := range mutations {
+		err := validateMutation(mut.Path, mut.Field, mut.Delta)
+		if err != nil {
+			batchErr.Errors[i] = err
+			continue
+		}
+		rows = append(rows, []any{mut.Path, mut.Field, int16(mut.Delta.Kind), mut.Delta.String()})
+	}
+
+	updated := map[postgresField]bool{}
+	if len(rows) > 0 {
+		err := pgx.BeginFunc(p.ctx, p.pool, func(tx pgx.Tx) error {
+			_, err := tx.Exec(p.ctx, `CREATE TEMPORARY TABLE key_storage_batch (
+	path text NOT NULL,
+	field text NOT NULL,
+	kind smallint NOT NULL,
+	value text NOT NULL
+) ON COMMIT DROP`)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.CopyFrom(p.ctx, pgx.Identifier{"key_storage_batch"}, []string{"path", "field", "kind", "value"}, pgx.CopyFromRows(rows))
+			if err != nil {
+				return err
+			}
+
+			// same row cannot be updated twice in one upsert, row with other kind not returned
+			result, err := tx.Query(p.ctx, fmt.Sprintf(`INSERT INTO %s AS t (path, field, kind, value)
+SELECT path, field, min(kind), sum(value::numeric) FROM key_storage_batch GROUP BY path, field HAVING min(kind) = max(kind)
+ON CONFLICT (path, field) DO UPDATE SET value = t.value + EXCLUDED.value, updated_at = now()
+WHERE t.kind = EXCLUDED.kind
+RETURNING path, field`, p.table))
+			if err != nil {
+				return err
+			}
+
+			var key postgresField
+			_, err = pgx.ForEachRow(result, []any{&key.path, &key.field}, func() error {
+				updated[key] = true
+				return nil
+			})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, mut := range mutations {
+		if _, ok := batchErr.Errors[i]; ok {
+			continue
+		}
+		if !updated[postgresField{mut.Path, mut.Field}] {
+			batchErr.Errors[i] = ErrKindMismatch
+		}
+	}
+
+	if len(batchErr.Errors) > 0 {
+		return batchErr
+	}
+	return nil
+}
+
+// Put implements KeyStorage.
+func (p *PostgresKeyStorage) Put(path string, field string, value Value) error {
+	err := validateMutation(path, field, value)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.pool.Exec(p.ctx, fmt.Sprintf(`INSERT INTO %s AS t (path, field, kind, value) VALUES ($1, $2, $3, $4::numeric)
+ON CONFLICT (path, field) DO UPDATE SET kind = EXCLUDED.kind, value = EXCLUDED.value, updated_at = now()`, p.table),
+		path, field, int16(value.Kind), value.String())
+	return err
+}
+
+// Get implements KeyStorage.
+func (p *PostgresKeyStorage) Get(path string, field string) (Value, error) {
+	var kind int16
+	var data string
+	err := p.pool.QueryRow(p.ctx, fmt.Sprintf("SELECT kind, value::text FROM %s WHERE path = $1 AND field = $2", p.table), path, field).Scan(&kind, &data)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Value{}, ErrNotFound
+		}
+		return Value{}, err
+	}
+
+	switch reflect.Kind(kind) {
+	case reflect.Int64:
+		value, err := strconv.ParseInt(data, 10, 64)
+		return Int64Value(value), err
+	case reflect.Uint64:
+		value, err := strconv.ParseUint(data, 10, 64)
+		return Uint64Value(value), err
+	case reflect.Float64:
+		value, err := strconv.ParseFloat(data, 64)
+		return Float64Value(value), err
+	default:
+		return Value{}, fmt.Errorf("%s field %s kind %d not supported", path, field, kind)
+	}
+}
+
+func (p *PostgresKeyStorage) Close() error {
+	p.pool.Close()
+	return nil
+}
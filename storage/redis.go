@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisPipelineSize max mutation sent in one pipeline
+const RedisPipelineSize = 1000
+
+type RedisConfig struct {
+	// Prefix prepended to path as redis hash key
+	Prefix string
+	// TTL of hash refreshed on every write, zero keep hash forever
+	TTL time.Duration
+}
+
+// RedisKeyStorage write field of path to redis hash
+type RedisKeyStorage struct {
+	ctx    context.Context
+	client *redis.Client
+	cfg    *RedisConfig
+}
+
+func NewRedisKeyStorage(ctx context.Context, opt *redis.Options, cfg *RedisConfig) (*RedisKeyStorage, error) {
+	client := redis.NewClient(opt)
+	err := client.Ping(ctx).Err()
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	if cfg == nil {
+		cfg = &RedisConfig{}
+	}
+
+	return &RedisKeyStorage{ctx, client, cfg}, nil
+}
+
+func (r *RedisKeyStorage) key(path string) string {
+	return r.cfg.Prefix + path
+}
+
+func (r *RedisKeyStorage) expire(pipe redis.Pipeliner, path string) {
+	if r.cfg.TTL > 0 {
+		pipe.Expire(r.ctx, r.key(path), r.cfg.TTL)
+	}
+}
+
+// increment queue HINCRBY for integer and HINCRBYFLOAT for float value, uint64 sent as int64 and decrease sent negative
+func (r *RedisKeyStorage) increment(pipe redis.Pipeliner, path string, field string, delta Value) (redis.Cmder, error) {
+	err := validateMutation(path, field, delta)
+	if err != nil {
+		return nil, err
+	}
+
+	switch delta.Kind {
+	case reflect.Float64:
+		return pipe.HIncrByFloat(r.ctx, r.key(path), field, delta.Float), nil
+	case reflect.Uint64:
+		if delta.Uint > math.MaxInt64 {
+			return nil, fmt.Errorf("%w: uint value %s redis integer", ErrValueOverflow, delta)
+		}
+		return pipe.HIncrBy(r.ctx, r.key(path), field, delta.Int64()), nil
+	default:
+		return pipe.HIncrBy(r.ctx, r.key(path), field, delta.Int), nil
+	}
+}
+
+// Increment implements KeyStorage.
+func (r *RedisKeyStorage) Increment(path string, field string, delta Value) error {
+	var cmd redis.Cmder
+	_, err := r.client.Pipelined(r.ctx, func(pipe redis.Pipeliner) error {
+		var err error
+		cmd, err = r.increment(pipe, path, field, delta)
+		if err != nil {
+			return err
+		}
+		r.expire(pipe, path)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return cmd.Err()
+}
+
+// IncrementBatch implements KeyStorage.
+// mutation sent pipelined in chunk of RedisPipelineSize
+func (r *RedisKeyStorage) IncrementBatch(mutations []Mutation) error {
+	batchErr := &BatchError{Errors: map[int]error{}}
+
+	for start := 0; start < len(mutations); start += RedisPipelineSize {
+		end := min(start+RedisPipelineSize, len(mutations))
+		r.writeChunk(start, mutations[start:end], batchErr)
+	}
+
+	if len(batchErr.Errors) > 0 {
+		return batchErr
+	}
+	return nil
+}
+
+func (r *RedisKeyStorage) writeChunk(start int, mutations []Mutation, batchErr *BatchError) {
+	cmds := make([]redis.Cmder, len(mutations))
+	expired := map[string]bool{}
+
+	pipe := r.client.Pipeline()
+	for i, mut := range mutations {
+		cmd, err := r.increment(pipe, mut.Path, mut.Field, mut.Delta)
+		if err != nil {
+			batchErr.Errors[start+i] = err
+			continue
+		}
+
+		cmds[i] = cmd
+		if !expired[mut.Path] {
+			expired[mut.Path] = true
+			r.expire(pipe, mut.Path)
+		}
+	}
+
+	// error of each command checked below
+	pipe.Exec(r.ctx)
+
+	for i, cmd := range cmds {
+		if cmd == nil {
+			continue
+		}
+		if err := cmd.Err(); err != nil {
+			batchErr.Errors[start+i] = err
+		}
+	}
+}
+
+// Put implements KeyStorage.
+func (r *RedisKeyStorage) Put(path string, field string, value Value) error {
+	err := validateMutation(path, field, value)
+	if err != nil {
+		return err
+	}
+
+	var cmd *redis.IntCmd
+	_, err = r.client.Pipelined(r.ctx, func(pipe redis.Pipeliner) error {
+		cmd = pipe.HSet(r.ctx, r.key(path), field, value.String())
+		r.expire(pipe, path)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return cmd.Err()
+}
+
+// Get implements KeyStorage.
+// redis not keeping kind, integer returned as int64 and other number as float64 value
+func (r *RedisKeyStorage) Get(path string, field string) (Value, error) {
+	data, err := r.client.HGet(r.ctx, r.key(path), field).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return Value{}, ErrNotFound
+		}
+		return Value{}, err
+	}
+
+	value, err := strconv.ParseInt(data, 10, 64)
+	if err == nil {
+		return Int64Value(value), nil
+	}
+
+	fvalue, err := strconv.ParseFloat(data, 64)
+	if err != nil {
+		return Value{}, fmt.Errorf("%s field %s value %q not a number", path, field, data)
+	}
+	return Float64Value(fvalue), nil
+}
+
+func (r *RedisKeyStorage) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,76 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/storage"
+	"github.com/wargasipil/stream_engine/storage/storagetest"
+)
+
+func TestRedisKeyStorage(t *testing.T) {
+	server := miniredis.RunT(t)
+	opt := &redis.Options{Addr: server.Addr()}
+
+	storagetest.Run(t, func(t *testing.T) storage.KeyStorage {
+		store, err := storage.NewRedisKeyStorage(context.Background(), opt, nil)
+		assert.Nil(t, err)
+		return store
+	})
+
+	t.Run("testing prefix and ttl", func(t *testing.T) {
+		store, err := storage.NewRedisKeyStorage(context.Background(), opt, &storage.RedisConfig{
+			Prefix: "counter:",
+			TTL:    time.Hour,
+		})
+		assert.Nil(t, err)
+		defer store.Close()
+
+		assert.Nil(t, store.IncrementBatch([]storage.Mutation{
+			{Path: "users/1", Field: "visit", Delta: storage.Int64Value(2)},
+			{Path: "users/1", Field: "visit", Delta: storage.Int64Value(3)},
+		}))
+
+		assert.Equal(t, "5", server.HGet("counter:users/1", "visit"))
+		assert.Equal(t, time.Hour, server.TTL("counter:users/1"))
+
+		server.FastForward(2 * time.Hour)
+		assert.False(t, server.Exists("counter:users/1"))
+	})
+
+	t.Run("testing increment float", func(t *testing.T) {
+		store, err := storage.NewRedisKeyStorage(context.Background(), opt, nil)
+		assert.Nil(t, err)
+		defer store.Close()
+
+		assert.Nil(t, store.Increment("users/2", "spent", storage.Float64Value(1.5)))
+		assert.Nil(t, store.Increment("users/2", "spent", storage.Float64Value(2.25)))
+		assert.Equal(t, "3.75", server.HGet("users/2", "spent"))
+
+		value, err := store.Get("users/2", "spent")
+		assert.Nil(t, err)
+		assert.Equal(t, storage.Float64Value(3.75), value)
+	})
+
+	t.Run("testing per item error on wrong type", func(t *testing.T) {
+		store, err := storage.NewRedisKeyStorage(context.Background(), opt, nil)
+		assert.Nil(t, err)
+		defer store.Close()
+
+		server.Set("users/3", "not a hash")
+		err = store.IncrementBatch([]storage.Mutation{
+			{Path: "users/4", Field: "visit", Delta: storage.Int64Value(1)},
+			{Path: "users/3", Field: "visit", Delta: storage.Int64Value(1)},
+		})
+
+		batchErr, ok := err.(*storage.BatchError)
+		assert.True(t, ok)
+		assert.Len(t, batchErr.Errors, 1)
+		assert.NotNil(t, batchErr.Errors[1])
+		assert.Equal(t, "1", server.HGet("users/4", "visit"))
+	})
+}
@@ -0,0 +1,301 @@
+package storage
+
+import (
+	"errors"
+	"log"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	wal_message "github.com/wargasipil/stream_engine/proto_core/wal_message/v1"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+type ReliableConfig struct {
+	// MaxRetry retry after first attempt, default 5
+	MaxRetry int
+	// BaseDelay backoff before first retry, doubled on every retry, default 100ms
+	BaseDelay time.Duration
+	// MaxDelay cap of backoff, default 10s
+	MaxDelay time.Duration
+	// BreakerThreshold consecutive failure opening circuit, default 5
+	BreakerThreshold int
+	// BreakerCooldown time circuit stay open before trial call, default 30s
+	BreakerCooldown time.Duration
+	// DeadLetterDir wal dir keeping undeliverable mutation
+	DeadLetterDir string
+}
+
+// ReliableKeyStorage retry failed call with exponential backoff and jitter, stop calling storage while circuit open,
+// mutation still failing is written to dead letter wal and not returned as error, so caller delta never lost
+type ReliableKeyStorage struct {
+	storage      KeyStorage
+	cfg          *ReliableConfig
+	breaker      *circuitBreaker
+	deadLetter   *stream_core.WAL
+	deadLettered atomic.Uint64
+}
+
+func NewReliableKeyStorage(storage KeyStorage, cfg *ReliableConfig) (*ReliableKeyStorage, error) {
+	if cfg.DeadLetterDir == "" {
+		return nil, errors.New("dead letter dir empty")
+	}
+
+	conf := *cfg
+	if conf.MaxRetry == 0 {
+		conf.MaxRetry = 5
+	}
+	if conf.BaseDelay == 0 {
+		conf.BaseDelay = 100 * time.Millisecond
+	}
+	if conf.MaxDelay == 0 {
+		conf.MaxDelay = 10 * time.Second
+	}
+	if conf.BreakerThreshold == 0 {
+		conf.BreakerThreshold = 5
+	}
+	if conf.BreakerCooldown == 0 {
+		conf.BreakerCooldown = 30 * time.Second
+	}
+
+	deadLetter, err := stream_core.OpenWAL(conf.DeadLetterDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReliableKeyStorage{
+		storage:    storage,
+		cfg:        &conf,
+		breaker:    newCircuitBreaker(conf.BreakerThreshold, conf.BreakerCooldown),
+		deadLetter: deadLetter,
+	}, nil
+}
+
+// backoff full jitter delay between half and whole of exponential delay
+func (r *ReliableKeyStorage) backoff(attempt int) time.Duration {
+	delay := r.cfg.MaxDelay
+	if attempt < 32 {
+		delay = min(r.cfg.BaseDelay<<attempt, r.cfg.MaxDelay)
+	}
+	half := int64(delay / 2)
+	return time.Duration(half + rand.Int64N(half+1))
+}
+
+// call run fn until succeed, permanent error or retry exhausted, return attempt made
+func (r *ReliableKeyStorage) call(fn func() error) (int, error) {
+	var err error
+	attempt := 0
+	for ; attempt <= r.cfg.MaxRetry; attempt++ {
+		if attempt > 0 {
+			time.Sleep(r.backoff(attempt - 1))
+		}
+
+		if !r.breaker.allow() {
+			return attempt, ErrCircuitOpen
+		}
+
+		err = fn()
+		if err == nil || permanentError(err) {
+			r.breaker.success()
+			return attempt + 1, err
+		}
+
+		// storage still answering when only some mutation failed, retried without counted by breaker
+		var partial *partialBatchError
+		if errors.As(err, &partial) {
+			err = partial.BatchError
+			continue
+		}
+		r.breaker.failure()
+	}
+
+	return attempt, err
+}
+
+// partialBatchError batch where some mutation applied, not counted as breaker failure
+type partialBatchError struct {
+	*BatchError
+}
+
+func (r *ReliableKeyStorage) writeDeadLetter(op wal_message.DeadLetterOp, path string, field string, value Value, attempt int, cause error) error {
+	err := r.deadLetter.Append(&wal_message.DeadLetter{
+		Op:         op,
+		Path:       path,
+		Field:      field,
+		Value:      value.Int,
+		FailedAt:   time.Now().UnixMilli(),
+		Attempt:    uint32(attempt),
+		Error:      cause.Error(),
+		Kind:       uint32(value.Kind),
+		UintValue:  value.Uint,
+		FloatValue: value.Float,
+		Negative:   value.Neg,
+	})
+	if err != nil {
+		return err
+	}
+
+	r.deadLettered.Add(1)
+	return nil
+}
+
+// Increment implements KeyStorage.
+func (r *ReliableKeyStorage) Increment(path string, field string, delta Value) error {
+	attempt, err := r.call(func() error {
+		return r.storage.Increment(path, field, delta)
+	})
+	if err == nil || permanentError(err) {
+		return err
+	}
+
+	log.Printf("increment %s %s dead lettered: %v", path, field, err)
+	return r.writeDeadLetter(wal_message.DeadLetterOp_DEAD_LETTER_OP_INCREMENT, path, field, delta, attempt, err)
+}
+
+// IncrementBatch implements KeyStorage.
+// only failed mutation retried, returned BatchError only contain permanent error
+func (r *ReliableKeyStorage) IncrementBatch(mutations []Mutation) error {
+	failed := map[int]error{}
+	permanent := map[int]error{}
+
+	pending := make([]int, 0, len(mutations))
+	for i, mut := range mutations {
+		err := validateMutation(mut.Path, mut.Field, mut.Delta)
+		if err != nil {
+			permanent[i] = err
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	// some mutation applied or rejected permanently, storage still answering
+	answered := false
+	attempt, err := r.call(func() error {
+		batch := make([]Mutation, len(pending))
+		for i, index := range pending {
+			batch[i] = mutations[index]
+		}
+
+		err := r.storage.IncrementBatch(batch)
+		if err == nil {
+			pending = pending[:0]
+			return nil
+		}
+
+		var batchErr *BatchError
+		if !errors.As(err, &batchErr) {
+			for _, index := range pending {
+				failed[index] = err
+			}
+			return err
+		}
+
+		retry := pending[:0]
+		for i, index := range pending {
+			itemErr, ok := batchErr.Errors[i]
+			switch {
+			case !ok:
+				delete(failed, index)
+				answered = true
+			case permanentError(itemErr):
+				delete(failed, index)
+				permanent[index] = itemErr
+				answered = true
+			default:
+				failed[index] = itemErr
+				retry = append(retry, index)
+			}
+		}
+		pending = retry
+
+		if len(pending) > 0 && answered {
+			return &partialBatchError{batchErr}
+		}
+		if len(pending) > 0 {
+			return batchErr
+		}
+		return nil
+	})
+
+	if permanentError(err) {
+		return err
+	}
+
+	if err != nil {
+		// circuit open before call, mutation still pending never got error from storage
+		for _, index := range pending {
+			if _, ok := failed[index]; !ok {
+				failed[index] = err
+			}
+		}
+
+		log.Printf("increment batch %d mutation dead lettered: %v", len(pending), err)
+		for _, index := range pending {
+			mut := mutations[index]
+			err := r.writeDeadLetter(wal_message.DeadLetterOp_DEAD_LETTER_OP_INCREMENT, mut.Path, mut.Field, mut.Delta, attempt, failed[index])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(permanent) > 0 {
+		return &BatchError{Errors: permanent}
+	}
+	return nil
+}
+
+// Put implements KeyStorage.
+func (r *ReliableKeyStorage) Put(path string, field string, value Value) error {
+	attempt, err := r.call(func() error {
+		return r.storage.Put(path, field, value)
+	})
+	if err == nil || permanentError(err) {
+		return err
+	}
+
+	log.Printf("put %s %s dead lettered: %v", path, field, err)
+	return r.writeDeadLetter(wal_message.DeadLetterOp_DEAD_LETTER_OP_PUT, path, field, value, attempt, err)
+}
+
+// Get implements KeyStorage.
+func (r *ReliableKeyStorage) Get(path string, field string) (Value, error) {
+	var value Value
+	var notFound bool
+	_, err := r.call(func() error {
+		var err error
+		value, err = r.storage.Get(path, field)
+		// not found is answer from healthy storage
+		notFound = errors.Is(err, ErrNotFound)
+		if notFound {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return Value{}, err
+	}
+	if notFound {
+		return Value{}, ErrNotFound
+	}
+	return value, nil
+}
+
+// DeadLettered count mutation written to dead letter since storage created
+func (r *ReliableKeyStorage) DeadLettered() uint64 {
+	return r.deadLettered.Load()
+}
+
+// CircuitOpen true while storage call rejected by circuit breaker
+func (r *ReliableKeyStorage) CircuitOpen() bool {
+	return r.breaker.open()
+}
+
+func (r *ReliableKeyStorage) Close() error {
+	err := r.deadLetter.Close()
+	if err != nil {
+		r.storage.Close()
+		return err
+	}
+	return r.storage.Close()
+}
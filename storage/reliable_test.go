@@ -0,0 +1,193 @@
+package storage_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/storage"
+)
+
+// flakyStorage fail next failures call before reaching memory storage
+type flakyStorage struct {
+	*storage.MemoryKeyStorage
+	failures int
+	calls    int
+}
+
+func (f *flakyStorage) fail() error {
+	f.calls++
+	if f.failures != 0 {
+		f.failures--
+		return errors.New("storage unavailable")
+	}
+	return nil
+}
+
+func (f *flakyStorage) Increment(path string, field string, delta storage.Value) error {
+	if err := f.fail(); err != nil {
+		return err
+	}
+	return f.MemoryKeyStorage.Increment(path, field, delta)
+}
+
+func (f *flakyStorage) IncrementBatch(mutations []storage.Mutation) error {
+	if err := f.fail(); err != nil {
+		return err
+	}
+	return f.MemoryKeyStorage.IncrementBatch(mutations)
+}
+
+func (f *flakyStorage) Put(path string, field string, value storage.Value) error {
+	if err := f.fail(); err != nil {
+		return err
+	}
+	return f.MemoryKeyStorage.Put(path, field, value)
+}
+
+// lockedRowStorage fail mutation of locked field inside batch, other mutation applied
+type lockedRowStorage struct {
+	*storage.MemoryKeyStorage
+}
+
+func (l *lockedRowStorage) IncrementBatch(mutations []storage.Mutation) error {
+	batchErr := &storage.BatchError{Errors: map[int]error{}}
+	for i, mut := range mutations {
+		if mut.Field == "locked" {
+			batchErr.Errors[i] = errors.New("row locked")
+			continue
+		}
+		err := l.MemoryKeyStorage.Increment(mut.Path, mut.Field, mut.Delta)
+		if err != nil {
+			batchErr.Errors[i] = err
+		}
+	}
+	if len(batchErr.Errors) > 0 {
+		return batchErr
+	}
+	return nil
+}
+
+func TestReliableKeyStorageBreakerBatchError(t *testing.T) {
+	dir := "/tmp/stream_engine/dead_letter_breaker_unittest"
+	os.RemoveAll(dir)
+
+	locked := &lockedRowStorage{MemoryKeyStorage: storage.NewMemoryKeyStorage()}
+	store, err := storage.NewReliableKeyStorage(locked, &storage.ReliableConfig{
+		MaxRetry:         3,
+		BaseDelay:        time.Millisecond,
+		MaxDelay:         time.Millisecond * 4,
+		BreakerThreshold: 3,
+		BreakerCooldown:  time.Minute,
+		DeadLetterDir:    dir,
+	})
+	assert.Nil(t, err)
+	defer store.Close()
+
+	t.Run("testing partial batch failure keep circuit closed", func(t *testing.T) {
+		assert.Nil(t, store.IncrementBatch([]storage.Mutation{
+			{Path: "users/1", Field: "visit", Delta: storage.Int64Value(1)},
+			{Path: "users/1", Field: "locked", Delta: storage.Int64Value(1)},
+		}))
+		assert.False(t, store.CircuitOpen())
+		assert.Equal(t, uint64(1), store.DeadLettered())
+		assert.Equal(t, map[string]storage.Value{"visit": storage.Int64Value(1)}, locked.Document("users/1"))
+	})
+
+	t.Run("testing every mutation failed open circuit", func(t *testing.T) {
+		assert.Nil(t, store.IncrementBatch([]storage.Mutation{
+			{Path: "users/2", Field: "locked", Delta: storage.Int64Value(1)},
+		}))
+		assert.True(t, store.CircuitOpen())
+		assert.Equal(t, uint64(2), store.DeadLettered())
+	})
+}
+
+func TestReliableKeyStorage(t *testing.T) {
+	dir := "/tmp/stream_engine/dead_letter_unittest"
+	os.RemoveAll(dir)
+	os.RemoveAll(dir + ".redrive")
+
+	flaky := &flakyStorage{MemoryKeyStorage: storage.NewMemoryKeyStorage()}
+	store, err := storage.NewReliableKeyStorage(flaky, &storage.ReliableConfig{
+		MaxRetry:         2,
+		BaseDelay:        time.Millisecond,
+		MaxDelay:         time.Millisecond * 4,
+		BreakerThreshold: 3,
+		BreakerCooldown:  time.Millisecond * 50,
+		DeadLetterDir:    dir,
+	})
+	assert.Nil(t, err)
+
+	t.Run("testing retry until success", func(t *testing.T) {
+		flaky.failures = 2
+		assert.Nil(t, store.Increment("users/1", "visit", storage.Int64Value(1)))
+		assert.Equal(t, map[string]storage.Value{"visit": storage.Int64Value(1)}, flaky.Document("users/1"))
+		assert.Equal(t, uint64(0), store.DeadLettered())
+	})
+
+	t.Run("testing dead letter when retry exhausted", func(t *testing.T) {
+		flaky.failures = 3
+		assert.Nil(t, store.Increment("users/1", "visit", storage.Int64Value(2)))
+		assert.Equal(t, uint64(1), store.DeadLettered())
+		assert.True(t, store.CircuitOpen())
+	})
+
+	t.Run("testing open circuit skip storage", func(t *testing.T) {
+		calls := flaky.calls
+		err := store.IncrementBatch([]storage.Mutation{
+			{Path: "users/2", Field: "visit", Delta: storage.Float64Value(3.5)},
+			{Path: "users/2", Field: "", Delta: storage.Int64Value(3)},
+		})
+		assert.IsType(t, &storage.BatchError{}, err)
+		assert.Equal(t, calls, flaky.calls)
+		assert.Equal(t, uint64(2), store.DeadLettered())
+
+		_, err = store.Get("users/1", "visit")
+		assert.Equal(t, storage.ErrCircuitOpen, err)
+	})
+
+	t.Run("testing circuit closed after cooldown", func(t *testing.T) {
+		time.Sleep(time.Millisecond * 60)
+		assert.Nil(t, store.Put("users/3", "level", storage.Float64Value(7.5)))
+		assert.False(t, store.CircuitOpen())
+
+		err := store.IncrementBatch([]storage.Mutation{
+			{Path: "users/3", Field: "visit", Delta: storage.Int64Value(1)},
+			{Path: "users/3", Field: "", Delta: storage.Int64Value(1)},
+		})
+		batchErr, ok := err.(*storage.BatchError)
+		assert.True(t, ok)
+		assert.ErrorIs(t, batchErr.Errors[1], storage.ErrEmptyKey)
+		assert.Equal(t, uint64(2), store.DeadLettered())
+	})
+
+	assert.Nil(t, store.Close())
+
+	t.Run("testing redrive dead letter", func(t *testing.T) {
+		target := &flakyStorage{MemoryKeyStorage: storage.NewMemoryKeyStorage(), failures: 2}
+		target.MemoryKeyStorage.Increment("users/1", "visit", storage.Int64Value(1))
+
+		// storage still failing, all written back to dead letter
+		result, err := storage.RedriveDeadLetter(dir, target)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, result.Delivered)
+		assert.Equal(t, 2, result.Failed)
+
+		result, err = storage.RedriveDeadLetter(dir, target)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, result.Delivered)
+		assert.Equal(t, 0, result.Failed)
+		assert.Equal(t, map[string]storage.Value{"visit": storage.Int64Value(3)}, target.Document("users/1"))
+		assert.Equal(t, map[string]storage.Value{"visit": storage.Float64Value(3.5)}, target.Document("users/2"))
+
+		_, err = os.Stat(dir + ".redrive")
+		assert.True(t, os.IsNotExist(err))
+
+		result, err = storage.RedriveDeadLetter(dir, target)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, result.Delivered+result.Failed)
+	})
+}
@@ -0,0 +1,199 @@
+// Package storagetest conformance test shared by every KeyStorage implementation
+package storagetest
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/storage"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+// Factory create storage used by one subtest, storage is closed by suite.
+// storage shared across run like emulator keep working, every run write under unique path
+type Factory func(t *testing.T) storage.KeyStorage
+
+// Run conformance test against storage created by factory.
+// storage may not keep uint64 kind, so integer value compared as int64
+func Run(t *testing.T, factory Factory) {
+	prefix := fmt.Sprintf("storagetest_%d", time.Now().UnixNano())
+	path := func(name string) string {
+		return prefix + "/" + name
+	}
+
+	run := func(name string, test func(t *testing.T, store storage.KeyStorage)) {
+		t.Run(name, func(t *testing.T) {
+			store := factory(t)
+			defer store.Close()
+			test(t, store)
+		})
+	}
+
+	run("testing get missing", func(t *testing.T, store storage.KeyStorage) {
+		_, err := store.Get(path("missing"), "count")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+
+		assert.Nil(t, store.Put(path("missing_field"), "count", storage.Int64Value(1)))
+		_, err = store.Get(path("missing_field"), "other")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	run("testing put replace value", func(t *testing.T, store storage.KeyStorage) {
+		assert.Nil(t, store.Put(path("put"), "count", storage.Int64Value(10)))
+		assert.Nil(t, store.Put(path("put"), "count", storage.Int64Value(-4)))
+		assert.Nil(t, store.Put(path("put"), "balance", storage.Float64Value(10.25)))
+
+		value, err := store.Get(path("put"), "count")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(-4), value.Int64())
+
+		value, err = store.Get(path("put"), "balance")
+		assert.Nil(t, err)
+		assert.Equal(t, 10.25, value.Float64())
+	})
+
+	run("testing increment", func(t *testing.T, store storage.KeyStorage) {
+		assert.Nil(t, store.Increment(path("inc"), "count", storage.Int64Value(5)))
+		assert.Nil(t, store.Increment(path("inc"), "count", storage.Int64Value(-2)))
+		assert.Nil(t, store.Increment(path("inc"), "other", storage.Int64Value(7)))
+
+		value, err := store.Get(path("inc"), "count")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), value.Int64())
+
+		value, err = store.Get(path("inc"), "other")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(7), value.Int64())
+	})
+
+	run("testing increment typed", func(t *testing.T, store storage.KeyStorage) {
+		assert.Nil(t, store.Increment(path("typed"), "visit", storage.Uint64Value(5)))
+		assert.Nil(t, store.Increment(path("typed"), "visit", storage.Uint64Value(6)))
+		assert.Nil(t, store.Increment(path("typed"), "balance", storage.Float64Value(1.5)))
+		assert.Nil(t, store.Increment(path("typed"), "balance", storage.Float64Value(2.25)))
+
+		value, err := store.Get(path("typed"), "visit")
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(11), value.Uint64())
+
+		value, err = store.Get(path("typed"), "balance")
+		assert.Nil(t, err)
+		assert.Equal(t, 3.75, value.Float64())
+	})
+
+	run("testing increment after put", func(t *testing.T, store storage.KeyStorage) {
+		assert.Nil(t, store.Put(path("inc_put"), "count", storage.Int64Value(100)))
+		assert.Nil(t, store.Increment(path("inc_put"), "count", storage.Int64Value(1)))
+
+		value, err := store.Get(path("inc_put"), "count")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(101), value.Int64())
+	})
+
+	run("testing increment batch", func(t *testing.T, store storage.KeyStorage) {
+		err := store.IncrementBatch([]storage.Mutation{
+			{Path: path("batch_a"), Field: "count", Delta: storage.Int64Value(1)},
+			{Path: path("batch_b"), Field: "count", Delta: storage.Int64Value(2)},
+			{Path: path("batch_a"), Field: "count", Delta: storage.Int64Value(3)},
+			{Path: path("batch_a"), Field: "other", Delta: storage.Int64Value(-1)},
+			{Path: path("batch_a"), Field: "balance", Delta: storage.Float64Value(0.5)},
+			{Path: path("batch_a"), Field: "balance", Delta: storage.Float64Value(0.25)},
+		})
+		assert.Nil(t, err)
+
+		expected := map[string]float64{
+			path("batch_a") + "#count":   4,
+			path("batch_b") + "#count":   2,
+			path("batch_a") + "#other":   -1,
+			path("batch_a") + "#balance": 0.75,
+		}
+		assert.Equal(t, expected, getAll(t, store, expected))
+
+		assert.Nil(t, store.IncrementBatch(nil))
+	})
+
+	run("testing increment batch per item error", func(t *testing.T, store storage.KeyStorage) {
+		err := store.IncrementBatch([]storage.Mutation{
+			{Path: path("batch_err"), Field: "count", Delta: storage.Int64Value(1)},
+			{Path: path("batch_err"), Field: "", Delta: storage.Int64Value(1)},
+			{Path: "", Field: "count", Delta: storage.Int64Value(1)},
+			{Path: path("batch_err"), Field: "other", Delta: storage.Value{}},
+		})
+
+		var batchErr *storage.BatchError
+		assert.True(t, errors.As(err, &batchErr))
+		if batchErr != nil {
+			assert.Len(t, batchErr.Errors, 3)
+			assert.ErrorIs(t, batchErr.Errors[1], storage.ErrEmptyKey)
+			assert.ErrorIs(t, batchErr.Errors[2], storage.ErrEmptyKey)
+			assert.ErrorIs(t, batchErr.Errors[3], storage.ErrInvalidKind)
+		}
+
+		value, err := store.Get(path("batch_err"), "count")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), value.Int64())
+	})
+
+	run("testing sync uint counter going down", func(t *testing.T, store storage.KeyStorage) {
+		dir := t.TempDir()
+		kv, err := stream_core.NewHashMapCounter(&stream_core.CoreConfig{
+			HashMapCounterPath:  filepath.Join(dir, "counter"),
+			HashMapCounterSlots: 1024,
+			DynamicValuePath:    filepath.Join(dir, "dynamic_value"),
+		})
+		assert.Nil(t, err)
+		defer kv.Close()
+
+		syncer, err := storage.NewSyncer(kv, store, &storage.SyncConfig{StateDir: filepath.Join(dir, "state")})
+		assert.Nil(t, err)
+		defer syncer.Close()
+
+		key := path("sync_uint") + "/visit"
+		kv.IncUint64(key, 10)
+		_, err = syncer.SyncOnce()
+		assert.Nil(t, err)
+
+		assert.Nil(t, kv.ResetCounter())
+		time.Sleep(2 * time.Millisecond)
+		kv.IncUint64(key, 3)
+		result, err := syncer.SyncOnce()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, result.Pushed)
+
+		value, err := store.Get(path("sync_uint"), "visit")
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(3), value.Uint64())
+
+		// decrease sent by single increment too
+		delta, err := storage.Uint64Value(1).Sub(storage.Uint64Value(3))
+		assert.Nil(t, err)
+		assert.Nil(t, store.Increment(path("sync_uint"), "visit", delta))
+		value, err = store.Get(path("sync_uint"), "visit")
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), value.Uint64())
+	})
+
+	run("testing invalid key rejected", func(t *testing.T, store storage.KeyStorage) {
+		assert.ErrorIs(t, store.Increment(path("empty"), "", storage.Int64Value(1)), storage.ErrEmptyKey)
+		assert.ErrorIs(t, store.Put("", "count", storage.Int64Value(1)), storage.ErrEmptyKey)
+		assert.ErrorIs(t, store.Put(path("empty"), "count", storage.Value{}), storage.ErrInvalidKind)
+	})
+}
+
+func getAll(t *testing.T, store storage.KeyStorage, keys map[string]float64) map[string]float64 {
+	values := map[string]float64{}
+	for key := range keys {
+		i := strings.LastIndex(key, "#")
+		path, field := key[:i], key[i+1:]
+
+		value, err := store.Get(path, field)
+		assert.Nil(t, err, key)
+		values[key] = value.Float64()
+	}
+	return values
+}
@@ -1,6 +1,68 @@
 package storage
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var ErrNotFound = errors.New("key storage field not found")
+var ErrEmptyKey = errors.New("key storage path and field must not empty")
+
+type Mutation struct {
+	Path  string
+	Field string
+	Delta Value
+}
+
+// BatchError error of each failed mutation in batch, keyed by mutation index
+type BatchError struct {
+	Errors map[int]error
+}
+
+func (e *BatchError) Error() string {
+	indexes := make([]int, 0, len(e.Errors))
+	for i := range e.Errors {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	msgs := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		msgs = append(msgs, fmt.Sprintf("mutation %d: %v", i, e.Errors[i]))
+	}
+	return fmt.Sprintf("%d mutation failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
 type KeyStorage interface {
-	Increment(path string, field string, delta int64) error
+	// Increment add typed delta to field, storage keeping kind return ErrKindMismatch when field has other kind
+	Increment(path string, field string, delta Value) error
+	// IncrementBatch apply all mutation, return *BatchError when some mutation failed
+	IncrementBatch(mutations []Mutation) error
+	// Put replace field value, used for key created by Put in counter
+	Put(path string, field string, value Value) error
+	// Get return ErrNotFound when field not exist
+	Get(path string, field string) (Value, error)
 	Close() error
 }
+
+func validateKey(path string, field string) error {
+	if path == "" || field == "" {
+		return ErrEmptyKey
+	}
+	return nil
+}
+
+func validateMutation(path string, field string, value Value) error {
+	err := validateKey(path, field)
+	if err != nil {
+		return err
+	}
+	return validateValue(value)
+}
+
+// permanentError error that never succeed on retry
+func permanentError(err error) bool {
+	return errors.Is(err, ErrEmptyKey) || errors.Is(err, ErrInvalidKind) || errors.Is(err, ErrKindMismatch) || errors.Is(err, ErrValueOverflow)
+}
@@ -0,0 +1,249 @@
+package storage
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+type SyncConfig struct {
+	// StateDir keep last synced value and watermark
+	StateDir string
+	Interval time.Duration
+	// only sync key with prefix, empty sync all key
+	Prefix string
+}
+
+type SyncResult struct {
+	Scanned int
+	Pushed  int
+	Skipped int
+}
+
+// Syncer push delta between counter and last synced value to KeyStorage.
+// last synced value is kept in its own counter with same slot as source counter,
+// delta of key is pushed before its synced value updated, so only key in flight when process crash can be pushed twice
+type Syncer struct {
+	hm        *stream_core.HashMapCounter
+	storage   KeyStorage
+	synced    *stream_core.HashMapCounter
+	cfg       *SyncConfig
+	watermark time.Time
+}
+
+func NewSyncer(hm *stream_core.HashMapCounter, storage KeyStorage, cfg *SyncConfig) (*Syncer, error) {
+	err := os.MkdirAll(cfg.StateDir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	synced, err := stream_core.NewHashMapCounter(&stream_core.CoreConfig{
+		HashMapCounterPath:  filepath.Join(cfg.StateDir, "synced_counter"),
+		HashMapCounterSlots: hm.Slots(),
+		DynamicValuePath:    filepath.Join(cfg.StateDir, "synced_dynamic_value"),
+		IndexPath:           filepath.Join(cfg.StateDir, "synced_key_index"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	watermark, err := readWatermark(filepath.Join(cfg.StateDir, "watermark"))
+	if err != nil {
+		synced.Close()
+		return nil, err
+	}
+
+	return &Syncer{
+		hm:        hm,
+		storage:   storage,
+		synced:    synced,
+		cfg:       cfg,
+		watermark: watermark,
+	}, nil
+}
+
+// SyncBatchSize max mutation pushed in one IncrementBatch
+const SyncBatchSize = 500
+
+type pendingSync struct {
+	key   string
+	value Value
+}
+
+// SyncOnce push delta of key updated since last sync, key created by Put pushed as Put of current value
+func (s *Syncer) SyncOnce() (*SyncResult, error) {
+	view := s.hm.OpenSnapshot()
+	defer view.Close()
+
+	result := &SyncResult{}
+	mutations := make([]Mutation, 0, SyncBatchSize)
+	pendings := make([]pendingSync, 0, SyncBatchSize)
+
+	flush := func() error {
+		if len(mutations) == 0 {
+			return nil
+		}
+
+		err := s.storage.IncrementBatch(mutations)
+		var batchErr *BatchError
+		if err != nil && !errors.As(err, &batchErr) {
+			return err
+		}
+
+		// only mutation succeeded marked as synced, failed one pushed again next sync
+		for i, pending := range pendings {
+			if batchErr != nil && batchErr.Errors[i] != nil {
+				continue
+			}
+
+			s.markSynced(pending.key, pending.value)
+			result.Pushed++
+		}
+
+		mutations = mutations[:0]
+		pendings = pendings[:0]
+		return err
+	}
+
+	err := view.Iterate(s.watermark, func(rec *stream_core.KeyRecord) error {
+		if !strings.HasPrefix(rec.Key, s.cfg.Prefix) {
+			return nil
+		}
+		result.Scanned++
+
+		field, path := stream_core.CounterKey(rec.Key).CounterName()
+		if path == "" {
+			result.Skipped++
+			return nil
+		}
+
+		current, err := ValueOf(rec.Kind, rec.Value)
+		if err != nil {
+			result.Skipped++
+			return nil
+		}
+
+		// updated since last sync, replaced value pushed as is
+		if rec.Replace {
+			err = s.storage.Put(path, field, current)
+			if err != nil {
+				return err
+			}
+			s.markSynced(rec.Key, current)
+			result.Pushed++
+			return nil
+		}
+
+		delta, err := current.Sub(s.syncedValue(rec.Kind, rec.Key))
+		if err != nil {
+			return err
+		}
+		if delta.IsZero() {
+			return nil
+		}
+
+		mutations = append(mutations, Mutation{
+			Path:  path,
+			Field: field,
+			Delta: delta,
+		})
+		pendings = append(pendings, pendingSync{rec.Key, current})
+		if len(mutations) == SyncBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return result, err
+	}
+
+	// key updated after view opened have timestamp not before view created
+	s.watermark = view.CreatedAt()
+	return result, writeWatermark(filepath.Join(s.cfg.StateDir, "watermark"), s.watermark)
+}
+
+func (s *Syncer) syncedValue(kind reflect.Kind, key string) Value {
+	switch kind {
+	case reflect.Uint64:
+		return Uint64Value(s.synced.GetUint64(key))
+	case reflect.Float64:
+		return Float64Value(s.synced.GetFloat64(key))
+	default:
+		return Int64Value(s.synced.GetInt64(key))
+	}
+}
+
+func (s *Syncer) markSynced(key string, value Value) {
+	switch value.Kind {
+	case reflect.Uint64:
+		s.synced.PutUint64(key, value.Uint)
+	case reflect.Float64:
+		s.synced.PutFloat64(key, value.Float)
+	default:
+		s.synced.PutInt64(key, value.Int)
+	}
+}
+
+// Run sync every interval until context canceled, failed sync retried next interval
+func (s *Syncer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			result, err := s.SyncOnce()
+			if err != nil {
+				log.Printf("sync failed: %v", err)
+				continue
+			}
+			log.Printf("sync scanned %d pushed %d skipped %d", result.Scanned, result.Pushed, result.Skipped)
+		}
+	}
+}
+
+func (s *Syncer) Close() error {
+	return s.synced.Close()
+}
+
+func readWatermark(path string) (time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+
+	if len(data) != 8 {
+		return time.Time{}, errors.New("sync watermark corrupted")
+	}
+
+	return time.UnixMilli(int64(binary.LittleEndian.Uint64(data))), nil
+}
+
+func writeWatermark(path string, t time.Time) error {
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, uint64(t.UnixMilli()))
+
+	tmpPath := path + ".tmp"
+	err := os.WriteFile(tmpPath, data, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
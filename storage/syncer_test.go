@@ -0,0 +1,116 @@
+package storage_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/storage"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+// failingStorage fail every write while fail set
+type failingStorage struct {
+	*storage.MemoryKeyStorage
+	fail bool
+}
+
+func (f *failingStorage) IncrementBatch(mutations []storage.Mutation) error {
+	if f.fail {
+		return errors.New("storage unavailable")
+	}
+	return f.MemoryKeyStorage.IncrementBatch(mutations)
+}
+
+func (f *failingStorage) Put(path string, field string, value storage.Value) error {
+	if f.fail {
+		return errors.New("storage unavailable")
+	}
+	return f.MemoryKeyStorage.Put(path, field, value)
+}
+
+func TestSyncer(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/syncer_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/syncer_value_unittest",
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+
+	stateDir := "/tmp/stream_engine/syncer_state_unittest"
+	os.RemoveAll(stateDir)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer kv.Close()
+
+	store := &failingStorage{MemoryKeyStorage: storage.NewMemoryKeyStorage()}
+	syncCfg := &storage.SyncConfig{StateDir: stateDir}
+
+	syncer, err := storage.NewSyncer(kv, store, syncCfg)
+	assert.Nil(t, err)
+
+	kv.IncInt64("users/1/order_count", 2)
+	kv.IncUint64("users/1/visit", 5)
+	kv.IncFloat64("users/1/spent", 1.5)
+	kv.PutFloat64("users/1/balance", 100)
+	kv.IncInt64("order_total", 1)
+
+	t.Run("testing push delta", func(t *testing.T) {
+		result, err := syncer.SyncOnce()
+		assert.Nil(t, err)
+		assert.Equal(t, 4, result.Pushed)
+		assert.Equal(t, 1, result.Skipped)
+		assert.Equal(t, map[string]storage.Value{
+			"order_count": storage.Int64Value(2),
+			"visit":       storage.Uint64Value(5),
+			"spent":       storage.Float64Value(1.5),
+			"balance":     storage.Float64Value(100),
+		}, store.Document("users/1"))
+
+		kv.IncInt64("users/1/order_count", -3)
+		kv.IncFloat64("users/1/spent", 0.25)
+		kv.PutFloat64("users/1/balance", 80)
+		result, err = syncer.SyncOnce()
+		assert.Nil(t, err)
+		assert.Equal(t, 3, result.Pushed)
+
+		doc := store.Document("users/1")
+		assert.Equal(t, storage.Int64Value(-1), doc["order_count"])
+		assert.Equal(t, storage.Float64Value(1.75), doc["spent"])
+		assert.Equal(t, storage.Float64Value(80), doc["balance"])
+	})
+
+	t.Run("testing failed push retried", func(t *testing.T) {
+		kv.IncInt64("users/1/order_count", 10)
+		store.fail = true
+		_, err := syncer.SyncOnce()
+		assert.NotNil(t, err)
+
+		store.fail = false
+		_, err = syncer.SyncOnce()
+		assert.Nil(t, err)
+		assert.Equal(t, storage.Int64Value(9), store.Document("users/1")["order_count"])
+	})
+
+	t.Run("testing restart not double count", func(t *testing.T) {
+		assert.Nil(t, syncer.Close())
+
+		kv.IncUint64("users/1/visit", 1)
+
+		syncer, err := storage.NewSyncer(kv, store, syncCfg)
+		assert.Nil(t, err)
+		defer syncer.Close()
+
+		_, err = syncer.SyncOnce()
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]storage.Value{
+			"order_count": storage.Int64Value(9),
+			"visit":       storage.Uint64Value(6),
+			"spent":       storage.Float64Value(1.75),
+			"balance":     storage.Float64Value(80),
+		}, store.Document("users/1"))
+	})
+}
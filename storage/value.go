@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+var ErrKindMismatch = errors.New("key storage value kind mismatch")
+var ErrInvalidKind = errors.New("key storage value kind not supported")
+var ErrValueOverflow = errors.New("key storage value overflow")
+
+// Value typed counter value, Kind mirror reflect.Kind of counter slot and is one of Int64, Uint64 or Float64
+type Value struct {
+	Kind  reflect.Kind
+	Int   int64
+	Uint  uint64
+	Float float64
+	// Neg uint64 delta decrease counter by Uint, set by Sub when counter went down
+	Neg bool
+}
+
+func Int64Value(v int64) Value {
+	return Value{Kind: reflect.Int64, Int: v}
+}
+
+func Uint64Value(v uint64) Value {
+	return Value{Kind: reflect.Uint64, Uint: v}
+}
+
+func Float64Value(v float64) Value {
+	return Value{Kind: reflect.Float64, Float: v}
+}
+
+// ValueOf convert counter value of kind
+func ValueOf(kind reflect.Kind, value any) (Value, error) {
+	switch val := value.(type) {
+	case int64:
+		if kind == reflect.Int64 {
+			return Int64Value(val), nil
+		}
+	case uint64:
+		if kind == reflect.Uint64 {
+			return Uint64Value(val), nil
+		}
+	case float64:
+		if kind == reflect.Float64 {
+			return Float64Value(val), nil
+		}
+	}
+	return Value{}, fmt.Errorf("%w: kind %s value %T", ErrKindMismatch, kind, value)
+}
+
+// Any value as int64, uint64 or float64 following kind
+func (v Value) Any() any {
+	switch v.Kind {
+	case reflect.Uint64:
+		return v.Uint64()
+	case reflect.Float64:
+		return v.Float
+	default:
+		return v.Int
+	}
+}
+
+// Int64 value converted to int64
+func (v Value) Int64() int64 {
+	switch v.Kind {
+	case reflect.Uint64:
+		if v.Neg {
+			return -int64(v.Uint)
+		}
+		return int64(v.Uint)
+	case reflect.Float64:
+		return int64(v.Float)
+	default:
+		return v.Int
+	}
+}
+
+// Uint64 value converted to uint64, negative delta wrapped
+func (v Value) Uint64() uint64 {
+	switch v.Kind {
+	case reflect.Int64:
+		return uint64(v.Int)
+	case reflect.Float64:
+		return uint64(v.Float)
+	default:
+		if v.Neg {
+			return -v.Uint
+		}
+		return v.Uint
+	}
+}
+
+// Float64 value converted to float64
+func (v Value) Float64() float64 {
+	switch v.Kind {
+	case reflect.Int64:
+		return float64(v.Int)
+	case reflect.Uint64:
+		if v.Neg {
+			return -float64(v.Uint)
+		}
+		return float64(v.Uint)
+	default:
+		return v.Float
+	}
+}
+
+func (v Value) IsZero() bool {
+	switch v.Kind {
+	case reflect.Int64:
+		return v.Int == 0
+	case reflect.Uint64:
+		return v.Uint == 0
+	case reflect.Float64:
+		return v.Float == 0
+	default:
+		return true
+	}
+}
+
+// Sub value minus other value with same kind
+func (v Value) Sub(other Value) (Value, error) {
+	if v.Kind != other.Kind {
+		return Value{}, fmt.Errorf("%w: %s and %s", ErrKindMismatch, v.Kind, other.Kind)
+	}
+
+	switch v.Kind {
+	case reflect.Int64:
+		return Int64Value(v.Int - other.Int), nil
+	case reflect.Uint64:
+		other.Neg = !other.Neg
+		return addUint(v, other), nil
+	default:
+		return Float64Value(v.Float - other.Float), nil
+	}
+}
+
+// Add value plus other value with same kind
+func (v Value) Add(other Value) (Value, error) {
+	if v.Kind != other.Kind {
+		return Value{}, fmt.Errorf("%w: %s and %s", ErrKindMismatch, v.Kind, other.Kind)
+	}
+
+	switch v.Kind {
+	case reflect.Int64:
+		return Int64Value(v.Int + other.Int), nil
+	case reflect.Uint64:
+		return addUint(v, other), nil
+	default:
+		return Float64Value(v.Float + other.Float), nil
+	}
+}
+
+// addUint add uint64 value as sign and magnitude, so decrease not wrapped to huge delta
+func addUint(v Value, other Value) Value {
+	if v.Neg == other.Neg {
+		return Value{Kind: reflect.Uint64, Uint: v.Uint + other.Uint, Neg: v.Neg && v.Uint+other.Uint != 0}
+	}
+	if v.Uint >= other.Uint {
+		return Value{Kind: reflect.Uint64, Uint: v.Uint - other.Uint, Neg: v.Neg && v.Uint != other.Uint}
+	}
+	return Value{Kind: reflect.Uint64, Uint: other.Uint - v.Uint, Neg: other.Neg}
+}
+
+func (v Value) String() string {
+	switch v.Kind {
+	case reflect.Int64:
+		return strconv.FormatInt(v.Int, 10)
+	case reflect.Uint64:
+		if v.Neg {
+			return "-" + strconv.FormatUint(v.Uint, 10)
+		}
+		return strconv.FormatUint(v.Uint, 10)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float, 'g', -1, 64)
+	default:
+		return "<invalid>"
+	}
+}
+
+// bits encode value payload to 8 byte
+func (v Value) bits() uint64 {
+	switch v.Kind {
+	case reflect.Int64:
+		return uint64(v.Int)
+	case reflect.Uint64:
+		return v.Uint64()
+	default:
+		return math.Float64bits(v.Float)
+	}
+}
+
+func valueFromBits(kind reflect.Kind, bits uint64) Value {
+	switch kind {
+	case reflect.Int64:
+		return Int64Value(int64(bits))
+	case reflect.Uint64:
+		return Uint64Value(bits)
+	default:
+		return Float64Value(math.Float64frombits(bits))
+	}
+}
+
+func validateValue(value Value) error {
+	switch value.Kind {
+	case reflect.Int64, reflect.Uint64, reflect.Float64:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidKind, value.Kind)
+	}
+}
@@ -2,6 +2,9 @@ package stream_core
 
 import "reflect"
 
+// Inc and Put method of typed value panic on kind mismatch and with ErrReadOnly on read only counter,
+// use Inc or Put to get error instead
+
 func (hm *HashMapCounter) IncFloat64(key string, delta float64) float64 {
 	return hm.apply(key, delta, false).(float64)
 }
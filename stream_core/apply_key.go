@@ -34,14 +34,30 @@ func (hm *HashMapCounter) getCounter(mustKind reflect.Kind, key string) any {
 	}
 }
 
+// apply used by IncInt64, PutInt64 and other method without error, panic with ErrReadOnly on read only counter like kind mismatch
 func (hm *HashMapCounter) apply(key string, delta any, replace bool) any {
 	hm.lock.Lock()
 	defer hm.lock.Unlock()
 
-	t := time.Now().UnixMilli()
-	ts := uint64(t)
+	err := hm.writable()
+	if err != nil {
+		panic(err)
+	}
+	return hm.applyLocked(key, delta, replace)
+}
+
+// applyLocked must called with lock held on writable counter
+func (hm *HashMapCounter) applyLocked(key string, delta any, replace bool) any {
+	now := time.Now()
+	ts := uint64(now.UnixMilli())
 	hkey := hm.hash.hash(key)
 	offset := hkey + HASHMAP_METADATA_SIZE // offset + current count metadata
+	hm.preserveSlot(hkey)
+	if replace {
+		hm.ops.record(opPut, now)
+	} else {
+		hm.ops.record(opInc, now)
+	}
 
 	lastts := binary.LittleEndian.Uint64(hm.data[offset+TIMESTAMP_OFFSET : offset+TIMESTAMP_OFFSET+8])
 
@@ -68,14 +84,19 @@ func (hm *HashMapCounter) apply(key string, delta any, replace bool) any {
 		// set timestamp
 		binary.LittleEndian.PutUint64(hm.data[offset+TIMESTAMP_OFFSET:offset+TIMESTAMP_OFFSET+8], uint64(ts))
 		// set type key
-		binary.LittleEndian.PutUint64(hm.data[offset+TYPE_KEY_OFFSET:offset+TYPE_KEY_OFFSET+8], uint64(CounterKeyType))
-		// set key pointer
 		var counter byte = CounterKeyType
+		if replace {
+			counter = ReplaceKeyType
+		}
+		binary.LittleEndian.PutUint64(hm.data[offset+TYPE_KEY_OFFSET:offset+TYPE_KEY_OFFSET+8], uint64(counter))
+		// set key pointer
 		keyOffset, err := hm.dynamicValue.Write(key, hkey, []byte{counter})
 		if err != nil {
 			panic(err)
 		}
 		binary.LittleEndian.PutUint64(hm.data[offset+KEY_POINTER_OFFSET:offset+KEY_POINTER_OFFSET+8], uint64(keyOffset))
+		hm.index.insert(key, hkey)
+		hm.publish(key, reflect.Kind(hm.data[offset+HASHMAP_TYPE_COUNTER_OFFSET]), nil, delta, now)
 		return delta
 	}
 
@@ -97,6 +118,7 @@ func (hm *HashMapCounter) apply(key string, delta any, replace bool) any {
 			nextVal = addOps(prevVal, val)
 		}
 		binary.LittleEndian.PutUint64(hm.data[offset+COUNTER_OFFSET:offset+COUNTER_OFFSET+8], uint64(nextVal))
+		hm.publish(key, typeCounter, prevVal, nextVal, now)
 
 		return nextVal
 	case int64:
@@ -111,6 +133,7 @@ func (hm *HashMapCounter) apply(key string, delta any, replace bool) any {
 			nextVal = addOps(prevVal, val)
 		}
 		binary.LittleEndian.PutUint64(hm.data[offset+COUNTER_OFFSET:offset+COUNTER_OFFSET+8], uint64(nextVal))
+		hm.publish(key, typeCounter, prevVal, nextVal, now)
 
 		return nextVal
 	case float64:
@@ -126,6 +149,7 @@ func (hm *HashMapCounter) apply(key string, delta any, replace bool) any {
 			nextVal = addOps(prevVal, val)
 		}
 		binary.LittleEndian.PutUint64(hm.data[offset+COUNTER_OFFSET:offset+COUNTER_OFFSET+8], math.Float64bits(nextVal))
+		hm.publish(key, typeCounter, prevVal, nextVal, now)
 
 		return nextVal
 	default:
@@ -0,0 +1,402 @@
+package stream_core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/edsrzf/mmap-go"
+	wal_message "github.com/wargasipil/stream_engine/proto_core/wal_message/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+var ErrWalDisabled = errors.New("wal dir not configured")
+
+// ErrBatchPending batch crashed before commit, undo applied on next open of counter
+var ErrBatchPending = errors.New("batch undo pending, open counter to roll back batch first")
+
+type batchOp struct {
+	key     string
+	value   any
+	replace bool
+	merge   *MergeDefinition
+	kind    reflect.Kind
+}
+
+// Batch counter update applied atomically together with source offset,
+// so ingestion restarted from committed offset never count event twice
+type Batch struct {
+	ops     []batchOp
+	offsets map[string]int64
+}
+
+func NewBatch() *Batch {
+	return &Batch{offsets: map[string]int64{}}
+}
+
+func (b *Batch) IncInt64(key string, delta int64) {
+	b.add(key, delta, false)
+}
+
+func (b *Batch) IncUint64(key string, delta uint64) {
+	b.add(key, delta, false)
+}
+
+func (b *Batch) IncFloat64(key string, delta float64) {
+	b.add(key, delta, false)
+}
+
+func (b *Batch) PutInt64(key string, value int64) {
+	b.add(key, value, true)
+}
+
+func (b *Batch) PutUint64(key string, value uint64) {
+	b.add(key, value, true)
+}
+
+func (b *Batch) PutFloat64(key string, value float64) {
+	b.add(key, value, true)
+}
+
+func (b *Batch) add(key string, value any, replace bool) {
+	b.ops = append(b.ops, batchOp{
+		key:     key,
+		value:   value,
+		replace: replace,
+		kind:    reflect.ValueOf(value).Kind(),
+	})
+}
+
+// Merge recalculate computed key after update before it in batch applied
+func (b *Batch) Merge(op MergeOps, kind reflect.Kind, computedKey string, keys ...string) {
+	b.ops = append(b.ops, batchOp{
+		key:  computedKey,
+		kind: kind,
+		merge: &MergeDefinition{
+			Op:      op,
+			Sources: keys,
+		},
+	})
+}
+
+// SetOffset position of source already covered by batch, like file path or topic partition
+func (b *Batch) SetOffset(source string, offset int64) {
+	b.offsets[source] = offset
+}
+
+// Len count update in batch
+func (b *Batch) Len() int {
+	return len(b.ops)
+}
+
+func (b *Batch) Reset() {
+	b.ops = b.ops[:0]
+	clear(b.offsets)
+}
+
+// batchUndo state before batch applied
+type batchUndo struct {
+	slots         map[int64]slotImage
+	keyCount      uint64
+	dynamicOffset int64
+	created       []string
+}
+
+// ApplyBatch apply all update and source offset of batch or none of them.
+// undo image of touched slot written to wal before applying, batch committed after touched page flushed,
+// change published to subscriber only after commit, batch not committed when process crash restored on next open
+func (hm *HashMapCounter) ApplyBatch(b *Batch) error {
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	if hm.readOnly {
+		return ErrReadOnly
+	}
+	if hm.wal == nil {
+		return ErrWalDisabled
+	}
+
+	err := hm.validateBatch(b)
+	if err != nil {
+		return err
+	}
+
+	undo := &batchUndo{
+		slots:         map[int64]slotImage{},
+		keyCount:      hm.keyCount,
+		dynamicOffset: hm.dynamicValue.Offset(),
+	}
+	record := &wal_message.BatchUndo{
+		KeyCount:      undo.keyCount,
+		DynamicOffset: undo.dynamicOffset,
+	}
+	for _, op := range b.ops {
+		slot := hm.hash.hash(op.key)
+		if _, ok := undo.slots[slot]; ok {
+			continue
+		}
+
+		image := make(slotImage, HASHMAP_SLOT_SIZE)
+		copy(image, hm.slot(slot))
+		undo.slots[slot] = image
+		record.Slots = append(record.Slots, &wal_message.SlotImage{Slot: slot, Data: image})
+	}
+
+	err = hm.wal.Append(&wal_message.BatchRecord{
+		Record: &wal_message.BatchRecord_Undo{Undo: record},
+	})
+	if err != nil {
+		return err
+	}
+
+	// change of batch not committed never reach subscriber
+	hm.feed.hold()
+	defer hm.feed.drop()
+
+	err = hm.applyBatchOps(b, undo)
+	if err != nil {
+		hm.rollbackBatch(undo)
+		abortErr := hm.wal.Append(&wal_message.BatchRecord{
+			Record: &wal_message.BatchRecord_Abort{Abort: &wal_message.BatchAbort{}},
+		})
+		return errors.Join(err, abortErr)
+	}
+
+	// counter must durable before commit, otherwise committed offset can be ahead of counter after os crash
+	err = hm.flushBatch(undo)
+	if err != nil {
+		return err
+	}
+
+	err = hm.wal.Append(&wal_message.BatchRecord{
+		Record: &wal_message.BatchRecord_Commit{Commit: &wal_message.BatchCommit{Offsets: b.offsets}},
+	})
+	if err != nil {
+		return err
+	}
+	for source, offset := range b.offsets {
+		hm.offsets[source] = offset
+	}
+	hm.feed.release()
+	hm.ops.record(opBatch, time.Now())
+
+	// wal rotated, dropping segment of batch already committed
+	if hm.wal.Segment() != hm.walSegment {
+		return hm.checkpointWal()
+	}
+	return nil
+}
+
+// validateBatch reject update with kind different from existing counter before anything written
+func (hm *HashMapCounter) validateBatch(b *Batch) error {
+	kinds := map[int64]reflect.Kind{}
+	for _, op := range b.ops {
+		if op.key == "" {
+			return errors.New("key have empty string")
+		}
+		switch op.kind {
+		case reflect.Int64, reflect.Uint64, reflect.Float64:
+		default:
+			return fmt.Errorf("%s counter typedata %s not supported", op.key, op.kind)
+		}
+
+		slot := hm.hash.hash(op.key)
+		kind, ok := kinds[slot]
+		if !ok && hm.slotTimestamp(slot) != 0 {
+			kind, _ = hm.slotValue(slot)
+			ok = true
+		}
+		if ok && kind != op.kind {
+			return &KindMismatchError{Key: op.key, Kind: op.kind, Existing: kind}
+		}
+		kinds[slot] = op.kind
+	}
+	return nil
+}
+
+func (hm *HashMapCounter) applyBatchOps(b *Batch, undo *batchUndo) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("applying batch: %v", r)
+		}
+	}()
+
+	for _, op := range b.ops {
+		slot := hm.hash.hash(op.key)
+		if hm.slotTimestamp(slot) == 0 {
+			undo.created = append(undo.created, op.key)
+		}
+
+		if op.merge != nil {
+			_, err = hm.mergeLocked(op.merge.Op, op.kind, op.key, op.merge.Sources...)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		hm.applyLocked(op.key, op.value, op.replace)
+	}
+	return nil
+}
+
+// flushBatch flush header, slot touched by batch and dynamic value appended by batch
+func (hm *HashMapCounter) flushBatch(undo *batchUndo) error {
+	d := hm.dynamicValue
+	err := flushPages(d.data, d.f, []byteRange{
+		{0, DYNAMIC_METADATA_SIZE},
+		{undo.dynamicOffset, d.currentOffset},
+	})
+	if err != nil {
+		return err
+	}
+
+	ranges := []byteRange{{0, HASHMAP_METADATA_SIZE}}
+	for slot := range undo.slots {
+		offset := slot + HASHMAP_METADATA_SIZE
+		ranges = append(ranges, byteRange{offset, offset + HASHMAP_SLOT_SIZE})
+	}
+	return flushPages(hm.data, hm.f, ranges)
+}
+
+// rollbackBatch restore slot, key count and dynamic value before batch applied
+func (hm *HashMapCounter) rollbackBatch(undo *batchUndo) {
+	for slot, image := range undo.slots {
+		hm.preserveSlot(slot)
+		copy(hm.slot(slot), image)
+	}
+	for _, key := range undo.created {
+		hm.index.remove(key)
+	}
+
+	hm.keyCount = undo.keyCount
+	setCurrentCount(hm.data, hm.keyCount)
+	hm.dynamicValue.truncate(undo.dynamicOffset)
+}
+
+// SourceOffset last committed offset of source
+func (hm *HashMapCounter) SourceOffset(source string) (int64, bool) {
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	offset, ok := hm.offsets[source]
+	return offset, ok
+}
+
+// SourceOffsets all committed source offset
+func (hm *HashMapCounter) SourceOffsets() map[string]int64 {
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	offsets := make(map[string]int64, len(hm.offsets))
+	for source, offset := range hm.offsets {
+		offsets[source] = offset
+	}
+	return offsets
+}
+
+// checkpointWal replace wal with single checkpoint of source offset, counter must already flushed
+func (hm *HashMapCounter) checkpointWal() error {
+	err := hm.wal.Truncate(&wal_message.BatchRecord{
+		Record: &wal_message.BatchRecord_Checkpoint{Checkpoint: &wal_message.Checkpoint{Offsets: hm.offsets}},
+	})
+	if err != nil {
+		return err
+	}
+	hm.walSegment = hm.wal.Segment()
+	return nil
+}
+
+// openBatchWal replay batch wal, batch not committed is undone before key index loaded.
+// wal of new counter file is ignored
+// replayBatchWal return undo of batch not committed or aborted and committed source offset
+func replayBatchWal(dir string) (*wal_message.BatchUndo, map[string]int64, error) {
+	offsets := map[string]int64{}
+	var pending *wal_message.BatchUndo
+
+	var replayErr error
+	err := Replay(dir, func(raw []byte) {
+		if replayErr != nil {
+			return
+		}
+
+		record := &wal_message.BatchRecord{}
+		replayErr = proto.Unmarshal(raw, record)
+		if replayErr != nil {
+			return
+		}
+
+		switch rec := record.Record.(type) {
+		case *wal_message.BatchRecord_Undo:
+			pending = rec.Undo
+		case *wal_message.BatchRecord_Commit:
+			pending = nil
+			for source, offset := range rec.Commit.Offsets {
+				offsets[source] = offset
+			}
+		case *wal_message.BatchRecord_Abort:
+			pending = nil
+		case *wal_message.BatchRecord_Checkpoint:
+			pending = nil
+			offsets = map[string]int64{}
+			for source, offset := range rec.Checkpoint.Offsets {
+				offsets[source] = offset
+			}
+		}
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, err
+	}
+	if replayErr != nil {
+		return nil, nil, fmt.Errorf("replaying batch wal: %w", replayErr)
+	}
+	return pending, offsets, nil
+}
+
+func openBatchWal(dir string, data mmap.MMap, dynamic *DynamicValue, isnew bool) (*WAL, map[string]int64, error) {
+	offsets := map[string]int64{}
+	var pending *wal_message.BatchUndo
+
+	if !isnew {
+		var err error
+		pending, offsets, err = replayBatchWal(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if pending != nil {
+		for _, image := range pending.Slots {
+			offset := image.Slot + HASHMAP_METADATA_SIZE
+			copy(data[offset:offset+HASHMAP_SLOT_SIZE], image.Data)
+		}
+		setCurrentCount(data, pending.KeyCount)
+		dynamic.truncate(pending.DynamicOffset)
+
+		err := dynamic.data.Flush()
+		if err != nil {
+			return nil, nil, err
+		}
+		err = data.Flush()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	wal, err := OpenWAL(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// torn record never followed by new record
+	err = wal.Truncate(&wal_message.BatchRecord{
+		Record: &wal_message.BatchRecord_Checkpoint{Checkpoint: &wal_message.Checkpoint{Offsets: offsets}},
+	})
+	if err != nil {
+		wal.Close()
+		return nil, nil, err
+	}
+
+	return wal, offsets, nil
+}
@@ -0,0 +1,175 @@
+package stream_core_test
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func copyFile(t *testing.T, src, dst string) {
+	data, err := os.ReadFile(src)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(dst, data, 0644))
+}
+
+// dropLastWalRecord cut last record of wal segment, record header is magic(4) + len(4) + crc(4)
+func dropLastWalRecord(t *testing.T, path string) {
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+
+	last := 0
+	for offset := 0; offset < len(data); {
+		last = offset
+		offset += 12 + int(binary.LittleEndian.Uint32(data[offset+4:offset+8]))
+	}
+	assert.Nil(t, os.WriteFile(path, data[:last], 0644))
+}
+
+func TestApplyBatch(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		WalDir:              "/tmp/stream_engine/batch_wal_unittest",
+		HashMapCounterPath:  "/tmp/stream_engine/batch_unittest",
+		HashMapCounterSlots: 1 << 16,
+		DynamicValuePath:    "/tmp/stream_engine/batch_value_unittest",
+	}
+	os.RemoveAll(cfg.WalDir)
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+
+	t.Run("testing batch commit offset", func(t *testing.T) {
+		batch := stream_core.NewBatch()
+		batch.IncInt64("accounts/1/debit", 10)
+		batch.IncInt64("accounts/1/credit", 4)
+		batch.Merge(stream_core.MergeOpAdd, reflect.Int64, "accounts/1/total", "accounts/1/debit", "accounts/1/credit")
+		batch.SetOffset("example.jsonl", 120)
+		assert.Nil(t, kv.ApplyBatch(batch))
+
+		assert.Equal(t, int64(10), kv.GetInt64("accounts/1/debit"))
+		assert.Equal(t, int64(14), kv.GetInt64("accounts/1/total"))
+
+		offset, ok := kv.SourceOffset("example.jsonl")
+		assert.True(t, ok)
+		assert.Equal(t, int64(120), offset)
+	})
+
+	t.Run("testing kind mismatch rejected", func(t *testing.T) {
+		batch := stream_core.NewBatch()
+		batch.IncInt64("accounts/2/debit", 1)
+		batch.IncFloat64("accounts/1/debit", 1)
+		batch.SetOffset("example.jsonl", 240)
+		assert.NotNil(t, kv.ApplyBatch(batch))
+
+		assert.Equal(t, int64(0), kv.GetInt64("accounts/2/debit"))
+		offset, _ := kv.SourceOffset("example.jsonl")
+		assert.Equal(t, int64(120), offset)
+	})
+
+	t.Run("testing failed batch rolled back", func(t *testing.T) {
+		changes := kv.Subscribe("accounts/")
+		defer kv.Unsubscribe(changes)
+
+		batch := stream_core.NewBatch()
+		batch.IncInt64("accounts/1/debit", 5)
+		batch.IncInt64("accounts/3/debit", 7)
+		// source changed from existing computed key
+		batch.Merge(stream_core.MergeOpAdd, reflect.Int64, "accounts/1/total", "accounts/1/debit")
+		batch.SetOffset("example.jsonl", 240)
+		assert.NotNil(t, kv.ApplyBatch(batch))
+
+		assert.Equal(t, int64(10), kv.GetInt64("accounts/1/debit"))
+		assert.Equal(t, int64(0), kv.GetInt64("accounts/3/debit"))
+
+		keys := []string{}
+		kv.ScanPrefix("accounts/", nil, func(key string, kind reflect.Kind, value any) error {
+			keys = append(keys, key)
+			return nil
+		})
+		assert.Equal(t, []string{"accounts/1/credit", "accounts/1/debit", "accounts/1/total"}, keys)
+
+		offset, _ := kv.SourceOffset("example.jsonl")
+		assert.Equal(t, int64(120), offset)
+
+		// change of rolled back batch not published
+		assert.Len(t, changes, 0)
+		batch.Reset()
+		batch.IncInt64("accounts/1/credit", 1)
+		assert.Nil(t, kv.ApplyBatch(batch))
+		assert.Len(t, changes, 1)
+		assert.Equal(t, "accounts/1/credit", (<-changes).Key)
+	})
+
+	t.Run("testing crash in middle of batch undone", func(t *testing.T) {
+		batch := stream_core.NewBatch()
+		batch.IncInt64("accounts/1/debit", 5)
+		batch.IncInt64("accounts/4/debit", 3)
+		batch.SetOffset("example.jsonl", 360)
+		assert.Nil(t, kv.ApplyBatch(batch))
+		assert.Equal(t, int64(15), kv.GetInt64("accounts/1/debit"))
+
+		// files left on disk when process killed before commit record written
+		crashCfg := cfg
+		crashCfg.WalDir = cfg.WalDir + "_crash"
+		crashCfg.HashMapCounterPath = cfg.HashMapCounterPath + "_crash"
+		crashCfg.DynamicValuePath = cfg.DynamicValuePath + "_crash"
+		os.RemoveAll(crashCfg.WalDir)
+		os.MkdirAll(crashCfg.WalDir, 0755)
+		copyFile(t, cfg.HashMapCounterPath, crashCfg.HashMapCounterPath)
+		copyFile(t, cfg.DynamicValuePath, crashCfg.DynamicValuePath)
+		segments, _ := os.ReadDir(cfg.WalDir)
+		for _, segment := range segments {
+			copyFile(t, filepath.Join(cfg.WalDir, segment.Name()), filepath.Join(crashCfg.WalDir, segment.Name()))
+		}
+		dropLastWalRecord(t, filepath.Join(crashCfg.WalDir, segments[len(segments)-1].Name()))
+
+		recovered, err := stream_core.NewHashMapCounter(&crashCfg)
+		assert.Nil(t, err)
+		defer recovered.Close()
+
+		assert.Equal(t, int64(10), recovered.GetInt64("accounts/1/debit"))
+		assert.Equal(t, int64(0), recovered.GetInt64("accounts/4/debit"))
+		offset, _ := recovered.SourceOffset("example.jsonl")
+		assert.Equal(t, int64(120), offset)
+
+		count := 0
+		recovered.ScanPrefix("accounts/4/", nil, func(key string, kind reflect.Kind, value any) error {
+			count++
+			return nil
+		})
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("testing offset persisted after reopen", func(t *testing.T) {
+		assert.Nil(t, kv.Close())
+
+		kv, err = stream_core.NewHashMapCounter(&cfg)
+		assert.Nil(t, err)
+		defer kv.Close()
+
+		assert.Equal(t, map[string]int64{"example.jsonl": 360}, kv.SourceOffsets())
+		assert.Equal(t, int64(3), kv.GetInt64("accounts/4/debit"))
+	})
+
+	t.Run("testing batch without wal", func(t *testing.T) {
+		noWal := stream_core.CoreConfig{
+			HashMapCounterPath:  "/tmp/stream_engine/batch_nowal_unittest",
+			HashMapCounterSlots: 1024,
+			DynamicValuePath:    "/tmp/stream_engine/batch_nowal_value_unittest",
+		}
+		os.Remove(noWal.DynamicValuePath)
+		os.Remove(noWal.HashMapCounterPath)
+
+		hm, err := stream_core.NewHashMapCounter(&noWal)
+		assert.Nil(t, err)
+		defer hm.Close()
+
+		assert.ErrorIs(t, hm.ApplyBatch(stream_core.NewBatch()), stream_core.ErrWalDisabled)
+	})
+}
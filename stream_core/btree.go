@@ -2,6 +2,8 @@ package stream_core
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 
 	"github.com/google/btree"
 )
@@ -16,3 +18,191 @@ type KVItem struct {
 func (a KVItem) Less(b btree.Item) bool {
 	return bytes.Compare(a.Key, b.(KVItem).Key) < 0
 }
+
+// keyIndex ordered index key to hashmap slot offset, composed by persisted run
+// and in memory tree for key written after run watermark
+type keyIndex struct {
+	path string
+	run  *indexRun
+	tree *btree.BTree
+}
+
+// openKeyIndex load persisted run from path and replay key written after the run from dynamic value,
+// empty path keep whole index in memory
+func openKeyIndex(path string, dynamic *DynamicValue) (*keyIndex, error) {
+	var run *indexRun
+	var err error
+
+	if path != "" {
+		run, err = openIndexRun(path, dynamic)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// run newer than dynamic value cannot be trusted, rebuilding from start
+	if run != nil && run.watermark > dynamic.currentOffset {
+		err = run.close()
+		if err != nil {
+			return nil, err
+		}
+		run = nil
+	}
+
+	var from int64 = DYNAMIC_METADATA_SIZE
+	if run != nil {
+		from = run.watermark
+	}
+
+	ki := &keyIndex{
+		path: path,
+		run:  run,
+		tree: btree.New(BTreeDegree),
+	}
+
+	err = dynamic.iterateFrom(from, func(offset int64, key string, hash int64, data []byte) error {
+		ki.insert(key, hash)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ki, nil
+}
+
+func (ki *keyIndex) insert(key string, slot int64) {
+	ki.tree.ReplaceOrInsert(KVItem{
+		Key: []byte(key),
+		Off: uint64(slot),
+	})
+}
+
+// remove key inserted to in memory tree
+func (ki *keyIndex) remove(key string) {
+	ki.tree.Delete(KVItem{Key: []byte(key)})
+}
+
+func (ki *keyIndex) len() int {
+	count := ki.tree.Len()
+	if ki.run != nil {
+		count += ki.run.count
+	}
+	return count
+}
+
+// ascend iterate item key >= start, and key < end when end not empty
+func (ki *keyIndex) ascend(start, end string, handler func(item KVItem) bool) {
+	pivot := KVItem{Key: []byte(start)}
+
+	// run and tree never have same key, merging both in key order
+	var i, n int
+	if ki.run != nil {
+		i = ki.run.search(pivot.Key)
+		n = ki.run.count
+	}
+
+	emitRun := func(limit []byte) bool {
+		for ; i < n; i++ {
+			item := ki.run.item(i)
+			if end != "" && bytes.Compare(item.Key, []byte(end)) >= 0 {
+				i = n
+				return true
+			}
+			if limit != nil && bytes.Compare(item.Key, limit) >= 0 {
+				return true
+			}
+			if !handler(item) {
+				return false
+			}
+		}
+		return true
+	}
+
+	next := true
+	iterator := func(it btree.Item) bool {
+		item := it.(KVItem)
+		next = emitRun(item.Key)
+		if !next {
+			return false
+		}
+		next = handler(item)
+		return next
+	}
+
+	if end == "" {
+		ki.tree.AscendGreaterOrEqual(pivot, iterator)
+	} else {
+		ki.tree.AscendRange(pivot, KVItem{Key: []byte(end)}, iterator)
+	}
+
+	if next {
+		emitRun(nil)
+	}
+}
+
+// checkpoint persist whole index as new run, watermark is dynamic value offset already covered by index
+func (ki *keyIndex) checkpoint(watermark int64, dynamic *DynamicValue, pointer func(slot uint64) uint64) error {
+	if ki.path == "" {
+		return nil
+	}
+
+	if ki.tree.Len() == 0 && ki.run != nil {
+		return nil
+	}
+
+	// key pointer in run must be durable before run
+	err := dynamic.data.Flush()
+	if err != nil {
+		return err
+	}
+
+	// writing next run beside current run, so run in path always complete
+	tmpPath := ki.path + ".tmp"
+	err = writeIndexRun(tmpPath, watermark, ki.len(), func(write func(pointer, slot uint64) error) error {
+		var err error
+		ki.ascend("", "", func(item KVItem) bool {
+			err = write(pointer(item.Off), item.Off)
+			return err == nil
+		})
+		return err
+	})
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if ki.run != nil {
+		err = ki.run.close()
+		if err != nil {
+			return err
+		}
+		ki.run = nil
+	}
+
+	err = os.Rename(tmpPath, ki.path)
+	if err != nil {
+		return err
+	}
+
+	err = syncDir(filepath.Dir(ki.path))
+	if err != nil {
+		return err
+	}
+
+	run, err := openIndexRun(ki.path, dynamic)
+	if err != nil {
+		return err
+	}
+
+	ki.run = run
+	ki.tree = btree.New(BTreeDegree)
+	return nil
+}
+
+func (ki *keyIndex) close() error {
+	if ki.run == nil {
+		return nil
+	}
+	return ki.run.close()
+}
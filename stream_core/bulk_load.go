@@ -0,0 +1,179 @@
+package stream_core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+var ErrBulkLoaderClosed = errors.New("bulk loader closed")
+
+// BulkLoader write key record directly to slot, counter lock is held until Close
+type BulkLoader struct {
+	hm     *HashMapCounter
+	count  int
+	closed bool
+	// dynamicOffset and loaded slot restored when load rejected at Close
+	dynamicOffset int64
+	loaded        map[string]int64
+	merges        []*KeyRecord
+}
+
+// NewBulkLoader lock counter for loading, other call to counter block until loader closed
+func (hm *HashMapCounter) NewBulkLoader() *BulkLoader {
+	hm.lock.Lock()
+	// change of rolled back load never reach subscriber
+	hm.feed.hold()
+	return &BulkLoader{
+		hm:            hm,
+		dynamicOffset: hm.dynamicValue.Offset(),
+		loaded:        map[string]int64{},
+	}
+}
+
+// Load write record with its original timestamp, kind and merge definition.
+// key must not exist yet, value of computed key is loaded as is not recalculated
+func (b *BulkLoader) Load(rec *KeyRecord) error {
+	if b.closed {
+		return ErrBulkLoaderClosed
+	}
+	if b.hm.readOnly {
+		return ErrReadOnly
+	}
+
+	hm := b.hm
+	hkey := hm.hash.hash(rec.Key)
+	offset := hkey + HASHMAP_METADATA_SIZE
+
+	if hm.slotTimestamp(hkey) != 0 {
+		return fmt.Errorf("%s slot already used", rec.Key)
+	}
+
+	var value uint64
+	switch val := rec.Value.(type) {
+	case uint64:
+		value = val
+	case int64:
+		value = uint64(val)
+	case float64:
+		value = math.Float64bits(val)
+	default:
+		return fmt.Errorf("%s value typedata %T not supported", rec.Key, rec.Value)
+	}
+
+	if rec.Kind != reflect.ValueOf(rec.Value).Kind() {
+		return fmt.Errorf("%s kind %s not match value %T", rec.Key, rec.Kind, rec.Value)
+	}
+
+	var typeKey byte = CounterKeyType
+	if rec.Replace {
+		typeKey = ReplaceKeyType
+	}
+	data := []byte{typeKey}
+	if rec.Merge != nil {
+		if len(rec.Merge.Sources) == 0 {
+			return fmt.Errorf("derrived key %s empty", rec.Key)
+		}
+
+		derrivedKeys := make([]int64, len(rec.Merge.Sources))
+		for i, key := range rec.Merge.Sources {
+			derrivedKeys[i] = hm.hash.hash(key)
+		}
+
+		mergeData := NewMergeData(int64(len(derrivedKeys)))
+		mergeData.setOp(rec.Merge.Op)
+		mergeData.setHashKeys(hm.hash, derrivedKeys)
+
+		typeKey = MergeKeyType
+		data = mergeData
+	}
+
+	ts := rec.UpdatedAt.UnixMilli()
+	if rec.UpdatedAt.IsZero() || ts <= 0 {
+		ts = time.Now().UnixMilli()
+	}
+
+	hm.preserveSlot(hkey)
+	keyOffset, err := hm.dynamicValue.Write(rec.Key, hkey, data)
+	if err != nil {
+		return err
+	}
+
+	hm.data[offset+HASHMAP_TYPE_COUNTER_OFFSET] = byte(rec.Kind)
+	binary.LittleEndian.PutUint64(hm.data[offset+COUNTER_OFFSET:offset+COUNTER_OFFSET+8], value)
+	binary.LittleEndian.PutUint64(hm.data[offset+TIMESTAMP_OFFSET:offset+TIMESTAMP_OFFSET+8], uint64(ts))
+	binary.LittleEndian.PutUint64(hm.data[offset+TYPE_KEY_OFFSET:offset+TYPE_KEY_OFFSET+8], uint64(typeKey))
+	binary.LittleEndian.PutUint64(hm.data[offset+KEY_POINTER_OFFSET:offset+KEY_POINTER_OFFSET+8], uint64(keyOffset))
+	hm.index.insert(rec.Key, hkey)
+	hm.publish(rec.Key, rec.Kind, nil, rec.Value, time.UnixMilli(ts))
+
+	b.loaded[rec.Key] = hkey
+	if rec.Merge != nil {
+		b.merges = append(b.merges, rec)
+	}
+	b.count++
+	return nil
+}
+
+// Count record loaded
+func (b *BulkLoader) Count() int {
+	return b.count
+}
+
+// missingSource first merge source neither loaded nor already in counter, must called with lock held
+func (b *BulkLoader) missingSource() (string, string) {
+	hm := b.hm
+	for _, rec := range b.merges {
+		for _, source := range rec.Merge.Sources {
+			if !hm.keyStored(source, hm.hash.hash(source)) {
+				return rec.Key, source
+			}
+		}
+	}
+	return "", ""
+}
+
+// rollback clear all slot and dynamic value written by loader, must called with lock held
+func (b *BulkLoader) rollback() {
+	hm := b.hm
+	for key, slot := range b.loaded {
+		hm.preserveSlot(slot)
+		clear(hm.slot(slot))
+		hm.index.remove(key)
+	}
+	hm.dynamicValue.truncate(b.dynamicOffset)
+	b.count = 0
+}
+
+// Close update key count and release counter lock.
+// whole load rolled back when merge source neither loaded nor already in counter
+func (b *BulkLoader) Close() error {
+	if b.closed {
+		return ErrBulkLoaderClosed
+	}
+	b.closed = true
+
+	hm := b.hm
+	defer hm.lock.Unlock()
+
+	// nothing loaded into read only counter
+	if hm.readOnly {
+		hm.feed.drop()
+		return nil
+	}
+
+	if key, source := b.missingSource(); source != "" {
+		b.rollback()
+		hm.feed.drop()
+		return fmt.Errorf("derrived key %s source %s not loaded and not in counter", key, source)
+	}
+
+	hm.keyCount += uint64(b.count)
+	setCurrentCount(hm.data, hm.keyCount)
+	hm.feed.release()
+
+	return hm.data.Flush()
+}
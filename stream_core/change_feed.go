@@ -0,0 +1,202 @@
+package stream_core
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultSubscribeBuffer = 1024
+
+type SlowConsumerPolicy int
+
+const (
+	// SlowConsumerDrop drop change when subscriber buffer full
+	SlowConsumerDrop SlowConsumerPolicy = iota
+	// SlowConsumerBlock block writer until subscriber buffer has space
+	SlowConsumerBlock
+	// SlowConsumerDisconnect close subscriber channel when subscriber buffer full
+	SlowConsumerDisconnect
+)
+
+type Change struct {
+	// Seq increase on every change published by counter
+	Seq  uint64
+	Key  string
+	Kind reflect.Kind
+	// Old is nil when key created
+	Old       any
+	New       any
+	Timestamp time.Time
+}
+
+type SubscribeOption struct {
+	Buffer int
+	Policy SlowConsumerPolicy
+}
+
+type subscriber struct {
+	prefix    string
+	policy    SlowConsumerPolicy
+	ch        chan Change
+	done      chan struct{}
+	closeOnce sync.Once
+	dropped   uint64
+}
+
+type changeFeed struct {
+	seq         uint64
+	subscribers map[<-chan Change]*subscriber
+	// holding queue change in held until release or drop
+	holding bool
+	held    []Change
+	// lookup lock never held while sending, so unsubscribe can find blocked subscriber
+	lookupLock sync.Mutex
+	lookup     map[<-chan Change]*subscriber
+}
+
+func newChangeFeed() *changeFeed {
+	return &changeFeed{
+		subscribers: map[<-chan Change]*subscriber{},
+		lookup:      map[<-chan Change]*subscriber{},
+	}
+}
+
+// Subscribe change of key with prefix, drop change when buffer full
+func (hm *HashMapCounter) Subscribe(prefix string) <-chan Change {
+	return hm.SubscribeWithOption(prefix, &SubscribeOption{
+		Buffer: DefaultSubscribeBuffer,
+		Policy: SlowConsumerDrop,
+	})
+}
+
+func (hm *HashMapCounter) SubscribeWithOption(prefix string, opt *SubscribeOption) <-chan Change {
+	buffer := opt.Buffer
+	if buffer <= 0 {
+		buffer = DefaultSubscribeBuffer
+	}
+
+	sub := &subscriber{
+		prefix: prefix,
+		policy: opt.Policy,
+		ch:     make(chan Change, buffer),
+		done:   make(chan struct{}),
+	}
+
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	hm.feed.subscribers[sub.ch] = sub
+	hm.feed.lookupLock.Lock()
+	hm.feed.lookup[sub.ch] = sub
+	hm.feed.lookupLock.Unlock()
+	return sub.ch
+}
+
+// Unsubscribe stop and close subscriber channel, safe to call while writer blocked by subscriber
+func (hm *HashMapCounter) Unsubscribe(ch <-chan Change) {
+	hm.feed.lookupLock.Lock()
+	sub, ok := hm.feed.lookup[ch]
+	hm.feed.lookupLock.Unlock()
+	if !ok {
+		return
+	}
+
+	// releasing blocked writer before taking lock
+	sub.closeOnce.Do(func() { close(sub.done) })
+
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+	hm.feed.remove(sub)
+}
+
+func (f *changeFeed) remove(sub *subscriber) {
+	if _, ok := f.subscribers[sub.ch]; !ok {
+		return
+	}
+	delete(f.subscribers, sub.ch)
+	f.lookupLock.Lock()
+	delete(f.lookup, sub.ch)
+	f.lookupLock.Unlock()
+	sub.closeOnce.Do(func() { close(sub.done) })
+	close(sub.ch)
+}
+
+func (f *changeFeed) closeAll() {
+	for _, sub := range f.subscribers {
+		f.remove(sub)
+	}
+}
+
+// publish send change to subscriber, must called with lock held
+func (hm *HashMapCounter) publish(key string, kind reflect.Kind, old any, current any, t time.Time) {
+	f := hm.feed
+	if len(f.subscribers) == 0 {
+		return
+	}
+
+	change := Change{
+		Key:       key,
+		Kind:      kind,
+		Old:       old,
+		New:       current,
+		Timestamp: t,
+	}
+	if f.holding {
+		f.held = append(f.held, change)
+		return
+	}
+	f.send(change)
+}
+
+// hold queue published change until release, must called with lock held
+func (f *changeFeed) hold() {
+	f.holding = true
+}
+
+// release send held change, must called with lock held
+func (f *changeFeed) release() {
+	held := f.held
+	f.holding = false
+	f.held = nil
+	for _, change := range held {
+		f.send(change)
+	}
+}
+
+// drop discard held change, no op after release. must called with lock held
+func (f *changeFeed) drop() {
+	f.holding = false
+	f.held = nil
+}
+
+// send change to matching subscriber following its slow consumer policy
+func (f *changeFeed) send(change Change) {
+	f.seq++
+	change.Seq = f.seq
+
+	for _, sub := range f.subscribers {
+		if !strings.HasPrefix(change.Key, sub.prefix) {
+			continue
+		}
+
+		select {
+		case sub.ch <- change:
+			continue
+		default:
+		}
+
+		switch sub.policy {
+		case SlowConsumerBlock:
+			select {
+			case sub.ch <- change:
+			case <-sub.done:
+			}
+		case SlowConsumerDisconnect:
+			f.remove(sub)
+		default:
+			sub.dropped++
+		}
+	}
+}
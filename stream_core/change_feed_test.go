@@ -0,0 +1,119 @@
+package stream_core_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func TestChangeFeed(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/change_feed_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/change_feed_value_unittest",
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer kv.Close()
+
+	t.Run("testing subscribe prefix", func(t *testing.T) {
+		ch := kv.Subscribe("accounts/1/")
+		defer kv.Unsubscribe(ch)
+
+		kv.IncInt64("accounts/1/debit", 10)
+		kv.IncInt64("accounts/1/debit", 5)
+		kv.IncInt64("accounts/2/debit", 5)
+		kv.IncInt64("accounts/1/credit", 3)
+		_, err := kv.Merge(stream_core.MergeOpAdd, reflect.Int64, "accounts/1/total", "accounts/1/debit", "accounts/1/credit")
+		assert.Nil(t, err)
+
+		changes := []stream_core.Change{}
+		for i := 0; i < 4; i++ {
+			changes = append(changes, <-ch)
+		}
+
+		assert.Equal(t, "accounts/1/debit", changes[0].Key)
+		assert.Nil(t, changes[0].Old)
+		assert.Equal(t, int64(10), changes[0].New)
+
+		assert.Equal(t, "accounts/1/debit", changes[1].Key)
+		assert.Equal(t, int64(10), changes[1].Old)
+		assert.Equal(t, int64(15), changes[1].New)
+		assert.Greater(t, changes[1].Seq, changes[0].Seq)
+
+		assert.Equal(t, "accounts/1/credit", changes[2].Key)
+
+		assert.Equal(t, "accounts/1/total", changes[3].Key)
+		assert.Equal(t, reflect.Int64, changes[3].Kind)
+		assert.Equal(t, int64(18), changes[3].New)
+
+		assert.Len(t, ch, 0)
+	})
+
+	t.Run("testing drop policy", func(t *testing.T) {
+		ch := kv.SubscribeWithOption("drop/", &stream_core.SubscribeOption{Buffer: 1, Policy: stream_core.SlowConsumerDrop})
+		defer kv.Unsubscribe(ch)
+
+		kv.IncInt64("drop/count", 1)
+		kv.IncInt64("drop/count", 1)
+
+		change := <-ch
+		assert.Equal(t, int64(1), change.New)
+		assert.Len(t, ch, 0)
+	})
+
+	t.Run("testing disconnect policy", func(t *testing.T) {
+		ch := kv.SubscribeWithOption("disconnect/", &stream_core.SubscribeOption{Buffer: 1, Policy: stream_core.SlowConsumerDisconnect})
+
+		kv.IncInt64("disconnect/count", 1)
+		kv.IncInt64("disconnect/count", 1)
+
+		_, ok := <-ch
+		assert.True(t, ok)
+		_, ok = <-ch
+		assert.False(t, ok, "channel closed")
+
+		kv.Unsubscribe(ch)
+	})
+
+	t.Run("testing block policy", func(t *testing.T) {
+		ch := kv.SubscribeWithOption("block/", &stream_core.SubscribeOption{Buffer: 1, Policy: stream_core.SlowConsumerBlock})
+
+		kv.IncInt64("block/count", 1)
+		done := make(chan struct{})
+		go func() {
+			kv.IncInt64("block/count", 1)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("writer not blocked")
+		case <-time.After(time.Millisecond * 50):
+		}
+
+		<-ch
+		<-done
+		assert.Equal(t, int64(2), (<-ch).New)
+
+		t.Run("unsubscribe release blocked writer", func(t *testing.T) {
+			kv.IncInt64("block/count", 1)
+			done := make(chan struct{})
+			go func() {
+				kv.IncInt64("block/count", 1)
+				close(done)
+			}()
+
+			time.Sleep(time.Millisecond * 20)
+			kv.Unsubscribe(ch)
+			<-done
+		})
+	})
+}
@@ -32,6 +32,30 @@ const (
 	MergeOpDivide
 )
 
+var mergeOpsNames = map[MergeOps]string{
+	MergeOpAdd:      "add",
+	MergeOpMin:      "min",
+	MergeOpMultiply: "multiply",
+	MergeOpDivide:   "divide",
+}
+
+func (op MergeOps) String() string {
+	name, ok := mergeOpsNames[op]
+	if !ok {
+		return fmt.Sprintf("merge_ops(%d)", int(op))
+	}
+	return name
+}
+
+func ParseMergeOps(name string) (MergeOps, error) {
+	for op, opname := range mergeOpsNames {
+		if opname == name {
+			return op, nil
+		}
+	}
+	return 0, fmt.Errorf("merge ops %s not supported", name)
+}
+
 type Int64Slice []int64
 
 func (s Int64Slice) Len() int           { return len(s) }
@@ -51,6 +75,10 @@ func (m MergeData) setOp(op MergeOps) {
 	binary.LittleEndian.PutUint64(m[MERGE_OPS_TYPE_OFFSET:MERGE_OPS_TYPE_OFFSET+8], uint64(op))
 }
 
+func (m MergeData) getOp() MergeOps {
+	return MergeOps(binary.LittleEndian.Uint64(m[MERGE_OPS_TYPE_OFFSET : MERGE_OPS_TYPE_OFFSET+8]))
+}
+
 func (m MergeData) setHashKeys(hasher *hashKey, keys Int64Slice) {
 	sort.Sort(keys)
 	keylen := len(keys)
@@ -89,7 +117,63 @@ func (m MergeData) getSourceHash() int64 {
 
 // ---------------------------- merge int implementation ---------------------------------
 
+type MergeDefinition struct {
+	Op      MergeOps
+	Sources []string
+}
+
+// mergeDefinition resolve merge op and source key of computed key slot, nil when slot not computed key
+func (hm *HashMapCounter) mergeDefinition(key string, image slotImage, read func(slot int64) slotImage) (*MergeDefinition, error) {
+	if image.typeKey() != MergeKeyType {
+		return nil, nil
+	}
+
+	if !hm.readable(image.keyPointer()) {
+		return nil, fmt.Errorf("%s merge definition not readable yet", key)
+	}
+	_, data := hm.dynamicValue.Get(image.keyPointer())
+	mdata := MergeData(data)
+
+	def := &MergeDefinition{
+		Op: mdata.getOp(),
+	}
+	for _, sourceSlot := range mdata.keys() {
+		source := read(int64(sourceSlot))
+		if source.timestamp() == 0 {
+			return nil, fmt.Errorf("%s derrived key never written", key)
+		}
+		if !hm.readable(source.keyPointer()) {
+			return nil, fmt.Errorf("%s derrived key not readable yet", key)
+		}
+
+		def.Sources = append(def.Sources, string(hm.dynamicValue.keyAt(source.keyPointer())))
+	}
+	sort.Strings(def.Sources)
+
+	return def, nil
+}
+
 func (hm *HashMapCounter) Merge(op MergeOps, kind reflect.Kind, computedKey string, keys ...string) (any, error) {
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	return hm.mergeLocked(op, kind, computedKey, keys...)
+}
+
+// keyStored check slot hold key, must called with lock held
+func (hm *HashMapCounter) keyStored(key string, slot int64) bool {
+	if hm.slotTimestamp(slot) == 0 {
+		return false
+	}
+	return string(hm.dynamicValue.keyAt(hm.slot(slot).keyPointer())) == key
+}
+
+// mergeLocked must called with lock held
+func (hm *HashMapCounter) mergeLocked(op MergeOps, kind reflect.Kind, computedKey string, keys ...string) (any, error) {
+	if hm.readOnly {
+		return 0, ErrReadOnly
+	}
+
 	hkey := hm.hash.hash(computedKey)
 	offset := hkey + HASHMAP_METADATA_SIZE
 
@@ -98,29 +182,38 @@ func (hm *HashMapCounter) Merge(op MergeOps, kind reflect.Kind, computedKey stri
 		return 0, fmt.Errorf("derrived key %s empty", computedKey)
 	}
 
+	switch kind {
+	case reflect.Uint64, reflect.Int64, reflect.Float64:
+	default:
+		return 0, fmt.Errorf("derrived key %s kind %s not supported", computedKey, kind)
+	}
+
 	mergeData := NewMergeData(derivedKeyLen)
 	mergeData.setOp(op)
 
-	// // generating key hash offset
+	// // generating key hash offset, source checked before anything written
 	derrivedKeys := make([]int64, len(keys))
 	for i, key := range keys {
 		if key == "" {
 			return 0, errors.New("key have empty string")
 		}
 		dhkey := hm.hash.hash(key)
+		if !hm.keyStored(key, dhkey) {
+			return 0, fmt.Errorf("derrived key %s source %s not found", computedKey, key)
+		}
 		derrivedKeys[i] = dhkey
 	}
 
 	mergeData.setHashKeys(hm.hash, derrivedKeys)
 
-	hm.lock.Lock()
-	defer hm.lock.Unlock()
-
+	hm.preserveSlot(hkey)
 	lastts := binary.LittleEndian.Uint64(hm.data[offset+TIMESTAMP_OFFSET : offset+TIMESTAMP_OFFSET+8])
 
-	t := time.Now().UnixMilli()
-	ts := uint64(t)
+	now := time.Now()
+	ts := uint64(now.UnixMilli())
+	hm.ops.record(opMerge, now)
 
+	var old any
 	if lastts == 0 {
 		hm.keyCount += 1
 		setCurrentCount(hm.data, hm.keyCount)
@@ -150,6 +243,7 @@ func (hm *HashMapCounter) Merge(op MergeOps, kind reflect.Kind, computedKey stri
 
 		// set key pointer
 		binary.LittleEndian.PutUint64(hm.data[offset+KEY_POINTER_OFFSET:offset+KEY_POINTER_OFFSET+8], uint64(keyOffset))
+		hm.index.insert(computedKey, hkey)
 
 	} else {
 		// checking keyhash before
@@ -168,6 +262,8 @@ func (hm *HashMapCounter) Merge(op MergeOps, kind reflect.Kind, computedKey stri
 		if existKind != kind {
 			return 0, fmt.Errorf("%s derrived counter type inconsistent", computedKey)
 		}
+
+		_, old = hm.slotValue(hkey)
 	}
 
 	// set timestamp
@@ -196,6 +292,7 @@ func (hm *HashMapCounter) Merge(op MergeOps, kind reflect.Kind, computedKey stri
 
 	}
 	binary.LittleEndian.PutUint64(hm.data[offset+COUNTER_OFFSET:offset+COUNTER_OFFSET+8], accvalue.getUint64())
+	hm.publish(computedKey, existKind, old, accvalue.getValue(), now)
 
 	return accvalue.getValue(), nil
 }
@@ -2,33 +2,59 @@ package stream_core
 
 import (
 	"os"
+	"time"
 )
 
 type CoreConfig struct {
-	// WalDir           string
+	// WalDir wal of batch and committed source offset, must removed together with counter file.
+	// empty disable ApplyBatch
+	WalDir string
 	// WalSerialization wal_message.WalSerialization
 
 	HashMapCounterPath string
 	// must n^2 for the size
 	HashMapCounterSlots uint64
 	DynamicValuePath    string
+	// ordered key index file, empty keep index only in memory
+	IndexPath string
+	// ReadOnly map file read only without truncating, key written by other process followed on read.
+	// wal and dedupe not opened, write return ErrReadOnly and snapshot not isolated from writer
+	ReadOnly bool
+
+	// DedupePath event id set of IncWithID, empty disable IncWithID
+	DedupePath string
+	// DedupeHorizon event id seen within horizon always dropped
+	DedupeHorizon time.Duration
+	// DedupeExpectedIDs and DedupeFalsePositive sizing bloom filter of one horizon
+	DedupeExpectedIDs   uint64
+	DedupeFalsePositive float64
+	// DedupeWindow exact recent id entry, must n^2
+	DedupeWindow uint64
 }
 
 func NewDefaultCoreConfig() *CoreConfig {
 	return &CoreConfig{
-		// WalDir:              "/tmp/stream_engine/wal",
+		WalDir:              "/tmp/stream_engine/wal",
 		HashMapCounterPath:  "/tmp/stream_engine/hm_counter",
 		HashMapCounterSlots: 536_870_912,
 		DynamicValuePath:    "/tmp/stream_engine/dynamic_value",
+		IndexPath:           "/tmp/stream_engine/key_index",
+		DedupePath:          "/tmp/stream_engine/dedupe",
+		DedupeHorizon:       DefaultDedupeHorizon,
 	}
 }
 
 func NewDefaultCoreConfigTest() *CoreConfig {
 	return &CoreConfig{
-		// WalDir:              "/tmp/stream_engine/wal_test",
+		WalDir:              "/tmp/stream_engine/wal_test",
 		HashMapCounterPath:  "/tmp/stream_engine/hm_counter_test",
 		HashMapCounterSlots: 32,
 		DynamicValuePath:    "/tmp/stream_engine/dynamic_value_test",
+		IndexPath:           "/tmp/stream_engine/key_index_test",
+		DedupePath:          "/tmp/stream_engine/dedupe_test",
+		DedupeHorizon:       time.Hour,
+		DedupeExpectedIDs:   10_000,
+		DedupeWindow:        1 << 12,
 	}
 }
 
@@ -0,0 +1,367 @@
+package stream_core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/cespare/xxhash"
+	"github.com/edsrzf/mmap-go"
+)
+
+/*
+structured dedupe set
+| 64 byte header | bloom generation 0 | bloom generation 1 | recent window
+
+header
+| 8 byte magic | 8 byte horizon ms | 8 byte bloom bits | 8 byte bloom hash count | 8 byte window size
+| 8 byte generation 0 start ms | 8 byte generation 1 start ms | 8 byte current generation
+
+recent window entry
+| 8 byte id fingerprint | 8 byte seen at ms
+
+note:
+	- each bloom generation cover one horizon, so id seen within horizon always found
+	- window probe limited to DEDUPE_WINDOW_PROBE entry, oldest entry replaced when all used
+*/
+
+const (
+	DEDUPE_MAGIC              = 0x3130455055444544 // "DEDUPE01"
+	DEDUPE_HEADER_SIZE        = 64
+	DEDUPE_HORIZON_OFFSET     = 8
+	DEDUPE_BITS_OFFSET        = 16
+	DEDUPE_HASH_COUNT_OFFSET  = 24
+	DEDUPE_WINDOW_SIZE_OFFSET = 32
+	DEDUPE_GENERATION_OFFSET  = 40
+	DEDUPE_CURRENT_OFFSET     = 56
+	DEDUPE_WINDOW_ENTRY_SIZE  = 16
+	DEDUPE_WINDOW_PROBE       = 8
+)
+
+const (
+	DefaultDedupeHorizon       = 24 * time.Hour
+	DefaultDedupeExpectedIDs   = 1_000_000
+	DefaultDedupeFalsePositive = 0.0001
+	DefaultDedupeWindow        = 1 << 20
+)
+
+var ErrDedupeDisabled = errors.New("dedupe path not configured")
+
+type DedupeStats struct {
+	// Hits duplicate found in recent window
+	Hits uint64
+	// BloomHits duplicate only found by bloom filter, can be false positive
+	BloomHits uint64
+	// Misses id seen first time
+	Misses uint64
+}
+
+// dedupeSet bloom filter of two rotating generation plus exact recent window, must used with counter lock held
+type dedupeSet struct {
+	f          *os.File
+	data       mmap.MMap
+	horizon    int64
+	bits       uint64
+	hashCount  uint64
+	windowSize uint64
+	bloomSize  int64
+
+	hits      atomic.Uint64
+	bloomHits atomic.Uint64
+	misses    atomic.Uint64
+	probe     probeMeter
+}
+
+func openDedupeSet(cfg *CoreConfig) (*dedupeSet, error) {
+	horizon := cfg.DedupeHorizon
+	if horizon <= 0 {
+		horizon = DefaultDedupeHorizon
+	}
+	expected := cfg.DedupeExpectedIDs
+	if expected == 0 {
+		expected = DefaultDedupeExpectedIDs
+	}
+	fp := cfg.DedupeFalsePositive
+	if fp <= 0 || fp >= 1 {
+		fp = DefaultDedupeFalsePositive
+	}
+	window := cfg.DedupeWindow
+	if window == 0 {
+		window = DefaultDedupeWindow
+	}
+	if window&(window-1) != 0 {
+		return nil, fmt.Errorf("dedupe window %d must power of 2", window)
+	}
+
+	// optimal bloom size for expected id per horizon, rounded to 8 byte
+	bits := uint64(math.Ceil(-float64(expected) * math.Log(fp) / (math.Ln2 * math.Ln2)))
+	bits = (bits + 63) / 64 * 64
+	hashCount := uint64(math.Max(1, math.Round(float64(bits)/float64(expected)*math.Ln2)))
+
+	d := &dedupeSet{
+		horizon:    horizon.Milliseconds(),
+		bits:       bits,
+		hashCount:  hashCount,
+		windowSize: window,
+		bloomSize:  int64(bits / 8),
+	}
+	size := DEDUPE_HEADER_SIZE + 2*d.bloomSize + int64(window*DEDUPE_WINDOW_ENTRY_SIZE)
+
+	f, err := os.OpenFile(cfg.DedupePath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	isnew := info.Size() == 0
+	if !isnew && info.Size() != size {
+		f.Close()
+		return nil, fmt.Errorf("dedupe file %s created with different config", cfg.DedupePath)
+	}
+	if isnew {
+		err = f.Truncate(size)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	d.f = f
+	d.data, err = mmap.Map(f, mmap.RDWR, 0)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if isnew {
+		d.putHeader(0, DEDUPE_MAGIC)
+		d.putHeader(DEDUPE_HORIZON_OFFSET, uint64(d.horizon))
+		d.putHeader(DEDUPE_BITS_OFFSET, bits)
+		d.putHeader(DEDUPE_HASH_COUNT_OFFSET, hashCount)
+		d.putHeader(DEDUPE_WINDOW_SIZE_OFFSET, window)
+		return d, nil
+	}
+
+	if d.header(0) != DEDUPE_MAGIC ||
+		d.header(DEDUPE_HORIZON_OFFSET) != uint64(d.horizon) ||
+		d.header(DEDUPE_BITS_OFFSET) != bits ||
+		d.header(DEDUPE_HASH_COUNT_OFFSET) != hashCount ||
+		d.header(DEDUPE_WINDOW_SIZE_OFFSET) != window {
+		d.close()
+		return nil, fmt.Errorf("dedupe file %s created with different config", cfg.DedupePath)
+	}
+	return d, nil
+}
+
+func (d *dedupeSet) header(offset int) uint64 {
+	return binary.LittleEndian.Uint64(d.data[offset : offset+8])
+}
+
+func (d *dedupeSet) putHeader(offset int, value uint64) {
+	binary.LittleEndian.PutUint64(d.data[offset:offset+8], value)
+}
+
+func (d *dedupeSet) generation(gen uint64) []byte {
+	start := DEDUPE_HEADER_SIZE + int64(gen)*d.bloomSize
+	return d.data[start : start+d.bloomSize]
+}
+
+func (d *dedupeSet) generationStart(gen uint64) int64 {
+	return int64(d.header(DEDUPE_GENERATION_OFFSET + int(gen)*8))
+}
+
+// rotate start new generation when current one older than horizon
+func (d *dedupeSet) rotate(now int64) uint64 {
+	current := d.header(DEDUPE_CURRENT_OFFSET)
+	start := d.generationStart(current)
+	if start != 0 && now-start < d.horizon {
+		return current
+	}
+
+	if start != 0 {
+		current = 1 - current
+		clear(d.generation(current))
+		d.putHeader(DEDUPE_CURRENT_OFFSET, current)
+	}
+	d.putHeader(DEDUPE_GENERATION_OFFSET+int(current)*8, uint64(now))
+	return current
+}
+
+// seen check id and remember it, true when id already seen within horizon
+func (d *dedupeSet) seen(id string, now int64) bool {
+	h := xxhash.Sum64String(id)
+	// zero fingerprint mark empty window entry
+	fp := h | 1
+
+	found, expired := d.windowSeen(fp, now)
+	if found {
+		d.hits.Add(1)
+		return true
+	}
+
+	// id known older than horizon skip bloom
+	current := d.rotate(now)
+	if !expired && d.bloomSeen(h, current, now) {
+		d.bloomHits.Add(1)
+		return true
+	}
+
+	d.misses.Add(1)
+	d.bloomAdd(h, current)
+	d.windowAdd(fp, now)
+	return false
+}
+
+func (d *dedupeSet) bloomIndex(h uint64, i uint64) uint64 {
+	// double hashing from both half of hash
+	a := h & 0xffffffff
+	b := (h >> 32) | 1
+	return (a + i*b) % d.bits
+}
+
+func (d *dedupeSet) bloomSeen(h uint64, current uint64, now int64) bool {
+	for _, gen := range []uint64{current, 1 - current} {
+		start := d.generationStart(gen)
+		// generation older than two horizon never rotated while idle
+		if start == 0 || now-start >= 2*d.horizon {
+			continue
+		}
+
+		bloom := d.generation(gen)
+		found := true
+		for i := uint64(0); i < d.hashCount; i++ {
+			idx := d.bloomIndex(h, i)
+			if bloom[idx/8]&(1<<(idx%8)) == 0 {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *dedupeSet) bloomAdd(h uint64, current uint64) {
+	bloom := d.generation(current)
+	for i := uint64(0); i < d.hashCount; i++ {
+		idx := d.bloomIndex(h, i)
+		bloom[idx/8] |= 1 << (idx % 8)
+	}
+}
+
+func (d *dedupeSet) windowEntry(i uint64) []byte {
+	start := DEDUPE_HEADER_SIZE + 2*d.bloomSize + int64((i&(d.windowSize-1))*DEDUPE_WINDOW_ENTRY_SIZE)
+	return d.data[start : start+DEDUPE_WINDOW_ENTRY_SIZE]
+}
+
+// windowSeen found when id in window within horizon, expired when id in window but older than horizon
+func (d *dedupeSet) windowSeen(fp uint64, now int64) (found bool, expired bool) {
+	for i := uint64(0); i < DEDUPE_WINDOW_PROBE; i++ {
+		entry := d.windowEntry(fp + i)
+		efp := binary.LittleEndian.Uint64(entry[0:8])
+		if efp == 0 {
+			d.probe.record(i + 1)
+			return false, false
+		}
+		if efp == fp {
+			d.probe.record(i + 1)
+			if now-int64(binary.LittleEndian.Uint64(entry[8:16])) < d.horizon {
+				return true, false
+			}
+			return false, true
+		}
+	}
+	d.probe.record(DEDUPE_WINDOW_PROBE)
+	return false, false
+}
+
+func (d *dedupeSet) windowAdd(fp uint64, now int64) {
+	var target []byte
+	oldest := int64(math.MaxInt64)
+	for i := uint64(0); i < DEDUPE_WINDOW_PROBE; i++ {
+		entry := d.windowEntry(fp + i)
+		efp := binary.LittleEndian.Uint64(entry[0:8])
+		ts := int64(binary.LittleEndian.Uint64(entry[8:16]))
+		if efp == 0 || efp == fp || now-ts >= d.horizon {
+			target = entry
+			break
+		}
+		if ts < oldest {
+			oldest = ts
+			target = entry
+		}
+	}
+
+	binary.LittleEndian.PutUint64(target[0:8], fp)
+	binary.LittleEndian.PutUint64(target[8:16], uint64(now))
+}
+
+func (d *dedupeSet) stats() DedupeStats {
+	return DedupeStats{
+		Hits:      d.hits.Load(),
+		BloomHits: d.bloomHits.Load(),
+		Misses:    d.misses.Load(),
+	}
+}
+
+func (d *dedupeSet) close() error {
+	err := d.data.Flush()
+	if err != nil {
+		return err
+	}
+
+	err = d.data.Unmap()
+	if err != nil {
+		return err
+	}
+	return d.f.Close()
+}
+
+// IncWithID increment counter once per event id, event seen again within dedupe horizon is dropped.
+// return false when event dropped as duplicate
+func (hm *HashMapCounter) IncWithID(eventID string, key string, delta any) (bool, error) {
+	if hm.readOnly {
+		return false, ErrReadOnly
+	}
+	if hm.dedupe == nil {
+		return false, ErrDedupeDisabled
+	}
+	if eventID == "" {
+		return false, errors.New("event id empty")
+	}
+
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	// rejected update not remembered, so fixed retry still applied
+	err := hm.checkKind(key, delta)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now().UnixMilli()
+	if hm.dedupe.seen(eventID, now) {
+		return false, nil
+	}
+
+	hm.applyLocked(key, delta, false)
+	return true, nil
+}
+
+// DedupeStats hit and miss of IncWithID since counter opened
+func (hm *HashMapCounter) DedupeStats() DedupeStats {
+	if hm.dedupe == nil {
+		return DedupeStats{}
+	}
+	return hm.dedupe.stats()
+}
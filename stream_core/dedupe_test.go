@@ -0,0 +1,131 @@
+package stream_core_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func TestIncWithID(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/dedupe_counter_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/dedupe_value_unittest",
+		DedupePath:          "/tmp/stream_engine/dedupe_unittest",
+		DedupeHorizon:       time.Hour,
+		DedupeExpectedIDs:   10_000,
+		DedupeWindow:        1 << 10,
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+	os.Remove(cfg.DedupePath)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+
+	t.Run("testing duplicate dropped", func(t *testing.T) {
+		applied, err := kv.IncWithID("trx-1", "accounts/1/debit", int64(10))
+		assert.Nil(t, err)
+		assert.True(t, applied)
+
+		applied, err = kv.IncWithID("trx-1", "accounts/1/debit", int64(10))
+		assert.Nil(t, err)
+		assert.False(t, applied)
+
+		applied, err = kv.IncWithID("trx-2", "accounts/1/debit", int64(5))
+		assert.Nil(t, err)
+		assert.True(t, applied)
+
+		assert.Equal(t, int64(15), kv.GetInt64("accounts/1/debit"))
+		assert.Equal(t, stream_core.DedupeStats{Hits: 1, Misses: 2}, kv.DedupeStats())
+	})
+
+	t.Run("testing kind mismatch error", func(t *testing.T) {
+		_, err := kv.IncWithID("trx-3", "accounts/1/debit", 1.5)
+		assert.ErrorIs(t, err, stream_core.ErrKindMismatch)
+
+		// rejected event not remembered
+		applied, err := kv.IncWithID("trx-3", "accounts/1/debit", int64(0))
+		assert.Nil(t, err)
+		assert.True(t, applied)
+
+		_, err = kv.IncWithID("trx-4", "accounts/1/debit", "1")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("testing id evicted from window found by bloom", func(t *testing.T) {
+		// window only keep 1024 id
+		for i := 0; i < 5000; i++ {
+			kv.IncWithID(fmt.Sprintf("bulk-%d", i), "bulk_count", uint64(1))
+		}
+		before := kv.DedupeStats()
+
+		dropped := 0
+		for i := 0; i < 5000; i++ {
+			applied, _ := kv.IncWithID(fmt.Sprintf("bulk-%d", i), "bulk_count", uint64(1))
+			if !applied {
+				dropped++
+			}
+		}
+		assert.Equal(t, 5000, dropped)
+		assert.Equal(t, uint64(5000), kv.GetUint64("bulk_count"))
+
+		after := kv.DedupeStats()
+		assert.Greater(t, after.BloomHits, before.BloomHits)
+	})
+
+	t.Run("testing dedupe persisted after reopen", func(t *testing.T) {
+		assert.Nil(t, kv.Close())
+		kv, err = stream_core.NewHashMapCounter(&cfg)
+		assert.Nil(t, err)
+
+		applied, err := kv.IncWithID("trx-1", "accounts/1/debit", int64(10))
+		assert.Nil(t, err)
+		assert.False(t, applied)
+		assert.Equal(t, int64(15), kv.GetInt64("accounts/1/debit"))
+		assert.Nil(t, kv.Close())
+	})
+
+	t.Run("testing config changed rejected", func(t *testing.T) {
+		changed := cfg
+		changed.DedupeWindow = 1 << 11
+		_, err := stream_core.NewHashMapCounter(&changed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("testing id forgotten after horizon", func(t *testing.T) {
+		short := cfg
+		short.DedupePath = "/tmp/stream_engine/dedupe_short_unittest"
+		short.DedupeHorizon = 50 * time.Millisecond
+		os.Remove(short.DedupePath)
+
+		hm, err := stream_core.NewHashMapCounter(&short)
+		assert.Nil(t, err)
+		defer hm.Close()
+
+		applied, _ := hm.IncWithID("trx-9", "late", int64(1))
+		assert.True(t, applied)
+		applied, _ = hm.IncWithID("trx-9", "late", int64(1))
+		assert.False(t, applied)
+
+		time.Sleep(120 * time.Millisecond)
+		applied, _ = hm.IncWithID("trx-9", "late", int64(1))
+		assert.True(t, applied)
+		assert.Equal(t, int64(2), hm.GetInt64("late"))
+	})
+
+	t.Run("testing dedupe disabled", func(t *testing.T) {
+		noDedupe := cfg
+		noDedupe.DedupePath = ""
+		hm, err := stream_core.NewHashMapCounter(&noDedupe)
+		assert.Nil(t, err)
+		defer hm.Close()
+
+		_, err = hm.IncWithID("trx-1", "accounts/1/debit", int64(1))
+		assert.ErrorIs(t, err, stream_core.ErrDedupeDisabled)
+	})
+}
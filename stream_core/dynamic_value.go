@@ -3,6 +3,7 @@ package stream_core
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"os"
 	"sync"
 
@@ -85,8 +86,58 @@ func NewDynamicValue(cfg *CoreConfig) (*DynamicValue, error) {
 	}, nil
 }
 
+// openDynamicValueReadOnly map dynamic value file written by other process, file never created or grown
+func openDynamicValueReadOnly(cfg *CoreConfig) (*DynamicValue, error) {
+	f, err := os.Open(cfg.DynamicValuePath)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DynamicValue{f: f}
+	err = d.remap()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	d.currentOffset = min(getCurrentOffset(d.data), d.filesize)
+	return d, nil
+}
+
+// remap map read only file again with its current size, writer grow file when dynamic value full
+func (d *DynamicValue) remap() error {
+	info, err := d.f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < DYNAMIC_METADATA_SIZE {
+		return fmt.Errorf("%s size %d is not dynamic value file", d.f.Name(), info.Size())
+	}
+
+	if d.data != nil {
+		err = d.data.Unmap()
+		if err != nil {
+			return err
+		}
+		d.data = nil
+	}
+
+	d.data, err = mmap.Map(d.f, mmap.RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	d.filesize = info.Size()
+	return nil
+}
+
 func (d *DynamicValue) Iterate(handler func(key string, hash int64, data []byte) error) error {
-	var offset int64 = DYNAMIC_METADATA_SIZE
+	return d.iterateFrom(DYNAMIC_METADATA_SIZE, func(offset int64, key string, hash int64, data []byte) error {
+		return handler(key, hash, data)
+	})
+}
+
+// iterateFrom iterate body dynamic start at offset, offset must be start of body dynamic
+func (d *DynamicValue) iterateFrom(offset int64, handler func(offset int64, key string, hash int64, data []byte) error) error {
 	var err error
 
 	for {
@@ -105,7 +156,7 @@ func (d *DynamicValue) Iterate(handler func(key string, hash int64, data []byte)
 		data := d.data[offset+DATA_OFFSET+keylen : offset+DATA_OFFSET+keylen+datalen]
 
 		// log.Printf("[%d] ddkey : %s %s\n", offset, string(key), data)
-		err = handler(string(key), int64(keyhash), data)
+		err = handler(offset, string(key), int64(keyhash), data)
 		if err != nil {
 			if errors.Is(err, ErrBreakDynamicRead) {
 				return nil
@@ -132,6 +183,12 @@ func (d *DynamicValue) Get(offset int64) (string, []byte) {
 	return string(key), data
 }
 
+// keyAt get key without copy, only valid until next write
+func (d *DynamicValue) keyAt(offset int64) []byte {
+	keylen := int64(binary.LittleEndian.Uint64(d.data[offset+KEY_LEN_OFFSET : offset+KEY_LEN_OFFSET+8]))
+	return d.data[offset+DATA_OFFSET : offset+DATA_OFFSET+keylen]
+}
+
 func (d *DynamicValue) GetData(offset int64) []byte {
 	keylen := int64(binary.LittleEndian.Uint64(d.data[offset+KEY_LEN_OFFSET : offset+KEY_LEN_OFFSET+8]))
 	return d.data[offset+keylen+DATA_OFFSET : offset+keylen+DATA_OFFSET+8]
@@ -175,6 +232,22 @@ func (d *DynamicValue) Write(key string, keyhash int64, data []byte) (int64, err
 	return currentoffset, nil
 }
 
+// truncate drop body dynamic written after offset
+func (d *DynamicValue) truncate(offset int64) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.currentOffset = offset
+	setCurrentOffset(d.data, offset)
+}
+
+// Offset end of last body dynamic
+func (d *DynamicValue) Offset() int64 {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.currentOffset
+}
+
 func (d *DynamicValue) increaseSize() error {
 	err := d.data.Flush()
 	if err != nil {
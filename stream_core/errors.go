@@ -0,0 +1,87 @@
+package stream_core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var ErrKindMismatch = errors.New("counter kind mismatch")
+
+// KindMismatchError update kind different from kind of existing counter
+type KindMismatchError struct {
+	Key      string
+	Kind     reflect.Kind
+	Existing reflect.Kind
+}
+
+func (e *KindMismatchError) Error() string {
+	return fmt.Sprintf("%s counter kind %s not match %s", e.Key, e.Existing, e.Kind)
+}
+
+func (e *KindMismatchError) Is(target error) bool {
+	return target == ErrKindMismatch
+}
+
+// checkKind must called with lock held
+func (hm *HashMapCounter) checkKind(key string, value any) error {
+	if key == "" {
+		return errors.New("key have empty string")
+	}
+
+	var kind reflect.Kind
+	switch value.(type) {
+	case int64:
+		kind = reflect.Int64
+	case uint64:
+		kind = reflect.Uint64
+	case float64:
+		kind = reflect.Float64
+	default:
+		return fmt.Errorf("%s counter typedata %T not supported", key, value)
+	}
+
+	slot := hm.hash.hash(key)
+	if hm.slotTimestamp(slot) == 0 {
+		return nil
+	}
+	existing, _ := hm.slotValue(slot)
+	if existing != kind {
+		return &KindMismatchError{Key: key, Kind: kind, Existing: existing}
+	}
+	return nil
+}
+
+// Inc add delta of int64, uint64 or float64 to counter, kind mismatch returned as error instead of panic
+func (hm *HashMapCounter) Inc(key string, delta any) (any, error) {
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	err := hm.writable()
+	if err != nil {
+		return nil, err
+	}
+
+	err = hm.checkKind(key, delta)
+	if err != nil {
+		return nil, err
+	}
+	return hm.applyLocked(key, delta, false), nil
+}
+
+// Put replace counter with value of int64, uint64 or float64, kind mismatch returned as error instead of panic
+func (hm *HashMapCounter) Put(key string, value any) (any, error) {
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	err := hm.writable()
+	if err != nil {
+		return nil, err
+	}
+
+	err = hm.checkKind(key, value)
+	if err != nil {
+		return nil, err
+	}
+	return hm.applyLocked(key, value, true), nil
+}
@@ -0,0 +1,155 @@
+package stream_core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var ErrLocked = errors.New("counter locked by other writer")
+
+// errWouldBlock lock file held by other open file
+var errWouldBlock = errors.New("lock file held")
+
+// maxLockAttempts open of lock file replaced by other process between open and lock
+const maxLockAttempts = 3
+
+// LockedError counter file opened for writing by other process, PID zero when holder not written yet
+type LockedError struct {
+	Path string
+	PID  int
+}
+
+func (e *LockedError) Error() string {
+	if e.PID == 0 {
+		return fmt.Sprintf("%s locked by other writer", e.Path)
+	}
+	return fmt.Sprintf("%s locked by writer pid %d", e.Path, e.PID)
+}
+
+func (e *LockedError) Is(target error) bool {
+	return target == ErrLocked
+}
+
+// fileLock advisory lock of single writer, lock file hold pid of writer
+type fileLock struct {
+	f    *os.File
+	path string
+}
+
+// lockPath lock file beside counter file
+func lockPath(cfg *CoreConfig) string {
+	return cfg.HashMapCounterPath + ".lock"
+}
+
+// acquireFileLock lock path without waiting. lock of dead process still held by inherited descriptor
+// is stale, lock file removed and locked again
+func acquireFileLock(path string) (*fileLock, error) {
+	var cleared bool
+	for attempt := 0; attempt < maxLockAttempts; attempt++ {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		err = lockFile(f)
+		if errors.Is(err, errWouldBlock) {
+			pid := readLockPID(f)
+			f.Close()
+			if cleared || pid == 0 || processAlive(pid) {
+				return nil, &LockedError{Path: path, PID: pid}
+			}
+
+			log.Printf("clearing stale lock %s of dead pid %d", path, pid)
+			err = os.Remove(path)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			cleared = true
+			continue
+		}
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+
+		// lock file removed as stale by other process after opened, locking removed file lock nothing
+		same, err := sameFile(f, path)
+		if err != nil || !same {
+			unlockFile(f)
+			f.Close()
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// lock released on crash, pid left behind is only stale
+		pid := readLockPID(f)
+		if pid != 0 && pid != os.Getpid() {
+			log.Printf("clearing stale lock %s of pid %d", path, pid)
+		}
+
+		l := &fileLock{f: f, path: path}
+		err = l.writePID()
+		if err != nil {
+			l.release()
+			return nil, err
+		}
+		return l, nil
+	}
+
+	return nil, fmt.Errorf("%s replaced while locking", path)
+}
+
+func (l *fileLock) writePID() error {
+	err := l.f.Truncate(0)
+	if err != nil {
+		return err
+	}
+
+	_, err = l.f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	if err != nil {
+		return err
+	}
+	return l.f.Sync()
+}
+
+// release clear pid and unlock, lock file kept so waiting process always lock same file
+func (l *fileLock) release() error {
+	return errors.Join(
+		l.f.Truncate(0),
+		unlockFile(l.f),
+		l.f.Close(),
+	)
+}
+
+// readLockPID pid written in lock file, zero when empty or unreadable
+func readLockPID(f *os.File) int {
+	buf := make([]byte, 32)
+	n, _ := f.ReadAt(buf, 0)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(buf[:n])))
+	if err != nil || pid < 0 {
+		return 0
+	}
+	return pid
+}
+
+func sameFile(f *os.File, path string) (bool, error) {
+	opened, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	current, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return os.SameFile(opened, current), nil
+}
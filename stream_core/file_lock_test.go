@@ -0,0 +1,69 @@
+package stream_core_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func TestFileLock(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/file_lock_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/file_lock_value_unittest",
+	}
+	lockPath := cfg.HashMapCounterPath + ".lock"
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+	os.Remove(lockPath)
+
+	t.Run("testing second writer rejected", func(t *testing.T) {
+		kv, err := stream_core.NewHashMapCounter(&cfg)
+		assert.Nil(t, err)
+		kv.IncInt64("teams/12/debit", 10)
+
+		_, err = stream_core.NewHashMapCounter(&cfg)
+		assert.ErrorIs(t, err, stream_core.ErrLocked)
+		var locked *stream_core.LockedError
+		assert.True(t, errors.As(err, &locked))
+		assert.Equal(t, os.Getpid(), locked.PID)
+		assert.Contains(t, err.Error(), "pid "+strconv.Itoa(os.Getpid()))
+
+		_, err = stream_core.RepairCounter(&cfg)
+		assert.ErrorIs(t, err, stream_core.ErrLocked)
+
+		readCfg := cfg
+		readCfg.ReadOnly = true
+		reader, err := stream_core.NewHashMapCounter(&readCfg)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(10), reader.GetInt64("teams/12/debit"))
+		assert.Nil(t, reader.Close())
+
+		assert.Nil(t, kv.Close())
+
+		kv, err = stream_core.NewHashMapCounter(&cfg)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(10), kv.GetInt64("teams/12/debit"))
+		assert.Nil(t, kv.Close())
+	})
+
+	t.Run("testing stale lock cleared", func(t *testing.T) {
+		// pid of exited process left by crashed writer
+		cmd := exec.Command(os.Args[0], "-test.run=^$")
+		assert.Nil(t, cmd.Run())
+		assert.Nil(t, os.WriteFile(lockPath, []byte(strconv.Itoa(cmd.Process.Pid)+"\n"), 0644))
+
+		kv, err := stream_core.NewHashMapCounter(&cfg)
+		assert.Nil(t, err)
+
+		data, err := os.ReadFile(lockPath)
+		assert.Nil(t, err)
+		assert.Equal(t, strconv.Itoa(os.Getpid())+"\n", string(data))
+		assert.Nil(t, kv.Close())
+	})
+}
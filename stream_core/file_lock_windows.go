@@ -0,0 +1,43 @@
+//go:build windows
+
+package stream_core
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// stillActive exit code of running process
+const stillActive = 259
+
+// lockRange byte locked far after pid, locked byte cannot be read by other process
+func lockRange() *windows.Overlapped {
+	return &windows.Overlapped{OffsetHigh: 1}
+}
+
+func lockFile(f *os.File) error {
+	err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, lockRange())
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errWouldBlock
+	}
+	return err
+}
+
+func unlockFile(f *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, lockRange())
+}
+
+func processAlive(pid int) bool {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// access denied is process of other user
+		return errors.Is(err, windows.ERROR_ACCESS_DENIED)
+	}
+	defer windows.CloseHandle(h)
+
+	var code uint32
+	err = windows.GetExitCodeProcess(h, &code)
+	return err != nil || code == stillActive
+}
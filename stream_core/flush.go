@@ -0,0 +1,50 @@
+package stream_core
+
+import (
+	"os"
+	"slices"
+
+	"github.com/edsrzf/mmap-go"
+)
+
+// byteRange [from, to) of mapped file
+type byteRange struct {
+	from int64
+	to   int64
+}
+
+// flushPages flush only page of m touched by ranges, so batch touching few slot not sync whole mapping
+func flushPages(m mmap.MMap, f *os.File, ranges []byteRange) error {
+	page := int64(os.Getpagesize())
+	pages := []int64{}
+	for _, r := range ranges {
+		if r.to <= r.from {
+			continue
+		}
+		for p := r.from / page; p <= (r.to-1)/page; p++ {
+			pages = append(pages, p)
+		}
+	}
+	if len(pages) == 0 {
+		return nil
+	}
+	slices.Sort(pages)
+	pages = slices.Compact(pages)
+
+	// contiguous page flushed together
+	start := pages[0]
+	for i := 1; i <= len(pages); i++ {
+		if i < len(pages) && pages[i] == pages[i-1]+1 {
+			continue
+		}
+		err := flushRange(m, start*page, min((pages[i-1]+1)*page, int64(len(m))))
+		if err != nil {
+			return err
+		}
+		if i < len(pages) {
+			start = pages[i]
+		}
+	}
+
+	return syncMapped(f)
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package stream_core
+
+import (
+	"os"
+
+	"github.com/edsrzf/mmap-go"
+	"golang.org/x/sys/unix"
+)
+
+// flushRange write page of m in [from, to) to disk, from must page aligned
+func flushRange(m mmap.MMap, from, to int64) error {
+	return unix.Msync(m[from:to], unix.MS_SYNC)
+}
+
+// syncMapped no op, msync with MS_SYNC already wait for disk
+func syncMapped(f *os.File) error {
+	return nil
+}
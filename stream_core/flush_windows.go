@@ -0,0 +1,29 @@
+//go:build windows
+
+package stream_core
+
+import (
+	"os"
+	"unsafe"
+
+	"github.com/edsrzf/mmap-go"
+	"golang.org/x/sys/windows"
+)
+
+// flushRange write page of m in [from, to) to file, from must page aligned
+func flushRange(m mmap.MMap, from, to int64) error {
+	err := windows.FlushViewOfFile(uintptr(unsafe.Pointer(&m[from])), uintptr(to-from))
+	if err != nil {
+		return os.NewSyscallError("FlushViewOfFile", err)
+	}
+	return nil
+}
+
+// syncMapped wait flushed view written to disk
+func syncMapped(f *os.File) error {
+	err := windows.FlushFileBuffers(windows.Handle(f.Fd()))
+	if err != nil {
+		return os.NewSyscallError("FlushFileBuffers", err)
+	}
+	return nil
+}
@@ -2,11 +2,7 @@ package stream_core
 
 import (
 	"encoding/binary"
-	"log"
-	"math"
 	"os"
-	"reflect"
-	"sync"
 	"time"
 
 	"github.com/edsrzf/mmap-go"
@@ -47,18 +43,56 @@ const (
 	CounterKeyType
 	MergeKeyType
 	DynamicKeyType
+	// ReplaceKeyType counter key created by Put
+	ReplaceKeyType
 )
 
 type HashMapCounter struct {
-	lock         sync.Mutex
+	lock         timedMutex
 	hash         *hashKey
 	dynamicValue *DynamicValue
 	f            *os.File
 	data         mmap.MMap
 	keyCount     uint64
+	index        *keyIndex
+	snapshots    map[*SnapshotView]struct{}
+	feed         *changeFeed
+	// wal of batch, nil when wal dir not configured
+	wal        *WAL
+	walSegment uint64
+	offsets    map[string]int64
+	// dedupe of IncWithID, nil when dedupe path not configured
+	dedupe        *dedupeSet
+	ops           opMeter
+	snapshotStats SnapshotStats
+	// readOnly file mapped read only, following writer of other process
+	readOnly bool
+	// fileLock writer lock, nil for read only counter
+	fileLock *fileLock
 }
 
+// NewHashMapCounter open counter for writing, fail with LockedError when other process already writing
 func NewHashMapCounter(cfg *CoreConfig) (*HashMapCounter, error) {
+	if cfg.ReadOnly {
+		return openReadOnly(cfg)
+	}
+
+	// single writer per counter file, reader opened read only not locked
+	lock, err := acquireFileLock(lockPath(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	hm, err := openHashMapCounter(cfg)
+	if err != nil {
+		lock.release()
+		return nil, err
+	}
+	hm.fileLock = lock
+	return hm, nil
+}
+
+func openHashMapCounter(cfg *CoreConfig) (*HashMapCounter, error) {
 	size := int(cfg.HashMapCounterSlots*HASHMAP_SLOT_SIZE) + HASHMAP_METADATA_SIZE
 
 	f, err := os.OpenFile(cfg.HashMapCounterPath, os.O_RDWR|os.O_CREATE, 0644)
@@ -93,6 +127,15 @@ func NewHashMapCounter(cfg *CoreConfig) (*HashMapCounter, error) {
 		isnew = true
 	}
 
+	var wal *WAL
+	offsets := map[string]int64{}
+	if cfg.WalDir != "" {
+		wal, offsets, err = openBatchWal(cfg.WalDir, m, dynamic, isnew)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var currKeyCount uint64
 	if isnew {
 		currKeyCount = 0
@@ -102,18 +145,66 @@ func NewHashMapCounter(cfg *CoreConfig) (*HashMapCounter, error) {
 		currKeyCount = getCurrentCount(m)
 	}
 
-	return &HashMapCounter{
-		sync.Mutex{},
+	// ordered key index, only replaying key written after last checkpoint
+	index, err := openKeyIndex(cfg.IndexPath, dynamic)
+	if err != nil {
+		return nil, err
+	}
+
+	var dedupe *dedupeSet
+	if cfg.DedupePath != "" {
+		dedupe, err = openDedupeSet(cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	hm := &HashMapCounter{
+		timedMutex{},
 		hash,
 		dynamic,
 		f,
 		m,
 		currKeyCount,
-	}, nil
+		index,
+		map[*SnapshotView]struct{}{},
+		newChangeFeed(),
+		wal,
+		0,
+		offsets,
+		dedupe,
+		opMeter{},
+		SnapshotStats{},
+		false,
+		nil,
+	}
+	if wal != nil {
+		hm.walSegment = wal.Segment()
+	}
+	return hm, nil
 }
 
 func (d *HashMapCounter) Close() error {
-	err := d.dynamicValue.Close()
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.feed.closeAll()
+
+	// index file owned by writer process
+	var err error
+	if !d.readOnly {
+		err = d.checkpointIndex()
+		if err != nil {
+			return err
+		}
+	}
+
+	err = d.index.close()
+	if err != nil {
+		return err
+	}
+
+	err = d.dynamicValue.Close()
 	if err != nil {
 		return err
 	}
@@ -123,55 +214,56 @@ func (d *HashMapCounter) Close() error {
 		return err
 	}
 
+	if d.dedupe != nil {
+		err = d.dedupe.close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.wal != nil {
+		err = d.checkpointWal()
+		if err != nil {
+			return err
+		}
+
+		err = d.wal.Close()
+		if err != nil {
+			return err
+		}
+	}
+
 	err = d.data.Unmap()
 	if err != nil {
 		return err
 	}
 
 	err = d.f.Close()
-	return err
-}
+	if err != nil || d.fileLock == nil {
+		return err
+	}
 
-func (hm *HashMapCounter) Snapshot(t time.Time, handler func(key string, kind reflect.Kind, value any) error) error {
-	var err error
+	// released after file flushed and closed, so next writer see whole write
+	return d.fileLock.release()
+}
 
+// CheckpointIndex persist ordered key index, so next open not replaying whole dynamic value
+func (hm *HashMapCounter) CheckpointIndex() error {
 	hm.lock.Lock()
 	defer hm.lock.Unlock()
 
-	tsFilter := uint64(t.UnixMilli())
-	err = hm.dynamicValue.Iterate(func(key string, khash int64, data []byte) error {
-		// log.Println(khash, "offset hash")
-		offset := khash + HASHMAP_METADATA_SIZE
-		ts := binary.LittleEndian.Uint64(hm.data[offset+TIMESTAMP_OFFSET : offset+TIMESTAMP_OFFSET+8])
-
-		if ts < tsFilter {
-			// log.Println(key, time.UnixMilli(int64(ts)).String())
-			return nil
-		}
-
-		// getting value
-		value := binary.LittleEndian.Uint64(hm.data[offset+COUNTER_OFFSET : offset+COUNTER_OFFSET+8])
-
-		// getting type key
-		typeKey := reflect.Kind(hm.data[offset+HASHMAP_TYPE_COUNTER_OFFSET])
-		switch typeKey {
-		case reflect.Uint64:
-			err = handler(key, reflect.Uint64, value)
-		case reflect.Int64:
-			err = handler(key, reflect.Int64, int64(value))
-		case reflect.Float64:
-			err = handler(key, reflect.Float64, math.Float64frombits(value))
-		default:
-			err = handler(key, reflect.Uint64, value)
-		}
+	err := hm.writable()
+	if err != nil {
+		return err
+	}
+	return hm.checkpointIndex()
+}
 
-		if err != nil {
-			return err
-		}
-		return nil
+func (hm *HashMapCounter) checkpointIndex() error {
+	return hm.index.checkpoint(hm.dynamicValue.currentOffset, hm.dynamicValue, func(slot uint64) uint64 {
+		offset := slot + HASHMAP_METADATA_SIZE
+		return binary.LittleEndian.Uint64(hm.data[offset+KEY_POINTER_OFFSET : offset+KEY_POINTER_OFFSET+8])
 	})
-
-	return err
 }
 
 func (hm *HashMapCounter) ResetCounter() error {
@@ -179,20 +271,39 @@ func (hm *HashMapCounter) ResetCounter() error {
 	hm.lock.Lock()
 	defer hm.lock.Unlock()
 
+	err = hm.writable()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
 	err = hm.dynamicValue.Iterate(func(key string, khash int64, data []byte) error {
 		// log.Println(khash, "offset hash")
 		offset := khash + HASHMAP_METADATA_SIZE
+		hm.preserveSlot(khash)
+		kind, old := hm.slotValue(khash)
 		binary.LittleEndian.PutUint64(hm.data[offset+COUNTER_OFFSET:offset+COUNTER_OFFSET+8], 0)
+		_, current := hm.slotValue(khash)
+		hm.publish(key, kind, old, current, now)
 
 		return nil
 	})
+	if err != nil || hm.wal == nil {
+		return err
+	}
 
-	return err
-
+	// counter start from zero, ingestion must restart from beginning of source
+	err = hm.data.Flush()
+	if err != nil {
+		return err
+	}
+	clear(hm.offsets)
+	return hm.checkpointWal()
 }
 
-func (hm *HashMapCounter) PrintStat() {
-	log.Printf("key_count: %d", hm.keyCount)
+// Slots hashmap counter slot count
+func (hm *HashMapCounter) Slots() uint64 {
+	return hm.hash.cfg.HashMapCounterSlots
 }
 
 func getCurrentCount(m mmap.MMap) uint64 {
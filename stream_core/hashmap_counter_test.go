@@ -53,6 +53,28 @@ func TestHashmap(t *testing.T) {
 		})
 	})
 
+	t.Run("testing inc and put returning kind mismatch", func(t *testing.T) {
+		value, err := kv.Inc("product/reserved", int64(3))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), value)
+
+		value, err = kv.Put("product/reserved", int64(10))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(10), value)
+
+		_, err = kv.Inc("product/reserved", 1.5)
+		assert.ErrorIs(t, err, stream_core.ErrKindMismatch)
+
+		var mismatch *stream_core.KindMismatchError
+		assert.ErrorAs(t, err, &mismatch)
+		assert.Equal(t, reflect.Int64, mismatch.Existing)
+		assert.Equal(t, reflect.Float64, mismatch.Kind)
+
+		_, err = kv.Put("product/reserved", "10")
+		assert.NotNil(t, err)
+		assert.Equal(t, int64(10), kv.GetInt64("product/reserved"))
+	})
+
 	t.Run("testing computed key", func(t *testing.T) {
 		kv.IncInt64("product/pending_stock", int64(1))
 
@@ -66,16 +88,17 @@ func TestHashmap(t *testing.T) {
 		)
 		assert.NotNil(t, err, "tidak punya merge key")
 
-		// t.Run("testing merge non exist key", func(t *testing.T) {
-		// 	_, err = kv.Merge(stream_core.MergeOpAdd, reflect.Uint64, "product/all_stock_not_exist",
-		// 		"product/stock",
-		// 		"product/pending_stock_not_exist",
-		// 	)
-		// 	assert.Nil(t, err)
+		t.Run("testing merge non exist key", func(t *testing.T) {
+			_, err = kv.Merge(stream_core.MergeOpAdd, reflect.Uint64, "product/all_stock_not_exist",
+				"product/stock",
+				"product/pending_stock_not_exist",
+			)
+			assert.ErrorContains(t, err, "product/pending_stock_not_exist")
 
-		// 	value := kv.GetUint64("product/all_stock")
-		// 	assert.Equal(t, uint64(3), value)
-		// })
+			_, ok, err := kv.GetRecord("product/all_stock_not_exist")
+			assert.Nil(t, err)
+			assert.False(t, ok)
+		})
 
 		t.Run("testing merge normal", func(t *testing.T) {
 			_, err = kv.Merge(stream_core.MergeOpAdd, reflect.Uint64, "product/all_stock",
@@ -0,0 +1,176 @@
+package stream_core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/edsrzf/mmap-go"
+)
+
+/*
+structured index run, entry sorted by key
+| 8 byte entry count | 8 byte dynamic value watermark | entries
+
+entry
+| 8 byte key pointer to dynamic value | 8 byte hashmap slot |
+
+note:
+	- watermark: dynamic value current offset when run written, key written
+	  after watermark is replayed from dynamic value when opening
+*/
+
+const (
+	INDEX_RUN_METADATA_SIZE      = 16
+	INDEX_RUN_COUNT_OFFSET       = 0
+	INDEX_RUN_WATERMARK_OFFSET   = 8
+	INDEX_RUN_ENTRY_SIZE         = 16
+	INDEX_RUN_KEY_POINTER_OFFSET = 0
+	INDEX_RUN_SLOT_OFFSET        = 8
+)
+
+type indexRun struct {
+	f         *os.File
+	data      mmap.MMap
+	count     int
+	watermark int64
+	dynamic   *DynamicValue
+}
+
+// openIndexRun return nil run when file not exist
+func openIndexRun(path string, dynamic *DynamicValue) (*indexRun, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if info.Size() < INDEX_RUN_METADATA_SIZE {
+		f.Close()
+		return nil, fmt.Errorf("index run %s too small", path)
+	}
+
+	m, err := mmap.Map(f, mmap.RDONLY, 0)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	count := int(binary.LittleEndian.Uint64(m[INDEX_RUN_COUNT_OFFSET : INDEX_RUN_COUNT_OFFSET+8]))
+	watermark := int64(binary.LittleEndian.Uint64(m[INDEX_RUN_WATERMARK_OFFSET : INDEX_RUN_WATERMARK_OFFSET+8]))
+
+	run := &indexRun{
+		f:         f,
+		data:      m,
+		count:     count,
+		watermark: watermark,
+		dynamic:   dynamic,
+	}
+
+	if int64(INDEX_RUN_METADATA_SIZE+count*INDEX_RUN_ENTRY_SIZE) != info.Size() {
+		run.close()
+		return nil, fmt.Errorf("index run %s size not match entry count", path)
+	}
+
+	return run, nil
+}
+
+func (r *indexRun) entry(i int) (pointer uint64, slot uint64) {
+	offset := INDEX_RUN_METADATA_SIZE + i*INDEX_RUN_ENTRY_SIZE
+	pointer = binary.LittleEndian.Uint64(r.data[offset+INDEX_RUN_KEY_POINTER_OFFSET : offset+INDEX_RUN_KEY_POINTER_OFFSET+8])
+	slot = binary.LittleEndian.Uint64(r.data[offset+INDEX_RUN_SLOT_OFFSET : offset+INDEX_RUN_SLOT_OFFSET+8])
+	return pointer, slot
+}
+
+func (r *indexRun) item(i int) KVItem {
+	pointer, slot := r.entry(i)
+	return KVItem{
+		Key: r.dynamic.keyAt(int64(pointer)),
+		Off: slot,
+	}
+}
+
+// search first entry with key >= start
+func (r *indexRun) search(start []byte) int {
+	return sort.Search(r.count, func(i int) bool {
+		return bytes.Compare(r.item(i).Key, start) >= 0
+	})
+}
+
+func (r *indexRun) close() error {
+	err := r.data.Unmap()
+	if err != nil {
+		return err
+	}
+	return r.f.Close()
+}
+
+// writeIndexRun write and sync entry in key order to path
+func writeIndexRun(path string, watermark int64, count int, iterate func(write func(pointer, slot uint64) error) error) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	buf := make([]byte, INDEX_RUN_ENTRY_SIZE)
+
+	binary.LittleEndian.PutUint64(buf[0:8], uint64(count))
+	binary.LittleEndian.PutUint64(buf[8:16], uint64(watermark))
+	if _, err := w.Write(buf); err != nil {
+		f.Close()
+		return err
+	}
+
+	written := 0
+	err = iterate(func(pointer, slot uint64) error {
+		binary.LittleEndian.PutUint64(buf[INDEX_RUN_KEY_POINTER_OFFSET:INDEX_RUN_KEY_POINTER_OFFSET+8], pointer)
+		binary.LittleEndian.PutUint64(buf[INDEX_RUN_SLOT_OFFSET:INDEX_RUN_SLOT_OFFSET+8], slot)
+		written++
+		_, err := w.Write(buf)
+		return err
+	})
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	if written != count {
+		f.Close()
+		return fmt.Errorf("index run written %d entry, expected %d", written, count)
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	// some platform not support sync directory
+	d.Sync()
+	return nil
+}
@@ -0,0 +1,632 @@
+package stream_core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/edsrzf/mmap-go"
+)
+
+// MaxVerifyProblems problem kept in verify report, rest only counted
+const MaxVerifyProblems = 1000
+
+// Inspector read counter and dynamic value file directly, without index, wal and dedupe.
+// file mapped read only, value can still change when counter opened by other process
+type Inspector struct {
+	hash        *hashKey
+	counterFile *os.File
+	counter     mmap.MMap
+	dynamic     *DynamicValue
+	// headerOffset current offset written in dynamic value header, dynamic current offset clamped to file size
+	headerOffset int64
+}
+
+type InspectStat struct {
+	CounterFileSize int64
+	Slots           uint64
+	// KeyCount key count written in counter header, SlotsUsed slot with timestamp set
+	KeyCount   uint64
+	SlotsUsed  uint64
+	LoadFactor float64
+	// DynamicOffset current offset written in dynamic value header
+	DynamicFileSize int64
+	DynamicOffset   int64
+	Entries         uint64
+}
+
+type VerifyReport struct {
+	Entries   uint64
+	SlotsUsed uint64
+	Problems  []string
+	// Dropped problem not kept after MaxVerifyProblems
+	Dropped int
+}
+
+func (r *VerifyReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *VerifyReport) problemf(format string, args ...any) {
+	if len(r.Problems) >= MaxVerifyProblems {
+		r.Dropped++
+		return
+	}
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// RepairReport change made by RepairCounter
+type RepairReport struct {
+	Entries uint64
+	// Rebuilt slot rewritten from dynamic value, Cleared used slot without key pointing to it
+	Rebuilt uint64
+	Cleared uint64
+	// Truncated unreadable byte dropped from dynamic value tail
+	Truncated int64
+	KeyCount  uint64
+}
+
+// dynamicEntry body dynamic read with bound check
+type dynamicEntry struct {
+	offset int64
+	key    string
+	hash   int64
+	data   []byte
+}
+
+// OpenInspector map counter and dynamic value file of cfg read only, slot count taken from counter file size
+func OpenInspector(cfg *CoreConfig) (*Inspector, error) {
+	return openInspector(cfg, false)
+}
+
+// openInspector write true create missing counter file sized by cfg slots
+func openInspector(cfg *CoreConfig, write bool) (*Inspector, error) {
+	flag, prot := os.O_RDONLY, mmap.RDONLY
+	if write {
+		flag, prot = os.O_RDWR, mmap.RDWR
+	}
+
+	in := &Inspector{dynamic: &DynamicValue{}}
+	var err error
+	in.counterFile, err = os.OpenFile(cfg.HashMapCounterPath, flagCreate(flag, write), 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := in.counterFile.Stat()
+	if err != nil {
+		in.Close()
+		return nil, err
+	}
+
+	slots, ok := counterSlots(info.Size())
+	if !ok {
+		if !write {
+			in.Close()
+			return nil, fmt.Errorf("%s size %d is not hashmap counter file", cfg.HashMapCounterPath, info.Size())
+		}
+
+		// counter file lost or cut, rebuilt with configured slot
+		slots = cfg.HashMapCounterSlots
+		if slots == 0 || slots&(slots-1) != 0 {
+			in.Close()
+			return nil, fmt.Errorf("hashmap counter slots %d must power of two", slots)
+		}
+		err = in.counterFile.Truncate(int64(slots*HASHMAP_SLOT_SIZE) + HASHMAP_METADATA_SIZE)
+		if err != nil {
+			in.Close()
+			return nil, err
+		}
+	}
+	in.hash = &hashKey{&CoreConfig{HashMapCounterSlots: slots}}
+
+	in.counter, err = mmap.Map(in.counterFile, prot, 0)
+	if err != nil {
+		in.Close()
+		return nil, err
+	}
+
+	in.dynamic.f, err = os.OpenFile(cfg.DynamicValuePath, flag, 0)
+	if err != nil {
+		in.Close()
+		return nil, err
+	}
+
+	info, err = in.dynamic.f.Stat()
+	if err != nil {
+		in.Close()
+		return nil, err
+	}
+	in.dynamic.filesize = info.Size()
+	if in.dynamic.filesize < DYNAMIC_METADATA_SIZE {
+		in.Close()
+		return nil, fmt.Errorf("%s size %d is not dynamic value file", cfg.DynamicValuePath, in.dynamic.filesize)
+	}
+
+	in.dynamic.data, err = mmap.Map(in.dynamic.f, prot, 0)
+	if err != nil {
+		in.Close()
+		return nil, err
+	}
+
+	in.headerOffset = getCurrentOffset(in.dynamic.data)
+	in.dynamic.currentOffset = min(max(in.headerOffset, DYNAMIC_METADATA_SIZE), in.dynamic.filesize)
+	return in, nil
+}
+
+func flagCreate(flag int, write bool) int {
+	if write {
+		return flag | os.O_CREATE
+	}
+	return flag
+}
+
+// counterSlots slot count of counter file size, false when size not metadata and power of two slot
+func counterSlots(size int64) (uint64, bool) {
+	if size <= HASHMAP_METADATA_SIZE || (size-HASHMAP_METADATA_SIZE)%HASHMAP_SLOT_SIZE != 0 {
+		return 0, false
+	}
+	slots := uint64(size-HASHMAP_METADATA_SIZE) / HASHMAP_SLOT_SIZE
+	return slots, slots&(slots-1) == 0
+}
+
+// Close unmap and close file, safe to call more than once
+func (in *Inspector) Close() error {
+	var errs []error
+	if in.dynamic.data != nil {
+		errs = append(errs, in.dynamic.data.Unmap())
+		in.dynamic.data = nil
+	}
+	if in.dynamic.f != nil {
+		errs = append(errs, in.dynamic.f.Close())
+		in.dynamic.f = nil
+	}
+	if in.counter != nil {
+		errs = append(errs, in.counter.Unmap())
+		in.counter = nil
+	}
+	if in.counterFile != nil {
+		errs = append(errs, in.counterFile.Close())
+		in.counterFile = nil
+	}
+	return errors.Join(errs...)
+}
+
+func (in *Inspector) Slots() uint64 {
+	return in.hash.cfg.HashMapCounterSlots
+}
+
+func (in *Inspector) slot(slot int64) slotImage {
+	offset := slot + HASHMAP_METADATA_SIZE
+	return slotImage(in.counter[offset : offset+HASHMAP_SLOT_SIZE])
+}
+
+// validSlot slot offset written in merge data is start of slot inside table
+func (in *Inspector) validSlot(slot uint64) bool {
+	return slot%HASHMAP_SLOT_SIZE == 0 && slot/HASHMAP_SLOT_SIZE < in.Slots()
+}
+
+// entryAt read body dynamic at offset, false when offset or length outside written dynamic value
+func (in *Inspector) entryAt(offset int64) (*dynamicEntry, int64, bool) {
+	end := in.dynamic.currentOffset
+	d := in.dynamic.data
+	if offset < DYNAMIC_METADATA_SIZE || offset+KEY_METADATA_SIZE > end {
+		return nil, 0, false
+	}
+
+	keylen := binary.LittleEndian.Uint64(d[offset+KEY_LEN_OFFSET : offset+KEY_LEN_OFFSET+8])
+	datalen := binary.LittleEndian.Uint64(d[offset+DATA_LEN_OFFSET : offset+DATA_LEN_OFFSET+8])
+	if keylen > uint64(end) || datalen > uint64(end) {
+		return nil, 0, false
+	}
+
+	next := offset + KEY_METADATA_SIZE + int64(keylen) + int64(datalen)
+	if next > end {
+		return nil, 0, false
+	}
+
+	keyEnd := offset + DATA_OFFSET + int64(keylen)
+	return &dynamicEntry{
+		offset: offset,
+		key:    string(d[offset+DATA_OFFSET : keyEnd]),
+		hash:   int64(binary.LittleEndian.Uint64(d[offset+KEY_HASH_OFFSET : offset+KEY_HASH_OFFSET+8])),
+		data:   d[keyEnd:next],
+	}, next, true
+}
+
+// entries iterate body dynamic in write order, return offset where readable body dynamic end
+func (in *Inspector) entries(handler func(e *dynamicEntry) error) (int64, error) {
+	offset := int64(DYNAMIC_METADATA_SIZE)
+	for offset < in.dynamic.currentOffset {
+		e, next, ok := in.entryAt(offset)
+		if !ok {
+			return offset, nil
+		}
+
+		err := handler(e)
+		if err != nil {
+			return offset, err
+		}
+		offset = next
+	}
+	return offset, nil
+}
+
+func (in *Inspector) Stat() (*InspectStat, error) {
+	stat := &InspectStat{
+		CounterFileSize: int64(len(in.counter)),
+		Slots:           in.Slots(),
+		KeyCount:        getCurrentCount(in.counter),
+		DynamicFileSize: in.dynamic.filesize,
+		DynamicOffset:   in.headerOffset,
+	}
+
+	for slot := uint64(0); slot < stat.Slots; slot++ {
+		if in.slot(int64(slot*HASHMAP_SLOT_SIZE)).timestamp() != 0 {
+			stat.SlotsUsed++
+		}
+	}
+	stat.LoadFactor = float64(stat.SlotsUsed) / float64(stat.Slots)
+
+	_, err := in.entries(func(e *dynamicEntry) error {
+		stat.Entries++
+		return nil
+	})
+	return stat, err
+}
+
+// Get get current record of key, false when key never written
+func (in *Inspector) Get(key string) (*KeyRecord, bool, error) {
+	slot := in.hash.hash(key)
+	image := in.slot(slot)
+	if image.timestamp() == 0 {
+		return nil, false, nil
+	}
+
+	e, _, ok := in.entryAt(image.keyPointer())
+	if !ok {
+		return nil, false, fmt.Errorf("%s slot %d key pointer %d outside dynamic value", key, slot, image.keyPointer())
+	}
+
+	// slot shared by other key with same hash
+	if e.key != key {
+		return nil, false, nil
+	}
+
+	rec, err := in.record(key, image)
+	if err != nil {
+		return nil, false, err
+	}
+	return rec, true, nil
+}
+
+// Scan visit record of key with prefix in key order
+func (in *Inspector) Scan(prefix string, handler func(rec *KeyRecord) error) error {
+	live := map[string]int64{}
+	_, err := in.entries(func(e *dynamicEntry) error {
+		if !strings.HasPrefix(e.key, prefix) {
+			return nil
+		}
+
+		slot := in.hash.hash(e.key)
+		image := in.slot(slot)
+		if image.timestamp() != 0 && image.keyPointer() == e.offset {
+			live[e.key] = slot
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(live))
+	for key := range live {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		rec, err := in.record(key, in.slot(live[key]))
+		if err != nil {
+			return err
+		}
+
+		err = handler(rec)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (in *Inspector) record(key string, image slotImage) (*KeyRecord, error) {
+	merge, err := in.mergeDefinition(key, image)
+	if err != nil {
+		return nil, err
+	}
+
+	kind, value := image.value()
+	return &KeyRecord{
+		Key:       key,
+		Kind:      kind,
+		Value:     value,
+		UpdatedAt: time.UnixMilli(int64(image.timestamp())),
+		Replace:   image.typeKey() == ReplaceKeyType,
+		Merge:     merge,
+	}, nil
+}
+
+func (in *Inspector) mergeDefinition(key string, image slotImage) (*MergeDefinition, error) {
+	if image.typeKey() != MergeKeyType {
+		return nil, nil
+	}
+
+	e, _, ok := in.entryAt(image.keyPointer())
+	if !ok || !validMergeData(e.data) {
+		return nil, fmt.Errorf("%s merge data unreadable", key)
+	}
+
+	mdata := MergeData(e.data)
+	def := &MergeDefinition{
+		Op: mdata.getOp(),
+	}
+	for _, sourceSlot := range mdata.keys() {
+		if !in.validSlot(sourceSlot) {
+			return nil, fmt.Errorf("%s source slot %d outside hashmap", key, sourceSlot)
+		}
+
+		source := in.slot(int64(sourceSlot))
+		if source.timestamp() == 0 {
+			return nil, fmt.Errorf("%s derrived key never written", key)
+		}
+
+		se, _, ok := in.entryAt(source.keyPointer())
+		if !ok {
+			return nil, fmt.Errorf("%s source slot %d key pointer outside dynamic value", key, sourceSlot)
+		}
+		def.Sources = append(def.Sources, se.key)
+	}
+	sort.Strings(def.Sources)
+
+	return def, nil
+}
+
+// validMergeData merge data length match its source count
+func validMergeData(data []byte) bool {
+	if len(data) < MERGE_OPS_METADATA_SIZE {
+		return false
+	}
+	keylen := binary.LittleEndian.Uint64(data[MERGE_DERRIVED_KEY_LEN_OFFSET : MERGE_DERRIVED_KEY_LEN_OFFSET+8])
+	return keylen <= uint64(len(data)) && uint64(len(data)) == MERGE_OPS_METADATA_SIZE+keylen*8
+}
+
+func validKind(kind byte) bool {
+	switch reflect.Kind(kind) {
+	case reflect.Int64, reflect.Uint64, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// entryTypeKey type key of counter slot owning body dynamic data, false when data unreadable
+func entryTypeKey(data []byte) (byte, bool) {
+	switch {
+	case len(data) == 1 && (data[0] == CounterKeyType || data[0] == ReplaceKeyType):
+		return data[0], true
+	case validMergeData(data):
+		return MergeKeyType, true
+	default:
+		return 0, false
+	}
+}
+
+// Verify check key pointer, key hash, merge source and dynamic value offset of whole file
+func (in *Inspector) Verify() (*VerifyReport, error) {
+	report := &VerifyReport{}
+	if in.headerOffset < DYNAMIC_METADATA_SIZE || in.headerOffset > in.dynamic.filesize {
+		report.problemf("dynamic value current offset %d outside file size %d", in.headerOffset, in.dynamic.filesize)
+	}
+
+	keys := map[int64]string{}
+	end, err := in.entries(func(e *dynamicEntry) error {
+		report.Entries++
+		keys[e.offset] = e.key
+
+		slot := in.hash.hash(e.key)
+		if e.hash != slot {
+			report.problemf("key %q at offset %d hash %d, expected %d", e.key, e.offset, e.hash, slot)
+		}
+
+		image := in.slot(slot)
+		switch {
+		case image.timestamp() == 0:
+			report.problemf("key %q slot %d never written", e.key, slot)
+			return nil
+		case image.keyPointer() != e.offset:
+			report.problemf("key %q at offset %d, slot %d point to offset %d", e.key, e.offset, slot, image.keyPointer())
+			return nil
+		}
+
+		typeKey, ok := entryTypeKey(e.data)
+		switch {
+		case !ok:
+			report.problemf("key %q at offset %d data length %d unreadable", e.key, e.offset, len(e.data))
+		case typeKey != image.typeKey():
+			report.problemf("key %q slot type %d, dynamic value type %d", e.key, image.typeKey(), typeKey)
+		case typeKey == MergeKeyType:
+			in.verifyMerge(report, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if end != in.dynamic.currentOffset {
+		report.problemf("dynamic value unreadable from offset %d, %d byte after it", end, in.dynamic.currentOffset-end)
+	}
+
+	for i := uint64(0); i < in.Slots(); i++ {
+		slot := int64(i * HASHMAP_SLOT_SIZE)
+		image := in.slot(slot)
+		if image.timestamp() == 0 {
+			continue
+		}
+		report.SlotsUsed++
+
+		if !validKind(image[HASHMAP_TYPE_COUNTER_OFFSET]) {
+			report.problemf("slot %d invalid kind %d", slot, image[HASHMAP_TYPE_COUNTER_OFFSET])
+		}
+
+		key, ok := keys[image.keyPointer()]
+		switch {
+		case !ok:
+			report.problemf("slot %d key pointer %d not start of dynamic value", slot, image.keyPointer())
+		case in.hash.hash(key) != slot:
+			report.problemf("slot %d point to key %q of slot %d", slot, key, in.hash.hash(key))
+		}
+	}
+
+	keyCount := getCurrentCount(in.counter)
+	if keyCount != report.SlotsUsed {
+		report.problemf("header key count %d, slot used %d", keyCount, report.SlotsUsed)
+	}
+
+	return report, nil
+}
+
+func (in *Inspector) verifyMerge(report *VerifyReport, e *dynamicEntry) {
+	mdata := MergeData(e.data)
+	sources := mdata.keys()
+	if mdata.getSourceHash() != in.hash.hashByte(e.data[MERGE_DATA_OFFSET:]) {
+		report.problemf("merge key %q source hash mismatch", e.key)
+	}
+
+	for _, source := range sources {
+		switch {
+		case !in.validSlot(source):
+			report.problemf("merge key %q source slot %d outside hashmap", e.key, source)
+		case in.slot(int64(source)).timestamp() == 0:
+			report.problemf("merge key %q source slot %d never written", e.key, source)
+		}
+	}
+}
+
+// RepairCounter rebuild counter slot from dynamic value log, fail with LockedError when counter opened by writer.
+// key value kept when slot kind valid, ordered key index removed so next open rebuild it.
+// fail with ErrBatchPending when batch wal hold undo not applied yet
+func RepairCounter(cfg *CoreConfig) (*RepairReport, error) {
+	lock, err := acquireFileLock(lockPath(cfg))
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	if cfg.WalDir != "" {
+		pending, _, err := replayBatchWal(cfg.WalDir)
+		if err != nil {
+			return nil, err
+		}
+		if pending != nil {
+			return nil, ErrBatchPending
+		}
+	}
+
+	in, err := openInspector(cfg, true)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	report := &RepairReport{}
+	now := uint64(time.Now().UnixMilli())
+	claimed := map[int64]bool{}
+
+	end, err := in.entries(func(e *dynamicEntry) error {
+		report.Entries++
+
+		slot := in.hash.hash(e.key)
+		if e.hash != slot {
+			binary.LittleEndian.PutUint64(in.dynamic.data[e.offset+KEY_HASH_OFFSET:e.offset+KEY_HASH_OFFSET+8], uint64(slot))
+		}
+
+		// first key written own the slot, same as apply never writing key of used slot
+		if claimed[slot] {
+			return nil
+		}
+		claimed[slot] = true
+
+		typeKey, ok := entryTypeKey(e.data)
+		if !ok {
+			typeKey = CounterKeyType
+		}
+
+		image := in.slot(slot)
+		var changed bool
+		if image.keyPointer() != e.offset {
+			binary.LittleEndian.PutUint64(image[KEY_POINTER_OFFSET:KEY_POINTER_OFFSET+8], uint64(e.offset))
+			changed = true
+		}
+		if image.typeKey() != typeKey {
+			image[TYPE_KEY_OFFSET] = typeKey
+			changed = true
+		}
+		if !validKind(image[HASHMAP_TYPE_COUNTER_OFFSET]) {
+			image[HASHMAP_TYPE_COUNTER_OFFSET] = byte(reflect.Int64)
+			binary.LittleEndian.PutUint64(image[COUNTER_OFFSET:COUNTER_OFFSET+8], 0)
+			changed = true
+		}
+		if image.timestamp() == 0 {
+			binary.LittleEndian.PutUint64(image[TIMESTAMP_OFFSET:TIMESTAMP_OFFSET+8], now)
+			changed = true
+		}
+		if changed {
+			report.Rebuilt++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if end != in.headerOffset {
+		report.Truncated = max(in.headerOffset-end, 0)
+		setCurrentOffset(in.dynamic.data, end)
+	}
+
+	for i := uint64(0); i < in.Slots(); i++ {
+		slot := int64(i * HASHMAP_SLOT_SIZE)
+		image := in.slot(slot)
+		if image.timestamp() == 0 || claimed[slot] {
+			continue
+		}
+		clear(image)
+		report.Cleared++
+	}
+
+	report.KeyCount = uint64(len(claimed))
+	setCurrentCount(in.counter, report.KeyCount)
+
+	err = in.dynamic.data.Flush()
+	if err != nil {
+		return nil, err
+	}
+	err = in.counter.Flush()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.IndexPath != "" {
+		err = os.Remove(cfg.IndexPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	return report, in.Close()
+}
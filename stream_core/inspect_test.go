@@ -0,0 +1,176 @@
+package stream_core_test
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func TestInspector(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/inspect_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/inspect_value_unittest",
+		IndexPath:           "/tmp/stream_engine/inspect_index_unittest",
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+	os.Remove(cfg.IndexPath)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	kv.IncInt64("teams/12/debit", 10)
+	kv.IncInt64("teams/12/credit", 4)
+	kv.PutFloat64("teams/13/rate", 1.5)
+	_, err = kv.Merge(stream_core.MergeOpAdd, reflect.Int64, "teams/12/total", "teams/12/debit", "teams/12/credit")
+	assert.Nil(t, err)
+	assert.Nil(t, kv.Close())
+
+	t.Run("testing read clean file", func(t *testing.T) {
+		in, err := stream_core.OpenInspector(&cfg)
+		assert.Nil(t, err)
+		defer in.Close()
+
+		stat, err := in.Stat()
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1024), stat.Slots)
+		assert.Equal(t, uint64(4), stat.KeyCount)
+		assert.Equal(t, uint64(4), stat.SlotsUsed)
+		assert.Equal(t, uint64(4), stat.Entries)
+
+		rec, ok, err := in.Get("teams/12/total")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, int64(14), rec.Value)
+		assert.Equal(t, []string{"teams/12/credit", "teams/12/debit"}, rec.Merge.Sources)
+
+		_, ok, err = in.Get("teams/99/debit")
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		keys := []string{}
+		err = in.Scan("teams/12/", func(rec *stream_core.KeyRecord) error {
+			keys = append(keys, rec.Key)
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"teams/12/credit", "teams/12/debit", "teams/12/total"}, keys)
+
+		report, err := in.Verify()
+		assert.Nil(t, err)
+		assert.True(t, report.OK(), report.Problems)
+	})
+
+	t.Run("testing verify and repair corrupt file", func(t *testing.T) {
+		counter, err := os.ReadFile(cfg.HashMapCounterPath)
+		assert.Nil(t, err)
+
+		// first used slot lose its timestamp and header count is wrong
+		for offset := stream_core.HASHMAP_METADATA_SIZE; offset < len(counter); offset += stream_core.HASHMAP_SLOT_SIZE {
+			ts := counter[offset+stream_core.TIMESTAMP_OFFSET : offset+stream_core.TIMESTAMP_OFFSET+8]
+			if binary.LittleEndian.Uint64(ts) != 0 {
+				clear(ts)
+				break
+			}
+		}
+		binary.LittleEndian.PutUint64(counter[0:8], 9)
+		assert.Nil(t, os.WriteFile(cfg.HashMapCounterPath, counter, 0644))
+
+		// half written body dynamic after last key
+		dynamic, err := os.ReadFile(cfg.DynamicValuePath)
+		assert.Nil(t, err)
+		offset := binary.LittleEndian.Uint64(dynamic[0:8])
+		binary.LittleEndian.PutUint64(dynamic[offset:offset+8], 1<<40)
+		binary.LittleEndian.PutUint64(dynamic[0:8], offset+30)
+		assert.Nil(t, os.WriteFile(cfg.DynamicValuePath, dynamic, 0644))
+
+		in, err := stream_core.OpenInspector(&cfg)
+		assert.Nil(t, err)
+		report, err := in.Verify()
+		assert.Nil(t, err)
+		assert.Nil(t, in.Close())
+		assert.False(t, report.OK())
+		assert.Len(t, report.Problems, 4, report.Problems)
+
+		repaired, err := stream_core.RepairCounter(&cfg)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(4), repaired.Entries)
+		assert.Equal(t, uint64(1), repaired.Rebuilt)
+		assert.Equal(t, int64(30), repaired.Truncated)
+		assert.Equal(t, uint64(4), repaired.KeyCount)
+
+		in, err = stream_core.OpenInspector(&cfg)
+		assert.Nil(t, err)
+		report, err = in.Verify()
+		assert.Nil(t, err)
+		assert.Nil(t, in.Close())
+		assert.True(t, report.OK(), report.Problems)
+
+		kv, err := stream_core.NewHashMapCounter(&cfg)
+		assert.Nil(t, err)
+		defer kv.Close()
+
+		assert.Equal(t, int64(10), kv.GetInt64("teams/12/debit"))
+		assert.Equal(t, int64(14), kv.GetInt64("teams/12/total"))
+	})
+}
+
+func TestRepairCounterPendingBatch(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		WalDir:              "/tmp/stream_engine/repair_batch_wal_unittest",
+		HashMapCounterPath:  "/tmp/stream_engine/repair_batch_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/repair_batch_value_unittest",
+	}
+	os.RemoveAll(cfg.WalDir)
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer kv.Close()
+
+	kv.IncInt64("teams/12/debit", 10)
+	batch := stream_core.NewBatch()
+	batch.IncInt64("teams/12/debit", 5)
+	batch.IncInt64("teams/13/debit", 3)
+	assert.Nil(t, kv.ApplyBatch(batch))
+
+	// files left on disk when process killed before commit record written
+	crashCfg := cfg
+	crashCfg.WalDir = cfg.WalDir + "_crash"
+	crashCfg.HashMapCounterPath = cfg.HashMapCounterPath + "_crash"
+	crashCfg.DynamicValuePath = cfg.DynamicValuePath + "_crash"
+	os.RemoveAll(crashCfg.WalDir)
+	os.MkdirAll(crashCfg.WalDir, 0755)
+	copyFile(t, cfg.HashMapCounterPath, crashCfg.HashMapCounterPath)
+	copyFile(t, cfg.DynamicValuePath, crashCfg.DynamicValuePath)
+	segments, _ := os.ReadDir(cfg.WalDir)
+	for _, segment := range segments {
+		copyFile(t, filepath.Join(cfg.WalDir, segment.Name()), filepath.Join(crashCfg.WalDir, segment.Name()))
+	}
+	dropLastWalRecord(t, filepath.Join(crashCfg.WalDir, segments[len(segments)-1].Name()))
+
+	_, err = stream_core.RepairCounter(&crashCfg)
+	assert.ErrorIs(t, err, stream_core.ErrBatchPending)
+
+	// opening counter apply undo, repair allowed after
+	recovered, err := stream_core.NewHashMapCounter(&crashCfg)
+	assert.Nil(t, err)
+	assert.Nil(t, recovered.Close())
+
+	repaired, err := stream_core.RepairCounter(&crashCfg)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), repaired.KeyCount)
+
+	recovered, err = stream_core.NewHashMapCounter(&crashCfg)
+	assert.Nil(t, err)
+	defer recovered.Close()
+	assert.Equal(t, int64(10), recovered.GetInt64("teams/12/debit"))
+	assert.Equal(t, int64(0), recovered.GetInt64("teams/13/debit"))
+}
@@ -0,0 +1,144 @@
+package stream_core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/edsrzf/mmap-go"
+)
+
+var ErrReadOnly = errors.New("counter opened read only")
+
+// openReadOnly map counter file of other process read only, wal and dedupe not opened
+func openReadOnly(cfg *CoreConfig) (*HashMapCounter, error) {
+	size := int64(cfg.HashMapCounterSlots*HASHMAP_SLOT_SIZE) + HASHMAP_METADATA_SIZE
+
+	f, err := os.Open(cfg.HashMapCounterPath)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	// file never truncated, slot count must match writer
+	if info.Size() != size {
+		f.Close()
+		return nil, fmt.Errorf("%s size %d not match %d slots", cfg.HashMapCounterPath, info.Size(), cfg.HashMapCounterSlots)
+	}
+
+	m, err := mmap.Map(f, mmap.RDONLY, 0)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	dynamic, err := openDynamicValueReadOnly(cfg)
+	if err != nil {
+		m.Unmap()
+		f.Close()
+		return nil, err
+	}
+
+	index, err := openKeyIndex(cfg.IndexPath, dynamic)
+	if err != nil {
+		dynamic.Close()
+		m.Unmap()
+		f.Close()
+		return nil, err
+	}
+
+	return &HashMapCounter{
+		hash:         &hashKey{cfg},
+		dynamicValue: dynamic,
+		f:            f,
+		data:         m,
+		keyCount:     getCurrentCount(m),
+		index:        index,
+		snapshots:    map[*SnapshotView]struct{}{},
+		feed:         newChangeFeed(),
+		offsets:      map[string]int64{},
+		readOnly:     true,
+	}, nil
+}
+
+// ReadOnly true when counter opened with CoreConfig.ReadOnly
+func (hm *HashMapCounter) ReadOnly() bool {
+	return hm.readOnly
+}
+
+// writable must called before any write to counter or dynamic value
+func (hm *HashMapCounter) writable() error {
+	if hm.readOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// readable true when entry at pointer written before last refresh, slot of read only counter can point to key
+// written by writer after refresh. must called with lock held
+func (hm *HashMapCounter) readable(pointer int64) bool {
+	if !hm.readOnly {
+		return true
+	}
+	return pointer >= DYNAMIC_METADATA_SIZE && pointer+KEY_METADATA_SIZE <= hm.dynamicValue.currentOffset
+}
+
+// Refresh reload key count and key written by writer process, read only counter also refreshed on every read
+func (hm *HashMapCounter) Refresh() error {
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	return hm.refreshLocked()
+}
+
+// refreshLocked follow writer of read only counter, no op for writer. must called with lock held
+func (hm *HashMapCounter) refreshLocked() error {
+	if !hm.readOnly {
+		return nil
+	}
+
+	hm.keyCount = getCurrentCount(hm.data)
+
+	d := hm.dynamicValue
+	offset := getCurrentOffset(d.data)
+	if offset == d.currentOffset {
+		return nil
+	}
+
+	if offset > d.filesize {
+		err := d.remap()
+		if err != nil {
+			return err
+		}
+		offset = min(offset, d.filesize)
+	}
+
+	// writer rolled back batch, key after new offset may not exist anymore
+	if offset < d.currentOffset {
+		d.currentOffset = offset
+		index, err := openKeyIndex(hm.index.path, d)
+		if err != nil {
+			return err
+		}
+
+		err = hm.index.close()
+		if err != nil {
+			index.close()
+			return err
+		}
+		hm.index = index
+		return nil
+	}
+
+	from := d.currentOffset
+	d.currentOffset = offset
+	return d.iterateFrom(from, func(offset int64, key string, hash int64, data []byte) error {
+		hm.index.insert(key, hash)
+		return nil
+	})
+}
@@ -0,0 +1,114 @@
+package stream_core_test
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func TestReadOnly(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/read_only_unittest",
+		HashMapCounterSlots: 1 << 16,
+		DynamicValuePath:    "/tmp/stream_engine/read_only_value_unittest",
+		IndexPath:           "/tmp/stream_engine/read_only_index_unittest",
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+	os.Remove(cfg.IndexPath)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer kv.Close()
+	kv.IncInt64("teams/12/debit", 10)
+	assert.Nil(t, kv.CheckpointIndex())
+
+	readCfg := cfg
+	readCfg.ReadOnly = true
+	reader, err := stream_core.NewHashMapCounter(&readCfg)
+	assert.Nil(t, err)
+	defer reader.Close()
+	assert.True(t, reader.ReadOnly())
+
+	scanKeys := func(hm *stream_core.HashMapCounter, prefix string) []string {
+		keys := []string{}
+		_, err := hm.ScanPrefix(prefix, nil, func(key string, kind reflect.Kind, value any) error {
+			keys = append(keys, key)
+			return nil
+		})
+		assert.Nil(t, err)
+		return keys
+	}
+
+	t.Run("testing write rejected", func(t *testing.T) {
+		_, err := reader.Inc("teams/12/debit", int64(1))
+		assert.ErrorIs(t, err, stream_core.ErrReadOnly)
+		_, err = reader.Put("teams/12/credit", int64(1))
+		assert.ErrorIs(t, err, stream_core.ErrReadOnly)
+		_, err = reader.Merge(stream_core.MergeOpAdd, reflect.Int64, "teams/12/total", "teams/12/debit")
+		assert.ErrorIs(t, err, stream_core.ErrReadOnly)
+		assert.ErrorIs(t, reader.ApplyBatch(stream_core.NewBatch()), stream_core.ErrReadOnly)
+		assert.ErrorIs(t, reader.ResetCounter(), stream_core.ErrReadOnly)
+		assert.ErrorIs(t, reader.CheckpointIndex(), stream_core.ErrReadOnly)
+		assert.PanicsWithError(t, stream_core.ErrReadOnly.Error(), func() { reader.IncInt64("teams/12/debit", 1) })
+
+		assert.Equal(t, int64(10), reader.GetInt64("teams/12/debit"))
+	})
+
+	t.Run("testing follow writer", func(t *testing.T) {
+		kv.IncInt64("teams/12/debit", 5)
+		kv.PutFloat64("teams/12/rate", 1.5)
+
+		rec, ok, err := reader.GetRecord("teams/12/rate")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 1.5, rec.Value)
+		assert.Equal(t, int64(15), reader.GetInt64("teams/12/debit"))
+		assert.Equal(t, []string{"teams/12/debit", "teams/12/rate"}, scanKeys(reader, "teams/12/"))
+
+		stats, err := reader.Stats()
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(2), stats.SlotsUsed)
+	})
+
+	t.Run("testing follow writer growing dynamic value", func(t *testing.T) {
+		suffix := strings.Repeat("x", 1000)
+		for i := 0; i < 6000; i++ {
+			kv.IncInt64(fmt.Sprintf("bulk/%04d/%s", i, suffix), 1)
+		}
+
+		writerStats, err := kv.Stats()
+		assert.Nil(t, err)
+		assert.Greater(t, writerStats.DynamicFileSize, int64(stream_core.FILE_SIZE_INCREASE))
+
+		stats, err := reader.Stats()
+		assert.Nil(t, err)
+		assert.Equal(t, writerStats.SlotsUsed, stats.SlotsUsed)
+		assert.Equal(t, writerStats.DynamicUsed, stats.DynamicUsed)
+		assert.Equal(t, scanKeys(kv, "bulk/"), scanKeys(reader, "bulk/"))
+	})
+
+	t.Run("testing file not truncated", func(t *testing.T) {
+		wrongCfg := readCfg
+		wrongCfg.HashMapCounterSlots = 1 << 10
+		_, err := stream_core.NewHashMapCounter(&wrongCfg)
+		assert.NotNil(t, err)
+
+		info, err := os.Stat(cfg.HashMapCounterPath)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1<<16*stream_core.HASHMAP_SLOT_SIZE+stream_core.HASHMAP_METADATA_SIZE), info.Size())
+
+		missingCfg := readCfg
+		missingCfg.HashMapCounterPath = "/tmp/stream_engine/read_only_missing_unittest"
+		os.Remove(missingCfg.HashMapCounterPath)
+		_, err = stream_core.NewHashMapCounter(&missingCfg)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		_, err = os.Stat(missingCfg.HashMapCounterPath)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
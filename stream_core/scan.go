@@ -0,0 +1,183 @@
+package stream_core
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+type ScanOption struct {
+	// Cursor is next cursor returned by previous scan, empty start from beginning
+	Cursor string
+	// Limit max key per page, 0 is unlimited
+	Limit int
+}
+
+type ScanHandler func(key string, kind reflect.Kind, value any) error
+
+type KeyRecord struct {
+	Key       string
+	Kind      reflect.Kind
+	Value     any
+	UpdatedAt time.Time
+	// Replace is true for key created by Put, its value replaced instead of incremented
+	Replace bool
+	// Merge is definition of computed key, nil for counter key
+	Merge *MergeDefinition
+}
+
+// ScanPrefix iterate key with prefix in key order, return next cursor or empty string when no more key
+func (hm *HashMapCounter) ScanPrefix(prefix string, opt *ScanOption, handler ScanHandler) (string, error) {
+	return hm.scan(prefix, "", prefix, opt, func(key string, slot int64) (bool, error) {
+		kind, value := hm.slotValue(slot)
+		return true, handler(key, kind, value)
+	})
+}
+
+// ScanRange iterate key in [start, end) in key order, empty end is unbounded
+func (hm *HashMapCounter) ScanRange(start, end string, opt *ScanOption, handler ScanHandler) (string, error) {
+	return hm.scan(start, end, "", opt, func(key string, slot int64) (bool, error) {
+		kind, value := hm.slotValue(slot)
+		return true, handler(key, kind, value)
+	})
+}
+
+// ScanRecord iterate key with prefix updated at or after since in key order, zero since not filtering
+func (hm *HashMapCounter) ScanRecord(prefix string, since time.Time, opt *ScanOption, handler func(rec *KeyRecord) error) (string, error) {
+	var tsFilter uint64
+	if !since.IsZero() {
+		tsFilter = uint64(since.UnixMilli())
+	}
+
+	return hm.scan(prefix, "", prefix, opt, func(key string, slot int64) (bool, error) {
+		ts := hm.slotTimestamp(slot)
+		if ts < tsFilter {
+			return false, nil
+		}
+
+		rec, err := hm.record(key, slot)
+		if err != nil {
+			return false, err
+		}
+		return true, handler(rec)
+	})
+}
+
+// GetRecord get current record of key, false when key never written
+func (hm *HashMapCounter) GetRecord(key string) (*KeyRecord, bool, error) {
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	err := hm.refreshLocked()
+	if err != nil {
+		return nil, false, err
+	}
+
+	slot := hm.hash.hash(key)
+	image := hm.slot(slot)
+	if image.timestamp() == 0 {
+		return nil, false, nil
+	}
+
+	// key written after refresh, refreshed again before reported not found
+	if !hm.readable(image.keyPointer()) {
+		err = hm.refreshLocked()
+		if err != nil {
+			return nil, false, err
+		}
+		if !hm.readable(image.keyPointer()) {
+			return nil, false, nil
+		}
+	}
+
+	// slot shared by other key with same hash
+	if string(hm.dynamicValue.keyAt(image.keyPointer())) != key {
+		return nil, false, nil
+	}
+
+	rec, err := hm.record(key, slot)
+	if err != nil {
+		return nil, false, err
+	}
+	return rec, true, nil
+}
+
+// record read key record from slot, must called with lock held
+func (hm *HashMapCounter) record(key string, slot int64) (*KeyRecord, error) {
+	image := hm.slot(slot)
+	merge, err := hm.mergeDefinition(key, image, hm.slot)
+	if err != nil {
+		return nil, err
+	}
+
+	kind, value := image.value()
+	return &KeyRecord{
+		Key:       key,
+		Kind:      kind,
+		Value:     value,
+		UpdatedAt: time.UnixMilli(int64(image.timestamp())),
+		Replace:   image.typeKey() == ReplaceKeyType,
+		Merge:     merge,
+	}, nil
+}
+
+// scan visit key in index order, visit return false when key filtered out and not counted for limit
+func (hm *HashMapCounter) scan(start, end, prefix string, opt *ScanOption, visit func(key string, slot int64) (bool, error)) (string, error) {
+	if opt == nil {
+		opt = &ScanOption{}
+	}
+
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	err := hm.refreshLocked()
+	if err != nil {
+		return "", err
+	}
+
+	if opt.Cursor != "" && opt.Cursor >= start {
+		start = opt.Cursor
+	}
+
+	var count int
+	var cursor string
+	var more bool
+
+	hm.index.ascend(start, end, func(item KVItem) bool {
+		key := string(item.Key)
+		if key == opt.Cursor {
+			return true
+		}
+		if !strings.HasPrefix(key, prefix) {
+			return false
+		}
+
+		if opt.Limit > 0 && count == opt.Limit {
+			more = true
+			return false
+		}
+
+		var visited bool
+		visited, err = visit(key, int64(item.Off))
+		if err != nil {
+			return false
+		}
+		if !visited {
+			return true
+		}
+
+		count++
+		cursor = key
+		return true
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	if !more {
+		return "", nil
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,222 @@
+package stream_core_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func TestScan(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/scan_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/scan_value_unittest",
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+
+	kv.IncInt64("teams/12/daily/2025-01-02/debit", 10)
+	kv.IncInt64("teams/12/daily/2025-01-01/debit", 20)
+	kv.IncFloat64("teams/12/daily/2025-01-03/credit", 1.5)
+	kv.IncInt64("teams/12/daily/2025-02-01/debit", 30)
+	kv.IncInt64("teams/13/daily/2025-01-01/debit", 40)
+
+	scanAll := func(t *testing.T, kv *stream_core.HashMapCounter, prefix string) []string {
+		keys := []string{}
+		_, err := kv.ScanPrefix(prefix, nil, func(key string, kind reflect.Kind, value any) error {
+			keys = append(keys, key)
+			return nil
+		})
+		assert.Nil(t, err)
+		return keys
+	}
+
+	t.Run("testing scan prefix", func(t *testing.T) {
+		values := map[string]any{}
+		cursor, err := kv.ScanPrefix("teams/12/daily/2025-01-", nil, func(key string, kind reflect.Kind, value any) error {
+			values[key] = value
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "", cursor)
+		assert.Equal(t, map[string]any{
+			"teams/12/daily/2025-01-01/debit":  int64(20),
+			"teams/12/daily/2025-01-02/debit":  int64(10),
+			"teams/12/daily/2025-01-03/credit": float64(1.5),
+		}, values)
+
+		assert.Equal(t, []string{
+			"teams/12/daily/2025-01-01/debit",
+			"teams/12/daily/2025-01-02/debit",
+			"teams/12/daily/2025-01-03/credit",
+		}, scanAll(t, kv, "teams/12/daily/2025-01-"))
+	})
+
+	t.Run("testing scan range", func(t *testing.T) {
+		keys := []string{}
+		_, err := kv.ScanRange("teams/12/daily/2025-01-02", "teams/13", nil, func(key string, kind reflect.Kind, value any) error {
+			keys = append(keys, key)
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"teams/12/daily/2025-01-02/debit",
+			"teams/12/daily/2025-01-03/credit",
+			"teams/12/daily/2025-02-01/debit",
+		}, keys)
+	})
+
+	t.Run("testing scan pagination", func(t *testing.T) {
+		pages := [][]string{}
+		opt := &stream_core.ScanOption{Limit: 2}
+		for {
+			page := []string{}
+			cursor, err := kv.ScanPrefix("teams/", opt, func(key string, kind reflect.Kind, value any) error {
+				page = append(page, key)
+				return nil
+			})
+			assert.Nil(t, err)
+			pages = append(pages, page)
+			if cursor == "" {
+				break
+			}
+			opt.Cursor = cursor
+		}
+
+		assert.Equal(t, [][]string{
+			{"teams/12/daily/2025-01-01/debit", "teams/12/daily/2025-01-02/debit"},
+			{"teams/12/daily/2025-01-03/credit", "teams/12/daily/2025-02-01/debit"},
+			{"teams/13/daily/2025-01-01/debit"},
+		}, pages)
+	})
+
+	t.Run("testing scan record replace key", func(t *testing.T) {
+		kv.PutInt64("balances/12", 100)
+		kv.IncInt64("balances/13", 100)
+
+		replace := map[string]bool{}
+		_, err := kv.ScanRecord("balances/", time.Time{}, nil, func(rec *stream_core.KeyRecord) error {
+			replace[rec.Key] = rec.Replace
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]bool{
+			"balances/12": true,
+			"balances/13": false,
+		}, replace)
+	})
+
+	t.Run("testing get record", func(t *testing.T) {
+		rec, ok, err := kv.GetRecord("balances/12")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, reflect.Int64, rec.Kind)
+		assert.Equal(t, int64(100), rec.Value)
+		assert.True(t, rec.Replace)
+
+		_, ok, err = kv.GetRecord("balances/14")
+		assert.Nil(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("testing index rebuild on open", func(t *testing.T) {
+		err := kv.Close()
+		assert.Nil(t, err)
+
+		kv, err = stream_core.NewHashMapCounter(&cfg)
+		assert.Nil(t, err)
+		defer kv.Close()
+
+		assert.Equal(t, []string{
+			"teams/12/daily/2025-01-01/debit",
+			"teams/12/daily/2025-01-02/debit",
+			"teams/12/daily/2025-01-03/credit",
+			"teams/12/daily/2025-02-01/debit",
+			"teams/13/daily/2025-01-01/debit",
+		}, scanAll(t, kv, "teams/"))
+	})
+}
+
+func TestScanPersistedIndex(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/scan_index_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/scan_index_value_unittest",
+		IndexPath:           "/tmp/stream_engine/scan_index_run_unittest",
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+	os.Remove(cfg.IndexPath)
+
+	scanAll := func(t *testing.T, kv *stream_core.HashMapCounter) []string {
+		keys := []string{}
+		_, err := kv.ScanPrefix("", nil, func(key string, kind reflect.Kind, value any) error {
+			keys = append(keys, key)
+			return nil
+		})
+		assert.Nil(t, err)
+		return keys
+	}
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+
+	kv.IncInt64("b/debit", 1)
+	kv.IncInt64("d/debit", 1)
+	assert.Nil(t, kv.Close())
+
+	_, err = os.Stat(cfg.IndexPath)
+	assert.Nil(t, err, "index run written on close")
+
+	t.Run("testing merge run with new key", func(t *testing.T) {
+		kv, err := stream_core.NewHashMapCounter(&cfg)
+		assert.Nil(t, err)
+
+		kv.IncInt64("a/debit", 1)
+		kv.IncInt64("c/debit", 1)
+		kv.IncInt64("e/debit", 1)
+		kv.IncInt64("b/debit", 1)
+
+		expected := []string{"a/debit", "b/debit", "c/debit", "d/debit", "e/debit"}
+		assert.Equal(t, expected, scanAll(t, kv))
+
+		keys := []string{}
+		_, err = kv.ScanRange("b", "d", nil, func(key string, kind reflect.Kind, value any) error {
+			keys = append(keys, key)
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"b/debit", "c/debit"}, keys)
+
+		assert.Nil(t, kv.CheckpointIndex())
+		assert.Equal(t, expected, scanAll(t, kv))
+		assert.Equal(t, int64(2), kv.GetInt64("b/debit"))
+
+		assert.Nil(t, kv.Close())
+	})
+
+	t.Run("testing key after checkpoint replayed", func(t *testing.T) {
+		kv, err := stream_core.NewHashMapCounter(&cfg)
+		assert.Nil(t, err)
+		kv.IncInt64("f/debit", 1)
+		assert.Nil(t, kv.CheckpointIndex())
+		kv.IncInt64("0/debit", 1)
+
+		// simulate crash, index not checkpoint on close. writer locked, so read only
+		readCfg := cfg
+		readCfg.ReadOnly = true
+		other, err := stream_core.NewHashMapCounter(&readCfg)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"0/debit", "a/debit", "b/debit", "c/debit", "d/debit", "e/debit", "f/debit"}, scanAll(t, other))
+
+		assert.Nil(t, kv.Close())
+		assert.Nil(t, other.Close())
+	})
+}
@@ -0,0 +1,56 @@
+package stream_core
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+)
+
+// slotImage raw bytes of single hashmap slot
+type slotImage []byte
+
+// slot get slot image from hashmap data, slot is hashed key without metadata offset
+func (hm *HashMapCounter) slot(slot int64) slotImage {
+	offset := slot + HASHMAP_METADATA_SIZE
+	return slotImage(hm.data[offset : offset+HASHMAP_SLOT_SIZE])
+}
+
+// slotValue read typed counter value from slot
+func (hm *HashMapCounter) slotValue(slot int64) (reflect.Kind, any) {
+	return hm.slot(slot).value()
+}
+
+func (hm *HashMapCounter) slotTimestamp(slot int64) uint64 {
+	return hm.slot(slot).timestamp()
+}
+
+func (s slotImage) value() (reflect.Kind, any) {
+	// getting value
+	value := binary.LittleEndian.Uint64(s[COUNTER_OFFSET : COUNTER_OFFSET+8])
+
+	// getting type key
+	typeKey := reflect.Kind(s[HASHMAP_TYPE_COUNTER_OFFSET])
+	switch typeKey {
+	case reflect.Uint64:
+		return reflect.Uint64, value
+	case reflect.Int64:
+		return reflect.Int64, int64(value)
+	case reflect.Float64:
+		return reflect.Float64, math.Float64frombits(value)
+	default:
+		return reflect.Uint64, value
+	}
+}
+
+func (s slotImage) timestamp() uint64 {
+	return binary.LittleEndian.Uint64(s[TIMESTAMP_OFFSET : TIMESTAMP_OFFSET+8])
+}
+
+// typeKey counter_key or merge_key, only first byte because type key overlapped by key pointer
+func (s slotImage) typeKey() byte {
+	return s[TYPE_KEY_OFFSET]
+}
+
+func (s slotImage) keyPointer() int64 {
+	return int64(binary.LittleEndian.Uint64(s[KEY_POINTER_OFFSET : KEY_POINTER_OFFSET+8]))
+}
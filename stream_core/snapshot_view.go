@@ -0,0 +1,188 @@
+package stream_core
+
+import (
+	"errors"
+	"reflect"
+	"time"
+)
+
+const SnapshotBatchSize = 1024
+
+var ErrSnapshotClosed = errors.New("snapshot closed")
+
+// SnapshotView frozen view of counter at time view opened. writer keep running,
+// slot image before first write after view opened is copied to view shadow (copy on write)
+type SnapshotView struct {
+	hm            *HashMapCounter
+	createdAt     time.Time
+	dynamicOffset int64
+	keyCount      uint64
+	shadow        map[int64]slotImage
+	closed        bool
+}
+
+// OpenSnapshot open point in time view, view must be closed to stop copying slot on write
+func (hm *HashMapCounter) OpenSnapshot() *SnapshotView {
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	// view of read only counter keep last offset when refresh failed
+	hm.refreshLocked()
+
+	view := &SnapshotView{
+		hm:            hm,
+		createdAt:     time.Now(),
+		dynamicOffset: hm.dynamicValue.currentOffset,
+		keyCount:      hm.keyCount,
+		shadow:        map[int64]slotImage{},
+	}
+	hm.snapshots[view] = struct{}{}
+	return view
+}
+
+// preserveSlot copy slot image to all open view before slot modified, must called with lock held
+func (hm *HashMapCounter) preserveSlot(slot int64) {
+	if len(hm.snapshots) == 0 {
+		return
+	}
+
+	var image slotImage
+	for view := range hm.snapshots {
+		if _, ok := view.shadow[slot]; ok {
+			continue
+		}
+		if image == nil {
+			image = make(slotImage, HASHMAP_SLOT_SIZE)
+			copy(image, hm.slot(slot))
+		}
+		view.shadow[slot] = image
+	}
+}
+
+func (s *SnapshotView) CreatedAt() time.Time {
+	return s.createdAt
+}
+
+func (s *SnapshotView) KeyCount() uint64 {
+	return s.keyCount
+}
+
+// slot read slot image at view time, must called with lock held
+func (s *SnapshotView) slot(slot int64) slotImage {
+	image, ok := s.shadow[slot]
+	if ok {
+		return image
+	}
+	return s.hm.slot(slot)
+}
+
+// Iterate key existed when view opened in write order with value at view time.
+// lock only held while reading each batch, handler called without lock
+func (s *SnapshotView) Iterate(since time.Time, handler func(rec *KeyRecord) error) error {
+	return s.iterate(since, true, handler)
+}
+
+func (s *SnapshotView) iterate(since time.Time, withMerge bool, handler func(rec *KeyRecord) error) error {
+	var tsFilter uint64
+	if !since.IsZero() {
+		tsFilter = uint64(since.UnixMilli())
+	}
+
+	next := int64(DYNAMIC_METADATA_SIZE)
+	for next < s.dynamicOffset {
+		var batch []*KeyRecord
+		var err error
+
+		batch, next, err = s.readBatch(next, tsFilter, withMerge)
+		if err != nil {
+			return err
+		}
+
+		for _, rec := range batch {
+			err = handler(rec)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *SnapshotView) readBatch(from int64, tsFilter uint64, withMerge bool) ([]*KeyRecord, int64, error) {
+	hm := s.hm
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	if s.closed {
+		return nil, from, ErrSnapshotClosed
+	}
+
+	batch := []*KeyRecord{}
+	next := from
+	visited := 0
+
+	err := hm.dynamicValue.iterateFrom(from, func(offset int64, key string, khash int64, data []byte) error {
+		if offset >= s.dynamicOffset || visited == SnapshotBatchSize {
+			return ErrBreakDynamicRead
+		}
+		visited++
+		next = offset + KEY_METADATA_SIZE + int64(len(key)+len(data))
+
+		image := s.slot(khash)
+		ts := image.timestamp()
+		if ts < tsFilter {
+			return nil
+		}
+
+		kind, value := image.value()
+		rec := &KeyRecord{
+			Key:       key,
+			Kind:      kind,
+			Value:     value,
+			UpdatedAt: time.UnixMilli(int64(ts)),
+			Replace:   image.typeKey() == ReplaceKeyType,
+		}
+
+		if withMerge {
+			var err error
+			rec.Merge, err = hm.mergeDefinition(key, image, s.slot)
+			if err != nil {
+				return err
+			}
+		}
+
+		batch = append(batch, rec)
+		return nil
+	})
+	if err != nil {
+		return nil, from, err
+	}
+
+	return batch, next, nil
+}
+
+// Close release view, writer stop copying slot for this view
+func (s *SnapshotView) Close() {
+	hm := s.hm
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.shadow = nil
+	delete(hm.snapshots, s)
+	hm.recordSnapshot(time.Since(s.createdAt))
+}
+
+// Snapshot iterate key updated at or after t without blocking writer, value is consistent at time Snapshot called
+func (hm *HashMapCounter) Snapshot(t time.Time, handler func(key string, kind reflect.Kind, value any) error) error {
+	view := hm.OpenSnapshot()
+	defer view.Close()
+
+	return view.iterate(t, false, func(rec *KeyRecord) error {
+		return handler(rec.Key, rec.Kind, rec.Value)
+	})
+}
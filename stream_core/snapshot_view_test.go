@@ -0,0 +1,84 @@
+package stream_core_test
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func TestSnapshotView(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/snapshot_view_unittest",
+		HashMapCounterSlots: 1 << 16,
+		DynamicValuePath:    "/tmp/stream_engine/snapshot_view_value_unittest",
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer kv.Close()
+
+	for i := 0; i < 3000; i++ {
+		kv.IncInt64(fmt.Sprintf("users/%d/order_count", i), 1)
+	}
+
+	t.Run("testing frozen value while writer running", func(t *testing.T) {
+		before := map[string]any{}
+		kv.ScanPrefix("", nil, func(key string, kind reflect.Kind, value any) error {
+			before[key] = value
+			return nil
+		})
+
+		view := kv.OpenSnapshot()
+		defer view.Close()
+
+		expected := kv.GetInt64("users/1/order_count") + 11
+		kv.IncInt64("users/1/order_count", 10)
+		kv.IncInt64("users/new/order_count", 1)
+
+		count := 0
+		err := view.Iterate(time.Time{}, func(rec *stream_core.KeyRecord) error {
+			count++
+			assert.Equal(t, before[rec.Key], rec.Value, rec.Key)
+			assert.NotEqual(t, "users/new/order_count", rec.Key)
+
+			// writer not blocked by slow reader
+			kv.IncInt64(rec.Key, 1)
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, int(view.KeyCount()), count)
+		assert.Equal(t, expected, kv.GetInt64("users/1/order_count"))
+	})
+
+	t.Run("testing snapshot handler can write", func(t *testing.T) {
+		done := make(chan error)
+		go func() {
+			done <- kv.Snapshot(time.Now().Add(-time.Minute), func(key string, kind reflect.Kind, value any) error {
+				kv.IncInt64("users/0/order_count", 1)
+				return nil
+			})
+		}()
+
+		select {
+		case err := <-done:
+			assert.Nil(t, err)
+		case <-time.After(time.Second * 10):
+			t.Fatal("snapshot blocking writer")
+		}
+	})
+
+	t.Run("testing closed view", func(t *testing.T) {
+		view := kv.OpenSnapshot()
+		view.Close()
+
+		err := view.Iterate(time.Time{}, func(rec *stream_core.KeyRecord) error { return nil })
+		assert.ErrorIs(t, err, stream_core.ErrSnapshotClosed)
+	})
+}
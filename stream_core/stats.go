@@ -0,0 +1,269 @@
+package stream_core
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateWindow second of op averaged by ops per second, current second not counted
+const RateWindow = 10
+
+type Stats struct {
+	Slots uint64
+	// SlotsUsed slot holding key, same as key count
+	SlotsUsed  uint64
+	LoadFactor float64
+	// Probe probe length of dedupe window lookup, hashmap slot addressed by hash without probing
+	Probe ProbeStats
+	// DynamicFileSize allocated byte of dynamic value file, DynamicUsed byte written
+	DynamicFileSize int64
+	DynamicUsed     int64
+	// DynamicFragmentation share of dynamic value file allocated but not written
+	DynamicFragmentation float64
+	Wal                  WalStats
+	// Ops op applied since counter opened, batch op also counted by its inc, put and merge
+	Ops OpStats
+	// OpsPerSecond op averaged over last RateWindow second
+	OpsPerSecond OpRates
+	Lock         LockStats
+	Snapshot     SnapshotStats
+	Dedupe       DedupeStats
+}
+
+type ProbeStats struct {
+	Lookups uint64
+	Avg     float64
+	Max     uint64
+}
+
+type WalStats struct {
+	Segments int
+	Bytes    int64
+}
+
+type OpStats struct {
+	Inc   uint64
+	Put   uint64
+	Merge uint64
+	Batch uint64
+}
+
+type OpRates struct {
+	Inc   float64
+	Put   float64
+	Merge float64
+	Batch float64
+}
+
+type LockStats struct {
+	Acquired uint64
+	// Contended lock call waiting for other holder
+	Contended uint64
+	WaitTotal time.Duration
+	WaitMax   time.Duration
+}
+
+type SnapshotStats struct {
+	// Count snapshot closed since counter opened, Open snapshot still open
+	Count uint64
+	Open  int
+	// Last, Max and Total time snapshot view kept open
+	Last  time.Duration
+	Max   time.Duration
+	Total time.Duration
+}
+
+// Stats counter engine stat, wal and dedupe stat zero when not configured.
+// wal and dedupe stat collected after counter lock released, so reading wal dir not block writer
+func (hm *HashMapCounter) Stats() (Stats, error) {
+	stats, wal, dedupe, err := hm.counterStats()
+	if err != nil {
+		return stats, err
+	}
+
+	if dedupe != nil {
+		stats.Dedupe = dedupe.stats()
+		stats.Probe = dedupe.probe.stats()
+	}
+
+	if wal != nil {
+		stats.Wal, err = wal.Stats()
+		if err != nil {
+			return stats, err
+		}
+	}
+
+	return stats, nil
+}
+
+// counterStats stat read with counter lock held
+func (hm *HashMapCounter) counterStats() (Stats, *WAL, *dedupeSet, error) {
+	hm.lock.Lock()
+	defer hm.lock.Unlock()
+
+	err := hm.refreshLocked()
+	if err != nil {
+		return Stats{}, nil, nil, err
+	}
+
+	stats := Stats{
+		Slots:     hm.Slots(),
+		SlotsUsed: hm.keyCount,
+		Ops:       hm.ops.totals(),
+		Lock:      hm.lock.stats(),
+		Snapshot:  hm.snapshotStats,
+	}
+	stats.LoadFactor = float64(stats.SlotsUsed) / float64(stats.Slots)
+	stats.OpsPerSecond = hm.ops.rates(time.Now().Unix())
+	stats.Snapshot.Open = len(hm.snapshots)
+
+	hm.dynamicValue.lock.Lock()
+	stats.DynamicFileSize = hm.dynamicValue.filesize
+	stats.DynamicUsed = hm.dynamicValue.currentOffset
+	hm.dynamicValue.lock.Unlock()
+	if stats.DynamicFileSize > 0 {
+		stats.DynamicFragmentation = float64(stats.DynamicFileSize-stats.DynamicUsed) / float64(stats.DynamicFileSize)
+	}
+
+	return stats, hm.wal, hm.dedupe, nil
+}
+
+func (hm *HashMapCounter) PrintStat() {
+	stats, err := hm.Stats()
+	if err != nil {
+		log.Printf("stats: %s", err)
+	}
+	log.Printf("key_count: %d load_factor: %.4f dynamic_used: %d wal_bytes: %d lock_wait: %s",
+		stats.SlotsUsed, stats.LoadFactor, stats.DynamicUsed, stats.Wal.Bytes, stats.Lock.WaitTotal)
+}
+
+// timedMutex mutex recording time waited when lock contended
+type timedMutex struct {
+	sync.Mutex
+	acquired  atomic.Uint64
+	contended atomic.Uint64
+	wait      atomic.Int64
+	maxWait   atomic.Int64
+}
+
+func (m *timedMutex) Lock() {
+	m.acquired.Add(1)
+	// uncontended lock not paying for clock read
+	if m.TryLock() {
+		return
+	}
+
+	start := time.Now()
+	m.Mutex.Lock()
+	wait := int64(time.Since(start))
+
+	m.contended.Add(1)
+	m.wait.Add(wait)
+	for {
+		max := m.maxWait.Load()
+		if wait <= max || m.maxWait.CompareAndSwap(max, wait) {
+			break
+		}
+	}
+}
+
+func (m *timedMutex) stats() LockStats {
+	return LockStats{
+		Acquired:  m.acquired.Load(),
+		Contended: m.contended.Load(),
+		WaitTotal: time.Duration(m.wait.Load()),
+		WaitMax:   time.Duration(m.maxWait.Load()),
+	}
+}
+
+type opKind int
+
+const (
+	opInc opKind = iota
+	opPut
+	opMerge
+	opBatch
+	opKindCount
+)
+
+// opMeter op count in ring of one second bucket, must used with counter lock held
+type opMeter struct {
+	total   [opKindCount]uint64
+	seconds [RateWindow + 1]int64
+	buckets [RateWindow + 1][opKindCount]uint64
+}
+
+func (m *opMeter) record(kind opKind, now time.Time) {
+	sec := now.Unix()
+	i := sec % int64(len(m.seconds))
+	if m.seconds[i] != sec {
+		m.seconds[i] = sec
+		m.buckets[i] = [opKindCount]uint64{}
+	}
+	m.buckets[i][kind]++
+	m.total[kind]++
+}
+
+func (m *opMeter) totals() OpStats {
+	return OpStats{
+		Inc:   m.total[opInc],
+		Put:   m.total[opPut],
+		Merge: m.total[opMerge],
+		Batch: m.total[opBatch],
+	}
+}
+
+func (m *opMeter) rates(now int64) OpRates {
+	var sum [opKindCount]uint64
+	for i, sec := range m.seconds {
+		if sec >= now-RateWindow && sec < now {
+			for kind, count := range m.buckets[i] {
+				sum[kind] += count
+			}
+		}
+	}
+
+	return OpRates{
+		Inc:   float64(sum[opInc]) / RateWindow,
+		Put:   float64(sum[opPut]) / RateWindow,
+		Merge: float64(sum[opMerge]) / RateWindow,
+		Batch: float64(sum[opBatch]) / RateWindow,
+	}
+}
+
+// probeMeter probe length of lookup, recorded with counter lock held and read without lock
+type probeMeter struct {
+	lookups atomic.Uint64
+	total   atomic.Uint64
+	max     atomic.Uint64
+}
+
+func (m *probeMeter) record(length uint64) {
+	m.lookups.Add(1)
+	m.total.Add(length)
+	if length > m.max.Load() {
+		m.max.Store(length)
+	}
+}
+
+func (m *probeMeter) stats() ProbeStats {
+	lookups := m.lookups.Load()
+	stats := ProbeStats{Lookups: lookups, Max: m.max.Load()}
+	if lookups > 0 {
+		stats.Avg = float64(m.total.Load()) / float64(lookups)
+	}
+	return stats
+}
+
+// recordSnapshot must called with lock held
+func (hm *HashMapCounter) recordSnapshot(d time.Duration) {
+	s := &hm.snapshotStats
+	s.Count++
+	s.Last = d
+	s.Total += d
+	if d > s.Max {
+		s.Max = d
+	}
+}
@@ -0,0 +1,71 @@
+package stream_core_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wargasipil/stream_engine/stream_core"
+)
+
+func TestStats(t *testing.T) {
+	cfg := stream_core.CoreConfig{
+		HashMapCounterPath:  "/tmp/stream_engine/stats_unittest",
+		HashMapCounterSlots: 1024,
+		DynamicValuePath:    "/tmp/stream_engine/stats_value_unittest",
+		WalDir:              "/tmp/stream_engine/stats_wal_unittest",
+		DedupePath:          "/tmp/stream_engine/stats_dedupe_unittest",
+		DedupeExpectedIDs:   1000,
+		DedupeWindow:        1 << 10,
+	}
+	os.Remove(cfg.DynamicValuePath)
+	os.Remove(cfg.HashMapCounterPath)
+	os.Remove(cfg.DedupePath)
+	os.RemoveAll(cfg.WalDir)
+
+	kv, err := stream_core.NewHashMapCounter(&cfg)
+	assert.Nil(t, err)
+	defer kv.Close()
+
+	kv.IncInt64("users/1/order_count", 1)
+	kv.IncInt64("users/1/order_count", 1)
+	kv.PutFloat64("users/1/balance", 10)
+	_, err = kv.Merge(stream_core.MergeOpAdd, reflect.Int64, "users/order_total", "users/1/order_count")
+	assert.Nil(t, err)
+
+	_, err = kv.IncWithID("trx-1", "users/1/visit", int64(1))
+	assert.Nil(t, err)
+	_, err = kv.IncWithID("trx-1", "users/1/visit", int64(1))
+	assert.Nil(t, err)
+
+	batch := stream_core.NewBatch()
+	batch.IncInt64("users/2/order_count", 1)
+	batch.SetOffset("orders", 1)
+	assert.Nil(t, kv.ApplyBatch(batch))
+
+	view := kv.OpenSnapshot()
+	view.Close()
+
+	stats, err := kv.Stats()
+	assert.Nil(t, err)
+
+	assert.Equal(t, uint64(1024), stats.Slots)
+	assert.Equal(t, uint64(5), stats.SlotsUsed)
+	assert.Equal(t, float64(5)/1024, stats.LoadFactor)
+	assert.Equal(t, stream_core.OpStats{Inc: 4, Put: 1, Merge: 1, Batch: 1}, stats.Ops)
+	assert.Equal(t, uint64(2), stats.Probe.Lookups)
+	assert.Equal(t, uint64(1), stats.Probe.Max)
+	assert.Equal(t, stream_core.DedupeStats{Hits: 1, Misses: 1}, stats.Dedupe)
+
+	assert.True(t, stats.DynamicUsed > 0)
+	assert.True(t, stats.DynamicFileSize >= stats.DynamicUsed)
+	assert.True(t, stats.DynamicFragmentation > 0 && stats.DynamicFragmentation < 1)
+
+	assert.Equal(t, 1, stats.Wal.Segments)
+	assert.True(t, stats.Wal.Bytes > 0)
+
+	assert.Equal(t, uint64(1), stats.Snapshot.Count)
+	assert.Equal(t, 0, stats.Snapshot.Open)
+	assert.True(t, stats.Lock.Acquired > 0)
+}
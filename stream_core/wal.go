@@ -73,6 +73,10 @@ func (w *WAL) Append(msg proto.Message) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	return w.append(data)
+}
+
+func (w *WAL) append(data []byte) error {
 	recSize := int64(headerSize + len(data))
 	if w.size+recSize > segmentSize {
 		if err := w.rotate(); err != nil {
@@ -115,6 +119,69 @@ func (w *WAL) rotate() error {
 	return nil
 }
 
+// ---------- TRUNCATE ----------
+
+// Truncate start new segment with msg as first record and remove older segment,
+// msg must carry every state still needed from removed segment
+func (w *WAL) Truncate(msg proto.Message) error {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.rotate(); err != nil {
+		return err
+	}
+	if err := w.append(data); err != nil {
+		return err
+	}
+
+	ids, err := listSegments(w.dir)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if id >= w.segmentID {
+			continue
+		}
+		if err := os.Remove(filepath.Join(w.dir, segmentName(id))); err != nil {
+			return err
+		}
+	}
+	return syncDir(w.dir)
+}
+
+// Segment current segment id, increased when wal rotated
+func (w *WAL) Segment() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.segmentID
+}
+
+// Stats segment count and byte of all segment in wal dir
+func (w *WAL) Stats() (WalStats, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ids, err := listSegments(w.dir)
+	if err != nil {
+		return WalStats{}, err
+	}
+
+	stats := WalStats{Segments: len(ids)}
+	for _, id := range ids {
+		info, err := os.Stat(filepath.Join(w.dir, segmentName(id)))
+		if err != nil {
+			return WalStats{}, err
+		}
+		stats.Bytes += info.Size()
+	}
+	return stats, nil
+}
+
 // ---------- REPLAY ----------
 
 func Replay(dir string, apply func([]byte)) error {